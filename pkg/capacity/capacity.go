@@ -0,0 +1,203 @@
+// Package capacity учитывает заполненность ячеек по весу, объему и числу единиц. Ячейка может хранить
+// несколько партий, если это разрешает ее политика смешивания (slots.mixing_policy). Функции пакета
+// работают с таблицей slots и используются всеми сервисами размещения и оркестратором
+package capacity
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// Политики смешивания ячейки
+const (
+	// MixingSingleBatch - в ячейке только одна партия; ее можно пополнять
+	MixingSingleBatch = "single_batch"
+	// MixingSameItem - несколько партий одного товара
+	MixingSameItem = "same_item"
+	// MixingMixed - любые товары
+	MixingMixed = "mixed"
+)
+
+// Статусы ячейки (slots.status). Размещать и резервировать можно только в действующую ячейку
+const (
+	// SlotActive - ячейка действует
+	SlotActive = "active"
+	// SlotBlocked - ячейка временно закрыта, например на ремонт стеллажа; ее остатки можно отбирать
+	SlotBlocked = "blocked"
+	// SlotRetired - ячейка выведена из справочника; выводится только пустая ячейка
+	SlotRetired = "retired"
+)
+
+// ErrInsufficientCapacity означает, что груз не помещается в ячейку, ее политика смешивания
+// не допускает его соседства с уже лежащим товаром или ячейка не действует
+var ErrInsufficientCapacity = errors.New("недостаточно места в ячейке")
+
+// ErrUnknownItem означает, что товара нет в справочнике items и груз рассчитать нельзя
+var ErrUnknownItem = errors.New("товар не найден в справочнике")
+
+// ErrUnknownBatch означает, что партии нет в batches или она принадлежит другому товару
+var ErrUnknownBatch = errors.New("партия товара не найдена")
+
+// ErrEmptyLoad означает, что в грузе нет ни одной единицы товара: такой груз поместился бы в любую ячейку
+var ErrEmptyLoad = errors.New("груз без единиц товара")
+
+// Load - груз, который размещение кладет в ячейку: Units единиц товара общим весом Weight и объемом Volume
+type Load struct {
+	ItemID  string
+	BatchID string
+	Units   int
+	Weight  float64
+	Volume  float64
+}
+
+// Remaining - свободная емкость ячейки; Units = -1, если число единиц не ограничено
+type Remaining struct {
+	Weight float64 `json:"weight"`
+	Volume float64 `json:"volume"`
+	Units  int     `json:"units"`
+}
+
+// RemainingColumns - выражения свободной емкости ячейки slots s в порядке полей Remaining
+const RemainingColumns = "s.max_weight - s.used_weight, s.max_length * s.max_width * s.max_height - s.used_volume, COALESCE(s.max_units - s.used_units, -1)"
+
+// Queryer - *sql.DB или *sql.Tx
+type Queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// ResolveLoad рассчитывает груз по справочнику: вес и объем единицы товара из items, умноженные
+// на quantity. quantity = 0 означает всю партию batchID. Для неизвестного товара возвращается ErrUnknownItem,
+// для партии, которой нет у товара, - ErrUnknownBatch, для груза без единиц - ErrEmptyLoad: груз без веса
+// и объема поместился бы в любую ячейку
+func ResolveLoad(ctx context.Context, q Queryer, itemID, batchID string, quantity int) (Load, error) {
+	load := Load{ItemID: itemID, BatchID: batchID, Units: quantity}
+
+	var unitWeight, unitVolume float64
+	var batchFound bool
+	err := q.QueryRowContext(ctx, `
+		SELECT i.weight, i.length * i.width * i.height, COALESCE(NULLIF($3, 0), b.quantity, 0), b.batch_id IS NOT NULL
+		FROM items i
+		LEFT JOIN batches b ON b.batch_id = $2 AND b.item_id = i.item_id
+		WHERE i.item_id = $1`,
+		itemID, batchID, quantity,
+	).Scan(&unitWeight, &unitVolume, &load.Units, &batchFound)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Load{}, fmt.Errorf("%w: %s", ErrUnknownItem, itemID)
+	}
+	if err != nil {
+		return Load{}, fmt.Errorf("ошибка расчета груза: %w", err)
+	}
+	if batchID != "" && !batchFound {
+		return Load{}, fmt.Errorf("%w: %s/%s", ErrUnknownBatch, itemID, batchID)
+	}
+	if load.Units <= 0 {
+		return Load{}, fmt.Errorf("%w: %s/%s", ErrEmptyLoad, itemID, batchID)
+	}
+
+	load.Weight = unitWeight * float64(load.Units)
+	load.Volume = unitVolume * float64(load.Units)
+	return load, nil
+}
+
+// FitsCondition возвращает условие SQL на ячейку slots s, выполненное, если ячейка действует, груз
+// помещается в нее по весу, объему и числу единиц и политика смешивания допускает его соседство
+// с остатками ячейки в slot_stock. Параметры условия нумеруются с $first; резерв ячейки условие не проверяет
+func FitsCondition(load Load, first int) (string, []interface{}) {
+	units, weight, volume, item, batch := first, first+1, first+2, first+3, first+4
+	condition := fmt.Sprintf(`(s.status = 'active'
+		AND s.used_weight + $%[2]d <= s.max_weight
+		AND s.used_volume + $%[3]d <= s.max_length * s.max_width * s.max_height
+		AND (s.max_units IS NULL OR s.used_units + $%[1]d <= s.max_units)
+		AND (s.mixing_policy = 'mixed' OR NOT EXISTS (
+			SELECT 1 FROM slot_stock st
+			WHERE st.slot_id = s.slot_id AND st.quantity > 0
+			  AND (st.item_id <> $%[4]d
+			       OR (s.mixing_policy = 'single_batch' AND st.batch_id <> $%[5]d))
+		)))`, units, weight, volume, item, batch)
+	return condition, []interface{}{load.Units, load.Weight, load.Volume, load.ItemID, load.BatchID}
+}
+
+// Fits проверяет, помещается ли груз в ячейку сейчас; занятость резервом не учитывается
+func Fits(ctx context.Context, q Queryer, slotID string, load Load) (bool, error) {
+	condition, args := FitsCondition(load, 2)
+	var fits bool
+	err := q.QueryRowContext(ctx,
+		"SELECT "+condition+" FROM slots s WHERE s.slot_id = $1",
+		append([]interface{}{slotID}, args...)...,
+	).Scan(&fits)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return fits, err
+}
+
+// Occupy добавляет груз к заполненности ячейки в рамках tx. Проверка емкости повторяется
+// под блокировкой строки ячейки, поэтому параллельные размещения не переполнят ее;
+// если груз уже не помещается, возвращается ErrInsufficientCapacity
+func Occupy(ctx context.Context, tx *sql.Tx, slotID string, load Load) error {
+	// Строка ячейки блокируется отдельным запросом до проверки. При READ COMMITTED повторная проверка
+	// UPDATE после ожидания блокировки видит новую версию строки ячейки, но не остатки slot_stock,
+	// записанные параллельной транзакцией, и политика смешивания нарушалась бы. Запрос, начатый после
+	// получения блокировки, видит все остатки, зафиксированные ее прежним владельцем
+	var locked string
+	err := tx.QueryRowContext(ctx, "SELECT slot_id FROM slots WHERE slot_id = $1 FOR UPDATE", slotID).Scan(&locked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w %s", ErrInsufficientCapacity, slotID)
+	}
+	if err != nil {
+		return err
+	}
+
+	condition, args := FitsCondition(load, 2)
+	result, err := tx.ExecContext(ctx, `
+		UPDATE slots s
+		SET is_occupied = true,
+		    used_units = s.used_units + $2,
+		    used_weight = s.used_weight + $3,
+		    used_volume = s.used_volume + $4
+		WHERE s.slot_id = $1 AND `+condition,
+		append([]interface{}{slotID}, args...)...,
+	)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w %s", ErrInsufficientCapacity, slotID)
+	}
+	return nil
+}
+
+// Vacate вычитает units единиц товара itemID из заполненности ячейки в рамках tx и сообщает,
+// опустела ли ячейка. Опустевшая ячейка обнуляется и помечается свободной
+func Vacate(ctx context.Context, tx *sql.Tx, slotID, itemID string, units int) (bool, error) {
+	var usedUnits int
+	err := tx.QueryRowContext(ctx, `
+		UPDATE slots s
+		SET used_units = GREATEST(s.used_units - $2, 0),
+		    used_weight = GREATEST(s.used_weight - $2 * COALESCE(i.weight, 0), 0),
+		    used_volume = GREATEST(s.used_volume - $2 * COALESCE(i.length * i.width * i.height, 0), 0)
+		FROM (SELECT $3::VARCHAR AS item_id) AS picked
+		LEFT JOIN items i ON i.item_id = picked.item_id
+		WHERE s.slot_id = $1
+		RETURNING s.used_units`,
+		slotID, units, itemID,
+	).Scan(&usedUnits)
+	if err != nil {
+		return false, err
+	}
+	if usedUnits > 0 {
+		return false, nil
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE slots SET is_occupied = false, used_weight = 0, used_volume = 0 WHERE slot_id = $1",
+		slotID,
+	)
+	return err == nil, err
+}
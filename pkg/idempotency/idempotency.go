@@ -0,0 +1,110 @@
+// Package idempotency выполняет изменяющие команды сервисов размещения не более одного раза
+// на ключ идемпотентности. Отпечаток запроса и ответ хранятся в таблице idempotency_keys
+// в области (scope) сервиса, поэтому одинаковые ключи разных сервисов не пересекаются
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"warehouse/pkg/placement"
+)
+
+var (
+	// ErrKeyReused означает, что ключ уже использован с другим запросом; сервис отвечает 422
+	ErrKeyReused = errors.New("ключ идемпотентности уже использован с другим запросом")
+	// ErrInProgress означает, что запрос с этим ключом еще выполняется; сервис отвечает 409
+	ErrInProgress = errors.New("запрос с этим ключом идемпотентности еще выполняется")
+)
+
+// Record - сохраненный отпечаток запроса и ответ на него
+type Record struct {
+	RequestHash string
+	Response    []byte
+	Completed   bool
+}
+
+// Store - хранилище ключей идемпотентности сервиса
+type Store interface {
+	// BeginIdempotentRequest регистрирует ключ; false - ключ уже был, возвращается его запись
+	BeginIdempotentRequest(ctx context.Context, scope, key, requestHash string) (*Record, bool, error)
+	CompleteIdempotentRequest(ctx context.Context, scope, key string, response []byte) error
+	DeleteIdempotentRequest(ctx context.Context, scope, key string) error
+}
+
+// Command - изменяющая команда сервиса размещения
+type Command func(ctx context.Context, req *placement.Request) (*placement.Response, error)
+
+// Execute выполняет команду не более одного раза на ключ: повтор с тем же ключом и запросом
+// возвращает сохраненный ответ, другой запрос отклоняется ErrKeyReused. Без ключа команда
+// выполняется как есть. Если команда завершилась ошибкой, ключ удаляется и повтор выполнит ее заново
+func Execute(ctx context.Context, store Store, scope, key string, req *placement.Request, run Command) (*placement.Response, error) {
+	if key == "" {
+		return run(ctx, req)
+	}
+
+	fingerprint, err := Fingerprint(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка вычисления отпечатка запроса: %w", err)
+	}
+
+	record, created, err := store.BeginIdempotentRequest(ctx, scope, key, fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка регистрации ключа идемпотентности: %w", err)
+	}
+
+	if !created {
+		if record.RequestHash != fingerprint {
+			return nil, ErrKeyReused
+		}
+		if !record.Completed {
+			return nil, ErrInProgress
+		}
+
+		var cached placement.Response
+		if err := json.Unmarshal(record.Response, &cached); err != nil {
+			return nil, fmt.Errorf("ошибка разбора сохраненного ответа: %w", err)
+		}
+		return &cached, nil
+	}
+
+	response, err := run(ctx, req)
+	if err != nil {
+		if delErr := store.DeleteIdempotentRequest(ctx, scope, key); delErr != nil {
+			log.Printf("Ошибка удаления ключа идемпотентности: %v", delErr)
+		}
+		return nil, err
+	}
+
+	body, err := json.Marshal(response)
+	if err == nil {
+		err = store.CompleteIdempotentRequest(ctx, scope, key, body)
+	}
+	if err != nil {
+		log.Printf("Ошибка сохранения ответа идемпотентного запроса: %v", err)
+	}
+
+	return response, nil
+}
+
+// Fingerprint вычисляет хеш запроса для сопоставления повтора с исходным вызовом. Признаки текущего
+// состояния склада (warehouse_load, has_fixed_slot) оркестратор рассчитывает заново при каждом вызове,
+// поэтому в отпечаток они не входят: иначе повтор после любого размещения на складе отклонялся бы
+// как другой запрос
+func Fingerprint(req *placement.Request) (string, error) {
+	stable := *req
+	stable.WarehouseLoad = 0
+	stable.HasFixedSlot = false
+
+	body, err := json.Marshal(&stable)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
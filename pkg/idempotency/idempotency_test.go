@@ -0,0 +1,163 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"warehouse/pkg/placement"
+)
+
+// memoryStore - хранилище ключей в памяти
+type memoryStore struct {
+	records map[string]*Record
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{records: map[string]*Record{}}
+}
+
+func (m *memoryStore) BeginIdempotentRequest(ctx context.Context, scope, key, requestHash string) (*Record, bool, error) {
+	if record, ok := m.records[scope+"/"+key]; ok {
+		return record, false, nil
+	}
+	m.records[scope+"/"+key] = &Record{RequestHash: requestHash}
+	return nil, true, nil
+}
+
+func (m *memoryStore) CompleteIdempotentRequest(ctx context.Context, scope, key string, response []byte) error {
+	m.records[scope+"/"+key].Response = response
+	m.records[scope+"/"+key].Completed = true
+	return nil
+}
+
+func (m *memoryStore) DeleteIdempotentRequest(ctx context.Context, scope, key string) error {
+	delete(m.records, scope+"/"+key)
+	return nil
+}
+
+func placeRequest(itemID string, warehouseLoad float64, hasFixedSlot bool) *placement.Request {
+	req := placement.NewRequest(placement.CommandReserve)
+	req.ItemID = itemID
+	req.Quantity = 10
+	req.WarehouseLoad = warehouseLoad
+	req.HasFixedSlot = hasFixedSlot
+	return req
+}
+
+func TestFingerprintIgnoresWarehouseState(t *testing.T) {
+	tests := []struct {
+		name  string
+		retry *placement.Request
+		same  bool
+	}{
+		{"тот же запрос", placeRequest("ITEM001", 0.4, false), true},
+		{"изменилась загрузка склада", placeRequest("ITEM001", 0.7, false), true},
+		{"появилась закрепленная ячейка", placeRequest("ITEM001", 0.4, true), true},
+		{"другой товар", placeRequest("ITEM002", 0.4, false), false},
+	}
+
+	original, err := Fingerprint(placeRequest("ITEM001", 0.4, false))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retry, err := Fingerprint(tt.retry)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (retry == original) != tt.same {
+				t.Errorf("совпадение отпечатков = %v, ожидалось %v", retry == original, tt.same)
+			}
+		})
+	}
+}
+
+func TestExecute(t *testing.T) {
+	placed := &placement.Response{Success: true, SlotID: "SLOT001", Comment: "размещено", Score: 1}
+	failure := errors.New("сбой базы")
+
+	tests := []struct {
+		name string
+		// first и retry - первый вызов и повтор с тем же ключом
+		first, retry *placement.Request
+		firstErr     error
+		wantErr      error
+		wantCalls    int
+	}{
+		{
+			name:      "повтор возвращает сохраненный ответ",
+			first:     placeRequest("ITEM001", 0.4, false),
+			retry:     placeRequest("ITEM001", 0.4, false),
+			wantCalls: 1,
+		},
+		{
+			name:      "повтор после размещения на складе не считается другим запросом",
+			first:     placeRequest("ITEM001", 0.4, false),
+			retry:     placeRequest("ITEM001", 0.9, false),
+			wantCalls: 1,
+		},
+		{
+			name:      "другой запрос с тем же ключом отклоняется",
+			first:     placeRequest("ITEM001", 0.4, false),
+			retry:     placeRequest("ITEM002", 0.4, false),
+			wantErr:   ErrKeyReused,
+			wantCalls: 1,
+		},
+		{
+			name:      "после ошибки команда выполняется заново",
+			first:     placeRequest("ITEM001", 0.4, false),
+			retry:     placeRequest("ITEM001", 0.4, false),
+			firstErr:  failure,
+			wantCalls: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := newMemoryStore()
+			calls := 0
+			run := func(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+				calls++
+				if calls == 1 && tt.firstErr != nil {
+					return nil, tt.firstErr
+				}
+				response := *placed
+				return &response, nil
+			}
+
+			if _, err := Execute(context.Background(), store, "test", "key-1", tt.first, run); !errors.Is(err, tt.firstErr) {
+				t.Fatalf("первый вызов: ошибка %v, ожидалась %v", err, tt.firstErr)
+			}
+			resp, err := Execute(context.Background(), store, "test", "key-1", tt.retry, run)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("повтор: ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("команда выполнена %d раз, ожидалось %d", calls, tt.wantCalls)
+			}
+			if err == nil && resp.SlotID != placed.SlotID {
+				t.Errorf("повтор вернул ячейку %q, ожидалась %q", resp.SlotID, placed.SlotID)
+			}
+		})
+	}
+}
+
+func TestExecuteInProgress(t *testing.T) {
+	store := newMemoryStore()
+	req := placeRequest("ITEM001", 0.4, false)
+	fingerprint, err := Fingerprint(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store.BeginIdempotentRequest(context.Background(), "test", "key-1", fingerprint)
+
+	_, err = Execute(context.Background(), store, "test", "key-1", req, func(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+		t.Fatal("команда не должна выполняться, пока выполняется первый запрос")
+		return nil, nil
+	})
+	if !errors.Is(err, ErrInProgress) {
+		t.Fatalf("ошибка %v, ожидалась %v", err, ErrInProgress)
+	}
+}
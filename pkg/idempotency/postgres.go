@@ -0,0 +1,70 @@
+package idempotency
+
+import (
+	"context"
+	"database/sql"
+)
+
+// KeyStore - Store поверх таблицы idempotency_keys. Репозитории оркестратора и сервисов размещения
+// встраивают его, поэтому SQL ключей идемпотентности у всех один
+type KeyStore struct {
+	db *sql.DB
+}
+
+func NewKeyStore(db *sql.DB) *KeyStore {
+	return &KeyStore{db: db}
+}
+
+// BeginIdempotentRequest регистрирует ключ; false - ключ уже был, возвращается его запись. Незавершенный
+// ключ старше пяти минут считается брошенным упавшим вызовом и регистрируется заново
+func (s *KeyStore) BeginIdempotentRequest(ctx context.Context, scope, key, requestHash string) (*Record, bool, error) {
+	if _, err := s.db.ExecContext(ctx,
+		"DELETE FROM idempotency_keys WHERE scope = $1 AND idempotency_key = $2 AND completed_at IS NULL AND created_at < NOW() - INTERVAL '5 minutes'",
+		scope, key,
+	); err != nil {
+		return nil, false, err
+	}
+
+	result, err := s.db.ExecContext(ctx,
+		"INSERT INTO idempotency_keys (scope, idempotency_key, request_hash) VALUES ($1, $2, $3) ON CONFLICT DO NOTHING",
+		scope, key, requestHash,
+	)
+	if err != nil {
+		return nil, false, err
+	}
+	if affected, err := result.RowsAffected(); err != nil {
+		return nil, false, err
+	} else if affected == 1 {
+		return &Record{RequestHash: requestHash}, true, nil
+	}
+
+	var record Record
+	var response []byte
+	err = s.db.QueryRowContext(ctx,
+		"SELECT request_hash, response, completed_at IS NOT NULL FROM idempotency_keys WHERE scope = $1 AND idempotency_key = $2",
+		scope, key,
+	).Scan(&record.RequestHash, &response, &record.Completed)
+	if err != nil {
+		return nil, false, err
+	}
+	record.Response = response
+	return &record, false, nil
+}
+
+// CompleteIdempotentRequest сохраняет ответ на запрос с ключом
+func (s *KeyStore) CompleteIdempotentRequest(ctx context.Context, scope, key string, response []byte) error {
+	_, err := s.db.ExecContext(ctx,
+		"UPDATE idempotency_keys SET response = $1, completed_at = NOW() WHERE scope = $2 AND idempotency_key = $3",
+		response, scope, key,
+	)
+	return err
+}
+
+// DeleteIdempotentRequest удаляет незавершенный ключ, чтобы повтор выполнил команду заново
+func (s *KeyStore) DeleteIdempotentRequest(ctx context.Context, scope, key string) error {
+	_, err := s.db.ExecContext(ctx,
+		"DELETE FROM idempotency_keys WHERE scope = $1 AND idempotency_key = $2 AND completed_at IS NULL",
+		scope, key,
+	)
+	return err
+}
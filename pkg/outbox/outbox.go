@@ -0,0 +1,56 @@
+// Package outbox реализует транзакционный outbox событий размещения: сервисы записывают событие
+// в таблицу placement_events_outbox в той же транзакции, что и изменение ячейки, а Relay
+// доставляет записанные события внешним получателям через Publisher
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Типы событий размещения
+const (
+	// EventItemPlaced - товар размещен в ячейке, ячейка занята
+	EventItemPlaced = "ItemPlaced"
+	// EventItemPicked - товар отобран из ячейки, Quantity - отобранное количество
+	EventItemPicked = "ItemPicked"
+	// EventSlotReleased - ячейка освобождена: из нее отобран или перемещен весь товар
+	EventSlotReleased = "SlotReleased"
+	// EventItemMoved - товар перемещен из ячейки FromSlotID в SlotID, Quantity - перемещенное количество
+	EventItemMoved = "ItemMoved"
+	// EventPlacementReverted - отменено ранее опубликованное размещение ItemPlaced: Quantity единиц
+	// снято из ячейки, потому что пакет all_or_nothing, в который оно входило, не выполнен целиком
+	EventPlacementReverted = "PlacementReverted"
+)
+
+// Event - событие размещения в том виде, в каком оно передается получателям
+type Event struct {
+	EventID    int64     `json:"event_id"`
+	Type       string    `json:"event_type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	SlotID     string    `json:"slot_id"`
+	FromSlotID string    `json:"from_slot_id,omitempty"`
+	ItemID     string    `json:"item_id,omitempty"`
+	BatchID    string    `json:"batch_id,omitempty"`
+	Quantity   int       `json:"quantity,omitempty"`
+	// Source - алгоритм или сервис, изменивший ячейку, например abc_placement или picking
+	Source string `json:"source"`
+}
+
+// Insert записывает событие в outbox в рамках транзакции tx; событие будет доставлено,
+// только если транзакция зафиксирована
+func Insert(ctx context.Context, tx *sql.Tx, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации события: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"INSERT INTO placement_events_outbox (event_type, slot_id, payload) VALUES ($1, $2, $3)",
+		event.Type, event.SlotID, payload,
+	)
+	return err
+}
@@ -0,0 +1,39 @@
+package outbox
+
+import (
+	"context"
+	"sync"
+)
+
+// Publisher доставляет событие получателю. Ошибка означает, что событие не доставлено
+// и Relay повторит попытку позже
+type Publisher interface {
+	Publish(ctx context.Context, event Event) error
+}
+
+// MemoryPublisher сохраняет события в памяти и используется только в тестах: события не покидают
+// процесс, поэтому оркестратор его не подключает
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []Event
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, event Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+// Events возвращает копию доставленных событий в порядке доставки
+func (p *MemoryPublisher) Events() []Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	events := make([]Event, len(p.events))
+	copy(events, p.events)
+	return events
+}
@@ -0,0 +1,224 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+)
+
+// RelayConfig - параметры доставки событий из outbox
+type RelayConfig struct {
+	// PollInterval - как часто проверять outbox, когда недоставленных событий нет
+	PollInterval time.Duration
+	// BatchSize - сколько событий забирать за один проход
+	BatchSize int
+	// MaxAttempts - после стольких неудачных доставок событие помечается как недоставленное
+	// и больше не задерживает следующие
+	MaxAttempts int
+	// RetryBackoff - пауза перед повторной доставкой; растет вдвое с каждой попыткой, но не больше часа
+	RetryBackoff time.Duration
+}
+
+// relayLockKey - ключ advisory-блокировки PostgreSQL, которую удерживает Relay на время прохода
+const relayLockKey int64 = 0x6f7574626f78 // "outbox"
+
+// Relay доставляет события из outbox в порядке записи. Если событие доставить не удалось, проход
+// прерывается, чтобы получатель не увидел следующие события раньше него. Проход выполняет только
+// экземпляр, захвативший advisory-блокировку, поэтому порядок общий для всех экземпляров оркестратора
+type Relay struct {
+	db        *sql.DB
+	publisher Publisher
+	config    RelayConfig
+}
+
+func NewRelay(db *sql.DB, publisher Publisher, cfg RelayConfig) *Relay {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	return &Relay{db: db, publisher: publisher, config: cfg}
+}
+
+// Run доставляет события до отмены контекста
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			delivered, err := r.RelayOnce(ctx)
+			if err != nil {
+				log.Printf("Ошибка доставки событий из outbox: %v", err)
+				break
+			}
+			if delivered < r.config.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce доставляет очередную порцию событий и возвращает число доставленных. Если проход уже
+// выполняет другой экземпляр, RelayOnce ничего не делает. События публикуются вне транзакции: пока
+// получатель отвечает или Publisher повторяет запрос, строки outbox не заблокированы. Отметка
+// о доставке пишется после публикации, поэтому при сбое между ними событие будет доставлено повторно;
+// получатель отличает повтор по X-Warehouse-Event-Id
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	// Блокировка сессионная: она снимается явно на том же соединении, а соединение, на котором
+	// снять ее не удалось, закрывается, чтобы блокировка не осталась в пуле
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", relayLockKey).Scan(&locked); err != nil {
+		return 0, err
+	}
+	if !locked {
+		return 0, nil
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", relayLockKey); err != nil {
+			log.Printf("Ошибка снятия блокировки доставки событий: %v", err)
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+	}()
+
+	pending, err := r.pendingEvents(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.publishPending(ctx, pending, &connMarker{conn: conn, config: r.config})
+}
+
+// deliveryMarker отмечает исход доставки события в outbox
+type deliveryMarker interface {
+	markPublished(ctx context.Context, eventID int64) error
+	markFailedAttempt(ctx context.Context, eventID int64, attempts int, publishErr error) error
+}
+
+// publishPending публикует события по порядку и останавливается на первом, которое ждет повтора
+// или не доставлено, чтобы получатель не увидел следующие события раньше него
+func (r *Relay) publishPending(ctx context.Context, pending []pendingEvent, marker deliveryMarker) (int, error) {
+	delivered := 0
+	for _, item := range pending {
+		// Событие ждет повторной доставки: следующие за ним тоже ждут, чтобы не нарушить порядок
+		if !item.ready {
+			break
+		}
+		if err := r.publisher.Publish(ctx, item.event); err != nil {
+			if markErr := marker.markFailedAttempt(ctx, item.event.EventID, item.attempts+1, err); markErr != nil {
+				return delivered, markErr
+			}
+			break
+		}
+
+		if err := marker.markPublished(ctx, item.event.EventID); err != nil {
+			return delivered, err
+		}
+		delivered++
+	}
+
+	return delivered, nil
+}
+
+// pendingEvent - недоставленное событие; ready - наступило время очередной попытки
+type pendingEvent struct {
+	event    Event
+	attempts int
+	ready    bool
+}
+
+// pendingEvents читает очередную порцию недоставленных событий в порядке event_id
+func (r *Relay) pendingEvents(ctx context.Context, conn *sql.Conn) ([]pendingEvent, error) {
+	rows, err := conn.QueryContext(ctx, `
+		SELECT event_id, payload, attempts, created_at, next_attempt_at <= NOW()
+		FROM placement_events_outbox
+		WHERE published_at IS NULL AND failed_at IS NULL
+		ORDER BY event_id
+		LIMIT $1`,
+		r.config.BatchSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pending []pendingEvent
+	for rows.Next() {
+		var item pendingEvent
+		var eventID int64
+		var payload []byte
+		var createdAt time.Time
+		if err := rows.Scan(&eventID, &payload, &item.attempts, &createdAt, &item.ready); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &item.event); err != nil {
+			return nil, fmt.Errorf("ошибка разбора события %d: %w", eventID, err)
+		}
+		item.event.EventID = eventID
+		item.event.OccurredAt = createdAt
+		pending = append(pending, item)
+	}
+	return pending, rows.Err()
+}
+
+// connMarker пишет исход доставки в outbox на соединении, которое удерживает блокировку прохода
+type connMarker struct {
+	conn   *sql.Conn
+	config RelayConfig
+}
+
+func (m *connMarker) markPublished(ctx context.Context, eventID int64) error {
+	_, err := m.conn.ExecContext(ctx, "UPDATE placement_events_outbox SET published_at = NOW(), attempts = attempts + 1, last_error = NULL WHERE event_id = $1", eventID)
+	return err
+}
+
+// markFailedAttempt откладывает следующую попытку доставки или, если попытки исчерпаны, помечает событие недоставленным
+func (m *connMarker) markFailedAttempt(ctx context.Context, eventID int64, attempts int, publishErr error) error {
+	log.Printf("Событие %d не доставлено (попытка %d): %v", eventID, attempts, publishErr)
+
+	if m.config.MaxAttempts > 0 && attempts >= m.config.MaxAttempts {
+		_, err := m.conn.ExecContext(ctx,
+			"UPDATE placement_events_outbox SET attempts = $2, last_error = $3, failed_at = NOW() WHERE event_id = $1",
+			eventID, attempts, publishErr.Error(),
+		)
+		return err
+	}
+
+	_, err := m.conn.ExecContext(ctx,
+		"UPDATE placement_events_outbox SET attempts = $2, last_error = $3, next_attempt_at = NOW() + $4 * INTERVAL '1 second' WHERE event_id = $1",
+		eventID, attempts, publishErr.Error(), retryDelay(m.config.RetryBackoff, attempts).Seconds(),
+	)
+	return err
+}
+
+// retryDelay вычисляет паузу перед попыткой attempts+1
+func retryDelay(backoff time.Duration, attempts int) time.Duration {
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	delay := backoff
+	for i := 1; i < attempts && delay < time.Hour; i++ {
+		delay *= 2
+	}
+	if delay > time.Hour {
+		delay = time.Hour
+	}
+	return delay
+}
@@ -0,0 +1,159 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name     string
+		backoff  time.Duration
+		attempts int
+		want     time.Duration
+	}{
+		{name: "первая попытка ждет backoff", backoff: 2 * time.Second, attempts: 1, want: 2 * time.Second},
+		{name: "каждая попытка удваивает паузу", backoff: 2 * time.Second, attempts: 4, want: 16 * time.Second},
+		{name: "нулевой backoff заменяется секундой", backoff: 0, attempts: 3, want: 4 * time.Second},
+		{name: "отрицательный backoff заменяется секундой", backoff: -time.Second, attempts: 1, want: time.Second},
+		{name: "попытка без номера ждет backoff", backoff: time.Second, attempts: 0, want: time.Second},
+		{name: "пауза ограничена часом", backoff: time.Second, attempts: 20, want: time.Hour},
+		{name: "backoff больше часа ограничен часом", backoff: 2 * time.Hour, attempts: 1, want: time.Hour},
+		{name: "большое число попыток не переполняет паузу", backoff: time.Second, attempts: 1000, want: time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryDelay(tt.backoff, tt.attempts); got != tt.want {
+				t.Errorf("retryDelay(%v, %d) = %v, ожидалось %v", tt.backoff, tt.attempts, got, tt.want)
+			}
+		})
+	}
+}
+
+// failingPublisher доставляет события в MemoryPublisher, кроме событий из failOn
+type failingPublisher struct {
+	*MemoryPublisher
+	failOn map[int64]bool
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, event Event) error {
+	if p.failOn[event.EventID] {
+		return errors.New("получатель недоступен")
+	}
+	return p.MemoryPublisher.Publish(ctx, event)
+}
+
+// failedAttempt - отметка о неудачной доставке
+type failedAttempt struct {
+	eventID  int64
+	attempts int
+}
+
+// recordingMarker запоминает отметки о доставке вместо записи в outbox
+type recordingMarker struct {
+	published []int64
+	failed    []failedAttempt
+	failErr   error
+}
+
+func (m *recordingMarker) markPublished(ctx context.Context, eventID int64) error {
+	m.published = append(m.published, eventID)
+	return nil
+}
+
+func (m *recordingMarker) markFailedAttempt(ctx context.Context, eventID int64, attempts int, publishErr error) error {
+	m.failed = append(m.failed, failedAttempt{eventID: eventID, attempts: attempts})
+	return m.failErr
+}
+
+func TestPublishPending(t *testing.T) {
+	markErr := errors.New("outbox недоступен")
+
+	tests := []struct {
+		name          string
+		notReady      map[int64]bool
+		failOn        map[int64]bool
+		markErr       error
+		wantDelivered int
+		wantPublished []int64
+		wantFailed    []failedAttempt
+		wantErr       error
+	}{
+		{
+			name:          "события публикуются по порядку",
+			wantDelivered: 3,
+			wantPublished: []int64{1, 2, 3},
+		},
+		{
+			name:          "проход останавливается на первой неудаче",
+			failOn:        map[int64]bool{2: true},
+			wantDelivered: 1,
+			wantPublished: []int64{1},
+			wantFailed:    []failedAttempt{{eventID: 2, attempts: 2}},
+		},
+		{
+			name:          "событие, ждущее повтора, задерживает следующие",
+			notReady:      map[int64]bool{2: true},
+			wantDelivered: 1,
+			wantPublished: []int64{1},
+		},
+		{
+			name:          "неудача первого события не пропускает остальные вперед",
+			failOn:        map[int64]bool{1: true},
+			wantDelivered: 0,
+			wantFailed:    []failedAttempt{{eventID: 1, attempts: 1}},
+		},
+		{
+			name:          "ошибка отметки о неудаче возвращается",
+			failOn:        map[int64]bool{3: true},
+			markErr:       markErr,
+			wantDelivered: 2,
+			wantPublished: []int64{1, 2},
+			wantFailed:    []failedAttempt{{eventID: 3, attempts: 3}},
+			wantErr:       markErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var pending []pendingEvent
+			for id := int64(1); id <= 3; id++ {
+				pending = append(pending, pendingEvent{
+					event:    Event{EventID: id, Type: EventItemPlaced},
+					attempts: int(id - 1),
+					ready:    !tt.notReady[id],
+				})
+			}
+
+			publisher := &failingPublisher{MemoryPublisher: NewMemoryPublisher(), failOn: tt.failOn}
+			marker := &recordingMarker{failErr: tt.markErr}
+			relay := NewRelay(nil, publisher, RelayConfig{})
+
+			delivered, err := relay.publishPending(context.Background(), pending, marker)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("publishPending() ошибка = %v, ожидалось %v", err, tt.wantErr)
+			}
+			if delivered != tt.wantDelivered {
+				t.Errorf("доставлено %d, ожидалось %d", delivered, tt.wantDelivered)
+			}
+
+			var got []int64
+			for _, event := range publisher.Events() {
+				got = append(got, event.EventID)
+			}
+			if !reflect.DeepEqual(got, tt.wantPublished) {
+				t.Errorf("получатель получил %v, ожидалось %v", got, tt.wantPublished)
+			}
+			if !reflect.DeepEqual(marker.published, tt.wantPublished) {
+				t.Errorf("отмечены доставленными %v, ожидалось %v", marker.published, tt.wantPublished)
+			}
+			if !reflect.DeepEqual(marker.failed, tt.wantFailed) {
+				t.Errorf("отмечены неудачи %v, ожидалось %v", marker.failed, tt.wantFailed)
+			}
+		})
+	}
+}
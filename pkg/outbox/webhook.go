@@ -0,0 +1,108 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// WebhookConfig - параметры доставки событий HTTP-вебхуком
+type WebhookConfig struct {
+	URL string
+	// Secret - ключ подписи HMAC-SHA256; пустой ключ отключает подпись
+	Secret string
+	// Timeout - таймаут одного запроса
+	Timeout time.Duration
+	// MaxRetries - сколько раз повторить запрос после неудачи, прежде чем вернуть ошибку
+	MaxRetries int
+	// RetryBackoff - пауза перед первым повтором; каждая следующая пауза вдвое длиннее
+	RetryBackoff time.Duration
+}
+
+// WebhookPublisher отправляет событие POST-запросом с JSON-телом. Запрос подписывается
+// заголовком X-Warehouse-Signature: sha256=HMAC(secret, timestamp + "." + body), где timestamp -
+// значение заголовка X-Warehouse-Timestamp (Unix-время в секундах)
+type WebhookPublisher struct {
+	client *http.Client
+	config WebhookConfig
+}
+
+func NewWebhookPublisher(cfg WebhookConfig) *WebhookPublisher {
+	return &WebhookPublisher{
+		client: &http.Client{Timeout: cfg.Timeout},
+		config: cfg,
+	}
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, event Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации события: %w", err)
+	}
+
+	backoff := p.config.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := p.send(ctx, event, body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= p.config.MaxRetries {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// send выполняет один запрос и сообщает, имеет ли смысл его повторять
+func (p *WebhookPublisher) send(ctx context.Context, event Event, body []byte) (bool, error) {
+	request, err := http.NewRequestWithContext(ctx, "POST", p.config.URL, bytes.NewReader(body))
+	if err != nil {
+		return false, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-Warehouse-Event-Id", strconv.FormatInt(event.EventID, 10))
+	request.Header.Set("X-Warehouse-Event-Type", event.Type)
+	request.Header.Set("X-Warehouse-Timestamp", timestamp)
+	if p.config.Secret != "" {
+		request.Header.Set("X-Warehouse-Signature", "sha256="+Sign(p.config.Secret, timestamp, body))
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		return true, fmt.Errorf("ошибка отправки события: %w", err)
+	}
+	defer response.Body.Close()
+
+	switch {
+	case response.StatusCode >= 200 && response.StatusCode < 300:
+		return false, nil
+	case response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500:
+		return true, fmt.Errorf("получатель вернул %d", response.StatusCode)
+	default:
+		return false, fmt.Errorf("получатель отклонил событие: %d", response.StatusCode)
+	}
+}
+
+// Sign вычисляет подпись тела вебхука; получатель сравнивает ее с X-Warehouse-Signature
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,172 @@
+package outbox
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	body := []byte(`{"event_id":1}`)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      []byte
+		want      string
+	}{
+		{
+			name:      "подпись события",
+			secret:    "secret",
+			timestamp: "1700000000",
+			body:      body,
+			want:      "dd50adb138aae6c63e07ca88318bb0ffda13bcba001bd50739b8d68637c1aafe",
+		},
+		{
+			name:      "другой ключ",
+			secret:    "другой ключ",
+			timestamp: "1700000000",
+			body:      body,
+			want:      "eff1a409a50c63492bdbccbb2a1622b17b91139f62e88467ee26b3c279d2b0c8",
+		},
+		{
+			name:      "timestamp входит в подпись",
+			secret:    "secret",
+			timestamp: "1700000001",
+			body:      body,
+			want:      "2a58221e2f1476409a05bec92ab3fafbed7bad4d26c2b6630d3776a9efc8e81c",
+		},
+		{
+			name:      "пустое тело",
+			secret:    "secret",
+			timestamp: "1700000000",
+			want:      "4bc5f74d868b97888288889c5d9d65df02526f94c1592a79fdf4fe8b26e311e5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, tt.timestamp, tt.body); got != tt.want {
+				t.Errorf("Sign() = %s, ожидалось %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// webhookReceiver отвечает кодами из statuses по очереди (последний повторяется) и запоминает запросы
+type webhookReceiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []*http.Request
+	bodies   [][]byte
+}
+
+func (r *webhookReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	status := r.statuses[len(r.statuses)-1]
+	if len(r.requests) < len(r.statuses) {
+		status = r.statuses[len(r.requests)]
+	}
+	r.requests = append(r.requests, req)
+	r.bodies = append(r.bodies, body)
+	w.WriteHeader(status)
+}
+
+func TestWebhookPublisherRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		maxRetries   int
+		wantRequests int
+		wantErr      bool
+	}{
+		{name: "успешная доставка", statuses: []int{http.StatusNoContent}, maxRetries: 3, wantRequests: 1},
+		{name: "5xx повторяется", statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK}, maxRetries: 3, wantRequests: 3},
+		{name: "429 повторяется", statuses: []int{http.StatusTooManyRequests, http.StatusOK}, maxRetries: 3, wantRequests: 2},
+		{name: "повторы ограничены MaxRetries", statuses: []int{http.StatusInternalServerError}, maxRetries: 2, wantRequests: 3, wantErr: true},
+		{name: "4xx не повторяется", statuses: []int{http.StatusBadRequest, http.StatusOK}, maxRetries: 3, wantRequests: 1, wantErr: true},
+		{name: "без повторов", statuses: []int{http.StatusInternalServerError, http.StatusOK}, maxRetries: 0, wantRequests: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := &webhookReceiver{statuses: tt.statuses}
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			publisher := NewWebhookPublisher(WebhookConfig{
+				URL:          server.URL,
+				Timeout:      time.Second,
+				MaxRetries:   tt.maxRetries,
+				RetryBackoff: time.Millisecond,
+			})
+
+			err := publisher.Publish(context.Background(), Event{EventID: 7, Type: EventItemPlaced, SlotID: "A-01"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Publish() ошибка = %v, ожидалась ошибка: %v", err, tt.wantErr)
+			}
+			if len(receiver.requests) != tt.wantRequests {
+				t.Errorf("получатель получил %d запросов, ожидалось %d", len(receiver.requests), tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestWebhookPublisherHeaders(t *testing.T) {
+	tests := []struct {
+		name          string
+		secret        string
+		wantSignature bool
+	}{
+		{name: "запрос подписан", secret: "secret", wantSignature: true},
+		{name: "без ключа подписи нет", secret: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiver := &webhookReceiver{statuses: []int{http.StatusOK}}
+			server := httptest.NewServer(receiver)
+			defer server.Close()
+
+			publisher := NewWebhookPublisher(WebhookConfig{URL: server.URL, Secret: tt.secret, Timeout: time.Second})
+			if err := publisher.Publish(context.Background(), Event{EventID: 42, Type: EventSlotReleased, SlotID: "A-01"}); err != nil {
+				t.Fatalf("Publish() ошибка = %v", err)
+			}
+			if len(receiver.requests) != 1 {
+				t.Fatalf("получатель получил %d запросов, ожидался 1", len(receiver.requests))
+			}
+
+			header := receiver.requests[0].Header
+			if got := header.Get("X-Warehouse-Event-Id"); got != "42" {
+				t.Errorf("X-Warehouse-Event-Id = %q, ожидалось 42", got)
+			}
+			if got := header.Get("X-Warehouse-Event-Type"); got != EventSlotReleased {
+				t.Errorf("X-Warehouse-Event-Type = %q, ожидалось %s", got, EventSlotReleased)
+			}
+			timestamp := header.Get("X-Warehouse-Timestamp")
+			if _, err := strconv.ParseInt(timestamp, 10, 64); err != nil {
+				t.Errorf("X-Warehouse-Timestamp = %q, ожидалось Unix-время", timestamp)
+			}
+
+			signature := header.Get("X-Warehouse-Signature")
+			if !tt.wantSignature {
+				if signature != "" {
+					t.Errorf("X-Warehouse-Signature = %q, ожидалось отсутствие подписи", signature)
+				}
+				return
+			}
+			if want := "sha256=" + Sign(tt.secret, timestamp, receiver.bodies[0]); signature != want {
+				t.Errorf("X-Warehouse-Signature = %q, ожидалось %q", signature, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package placement
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Версии контракта. Версия 1 - запросы без поля schema_version, которые отправляли клиенты
+// до появления версий; DecodeRequest поднимает их до текущей версии
+const (
+	SchemaVersion    = 2
+	MinSchemaVersion = 1
+)
+
+// Команды сервиса размещения
+const (
+	CommandAnalyze = "analyze"
+	CommandPlace   = "place"
+	CommandReserve = "reserve"
+	CommandCommit  = "commit"
+	CommandRelease = "release"
+)
+
+// DefaultWarehouseID - склад запросов без warehouse_id. Клиенты, написанные до появления
+// нескольких складов, продолжают размещать товар на основном складе
+const DefaultWarehouseID = "WH001"
+
+var (
+	// ErrInvalidRequest означает, что запрос не соответствует контракту; сервис отвечает 400
+	ErrInvalidRequest = errors.New("запрос не соответствует контракту")
+	// ErrUnsupportedSchemaVersion означает, что версия запроса не поддерживается
+	ErrUnsupportedSchemaVersion = fmt.Errorf("%w: неподдерживаемая версия схемы", ErrInvalidRequest)
+)
+
+// NewRequest создает запрос текущей версии
+func NewRequest(command string) *Request {
+	return &Request{SchemaVersion: SchemaVersion, Command: command}
+}
+
+// DecodeRequest разбирает тело запроса любой поддерживаемой версии, поднимает его до текущей
+// версии и проверяет. Неизвестные поля игнорируются, чтобы более новые клиенты не ломали старые сервисы
+func DecodeRequest(body []byte) (*Request, error) {
+	var probe struct {
+		SchemaVersion *int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(body, &probe); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+	}
+
+	version := MinSchemaVersion
+	if probe.SchemaVersion != nil {
+		version = *probe.SchemaVersion
+	}
+	if version < MinSchemaVersion || version > SchemaVersion {
+		return nil, fmt.Errorf("%w %d, поддерживаются %d-%d", ErrUnsupportedSchemaVersion, version, MinSchemaVersion, SchemaVersion)
+	}
+
+	var req Request
+	if err := json.Unmarshal(body, &req); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+	}
+	if version == 1 {
+		upgradeV1(&req)
+	}
+	req.SchemaVersion = SchemaVersion
+
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
+
+// upgradeV1 приводит запрос версии 1 к версии 2. В версии 1 сервисы сами определяли категории
+// товара и не проверяли регистр классов и команды, поэтому значения нормализуются
+func upgradeV1(req *Request) {
+	req.Command = strings.ToLower(strings.TrimSpace(req.Command))
+	req.ABCClass = strings.ToUpper(strings.TrimSpace(req.ABCClass))
+	req.XYZClass = strings.ToUpper(strings.TrimSpace(req.XYZClass))
+}
+
+// Warehouse возвращает склад запроса: WarehouseID или DefaultWarehouseID, если он не указан
+func (r *Request) Warehouse() string {
+	if r.WarehouseID == "" {
+		return DefaultWarehouseID
+	}
+	return r.WarehouseID
+}
+
+// Validate проверяет запрос текущей версии и перечисляет все нарушения сразу
+func (r *Request) Validate() error {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if r.SchemaVersion != SchemaVersion {
+		add("schema_version должна быть %d", SchemaVersion)
+	}
+	switch r.Command {
+	case CommandAnalyze, CommandPlace, CommandReserve:
+	case CommandCommit, CommandRelease:
+		if r.ReservationToken == "" {
+			add("reservation_token обязателен для команды %s", r.Command)
+		}
+	default:
+		add("неизвестная команда %q", r.Command)
+	}
+	if r.SlotID != "" && r.Command != CommandReserve {
+		add("slot_id допускается только для команды reserve")
+	}
+
+	if r.ItemID == "" {
+		add("item_id обязателен")
+	}
+	if r.Quantity < 0 {
+		add("quantity не может быть отрицательным")
+	}
+	if r.Weight < 0 {
+		add("weight не может быть отрицательным")
+	}
+	if r.Volume < 0 {
+		add("volume не может быть отрицательным")
+	}
+	for _, field := range []struct {
+		name  string
+		value float64
+	}{
+		{"turnover_rate", r.TurnoverRate},
+		{"demand_rate", r.DemandRate},
+		{"seasonality", r.Seasonality},
+		{"storage_humidity", r.StorageHumidity},
+		{"warehouse_load", r.WarehouseLoad},
+	} {
+		if field.value < 0 || field.value > 1 {
+			add("%s должен быть в диапазоне 0-1", field.name)
+		}
+	}
+	if !oneOf(r.ABCClass, "", "A", "B", "C") {
+		add("abc_class должен быть A, B или C")
+	}
+	if !oneOf(r.XYZClass, "", "X", "Y", "Z") {
+		add("xyz_class должен быть X, Y или Z")
+	}
+	if r.ReservationTTLSeconds < 0 {
+		add("reservation_ttl_seconds не может быть отрицательным")
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrInvalidRequest, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// DecodeResponse разбирает ответ сервиса любой поддерживаемой версии; ответы версии 1
+// не содержат schema_version и резервов
+func DecodeResponse(body []byte) (*Response, error) {
+	var resp Response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.SchemaVersion == 0 {
+		resp.SchemaVersion = MinSchemaVersion
+	}
+	if resp.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("неподдерживаемая версия схемы ответа %d", resp.SchemaVersion)
+	}
+	resp.SchemaVersion = SchemaVersion
+	return &resp, nil
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, candidate := range allowed {
+		if value == candidate {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,184 @@
+package placement
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUpgradeV1(t *testing.T) {
+	tests := []struct {
+		name string
+		req  Request
+		want Request
+	}{
+		{
+			name: "команда и классы нормализуются",
+			req:  Request{Command: " Analyze ", ABCClass: "a", XYZClass: " x "},
+			want: Request{Command: CommandAnalyze, ABCClass: "A", XYZClass: "X"},
+		},
+		{
+			name: "пустые классы остаются пустыми",
+			req:  Request{Command: "PLACE"},
+			want: Request{Command: CommandPlace},
+		},
+		{
+			name: "остальные поля не меняются",
+			req:  Request{Command: "reserve", ItemID: " item ", BatchID: "b", Quantity: 3, ABCClass: "B"},
+			want: Request{Command: CommandReserve, ItemID: " item ", BatchID: "b", Quantity: 3, ABCClass: "B"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.req
+			upgradeV1(&req)
+			if req != tt.want {
+				t.Errorf("upgradeV1() = %+v, ожидалось %+v", req, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	valid := func(command string) Request {
+		return Request{SchemaVersion: SchemaVersion, Command: command, ItemID: "ITEM001", Quantity: 1}
+	}
+	with := func(req Request, change func(*Request)) Request {
+		change(&req)
+		return req
+	}
+
+	tests := []struct {
+		name string
+		req  Request
+		// wantProblems - фрагменты сообщения об ошибке; пустой список - запрос корректен
+		wantProblems []string
+	}{
+		{name: "корректный analyze", req: valid(CommandAnalyze)},
+		{name: "корректный reserve с ячейкой", req: with(valid(CommandReserve), func(r *Request) { r.SlotID = "SLOT001" })},
+		{name: "корректный commit", req: with(valid(CommandCommit), func(r *Request) { r.ReservationToken = "token" })},
+		{
+			name:         "устаревшая версия схемы",
+			req:          with(valid(CommandAnalyze), func(r *Request) { r.SchemaVersion = MinSchemaVersion }),
+			wantProblems: []string{"schema_version должна быть 2"},
+		},
+		{
+			name:         "неизвестная команда",
+			req:          valid("teleport"),
+			wantProblems: []string{`неизвестная команда "teleport"`},
+		},
+		{
+			name:         "release без токена",
+			req:          valid(CommandRelease),
+			wantProblems: []string{"reservation_token обязателен для команды release"},
+		},
+		{
+			name:         "slot_id вне команды reserve",
+			req:          with(valid(CommandPlace), func(r *Request) { r.SlotID = "SLOT001" }),
+			wantProblems: []string{"slot_id допускается только для команды reserve"},
+		},
+		{
+			name: "отрицательные величины",
+			req: with(valid(CommandPlace), func(r *Request) {
+				r.Quantity, r.Weight, r.Volume, r.ReservationTTLSeconds = -1, -1, -1, -1
+			}),
+			wantProblems: []string{
+				"quantity не может быть отрицательным",
+				"weight не может быть отрицательным",
+				"volume не может быть отрицательным",
+				"reservation_ttl_seconds не может быть отрицательным",
+			},
+		},
+		{
+			name: "доли вне диапазона 0-1",
+			req: with(valid(CommandAnalyze), func(r *Request) {
+				r.TurnoverRate, r.WarehouseLoad = -0.1, 1.5
+			}),
+			wantProblems: []string{"turnover_rate должен быть в диапазоне 0-1", "warehouse_load должен быть в диапазоне 0-1"},
+		},
+		{
+			name:         "классы в нижнем регистре",
+			req:          with(valid(CommandAnalyze), func(r *Request) { r.ABCClass, r.XYZClass = "a", "x" }),
+			wantProblems: []string{"abc_class должен быть A, B или C", "xyz_class должен быть X, Y или Z"},
+		},
+		{
+			name:         "все нарушения перечисляются сразу",
+			req:          Request{SchemaVersion: SchemaVersion, Command: CommandCommit},
+			wantProblems: []string{"reservation_token обязателен для команды commit", "item_id обязателен"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if len(tt.wantProblems) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, ожидался nil", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidRequest) {
+				t.Fatalf("Validate() = %v, ожидалась ErrInvalidRequest", err)
+			}
+			for _, problem := range tt.wantProblems {
+				if !strings.Contains(err.Error(), problem) {
+					t.Errorf("в ошибке %q нет %q", err.Error(), problem)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeRequest(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    Request
+		wantErr error
+	}{
+		{
+			name: "запрос версии 1 поднимается до текущей",
+			body: `{"command":" ANALYZE ","item_id":"ITEM001","abc_class":"a"}`,
+			want: Request{SchemaVersion: SchemaVersion, Command: CommandAnalyze, ItemID: "ITEM001", ABCClass: "A"},
+		},
+		{
+			name:    "запрос текущей версии не нормализуется",
+			body:    `{"schema_version":2,"command":"analyze","item_id":"ITEM001","abc_class":"a"}`,
+			wantErr: ErrInvalidRequest,
+		},
+		{
+			name:    "версия выше поддерживаемой",
+			body:    `{"schema_version":3,"command":"analyze","item_id":"ITEM001"}`,
+			wantErr: ErrUnsupportedSchemaVersion,
+		},
+		{
+			name:    "нулевая версия",
+			body:    `{"schema_version":0,"command":"analyze","item_id":"ITEM001"}`,
+			wantErr: ErrUnsupportedSchemaVersion,
+		},
+		{
+			name:    "некорректный JSON",
+			body:    `{"command":`,
+			wantErr: ErrInvalidRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := DecodeRequest([]byte(tt.body))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("DecodeRequest() = %v, ожидалась %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeRequest() = %v", err)
+			}
+			if *req != tt.want {
+				t.Errorf("DecodeRequest() = %+v, ожидалось %+v", *req, tt.want)
+			}
+		})
+	}
+}
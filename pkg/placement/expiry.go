@@ -0,0 +1,28 @@
+package placement
+
+import "time"
+
+// ShortDatedWindow - остаток срока годности, начиная с которого партия считается короткой
+// и размещается ближе к выходу, чтобы ее отобрали раньше
+const ShortDatedWindow = 30 * 24 * time.Hour
+
+// ExpiryUrgency возвращает срочность партии по сроку годности от 0 до 1: 0 - срок не указан или
+// до него больше ShortDatedWindow, 1 - партия просрочена; в окне срочность растет линейно
+func ExpiryUrgency(expiresAt *time.Time, now time.Time) float64 {
+	if expiresAt == nil {
+		return 0
+	}
+	left := expiresAt.Sub(now)
+	switch {
+	case left <= 0:
+		return 1
+	case left >= ShortDatedWindow:
+		return 0
+	}
+	return 1 - float64(left)/float64(ShortDatedWindow)
+}
+
+// Expired сообщает, истек ли срок годности партии к моменту now
+func Expired(expiresAt *time.Time, now time.Time) bool {
+	return expiresAt != nil && !expiresAt.After(now)
+}
@@ -0,0 +1,104 @@
+// Package placement - общий контракт сервисов размещения: версионированные запрос и ответ,
+// их проверка и разбор, и интерфейс алгоритма. Интерфейс реализует PlacementService каждого сервиса,
+// поэтому оркестратор может вызывать алгоритм в том же процессе, без HTTP и gRPC
+package placement
+
+import (
+	"context"
+	"time"
+)
+
+// Request - запрос к сервису размещения, тело HTTP-запроса. Разбирать его следует DecodeRequest,
+// который поднимает запросы старых версий до текущей
+type Request struct {
+	SchemaVersion int    `json:"schema_version"`
+	Command       string `json:"command"`
+
+	// WarehouseID - склад, в ячейках которого подбирается место; пустое значение - DefaultWarehouseID
+	WarehouseID string `json:"warehouse_id,omitempty"`
+	// DockID - ворота, от которых считается путь до ячейки, если у склада задана топология;
+	// пустое значение - ближайшие к ячейке ворота
+	DockID string `json:"dock_id,omitempty"`
+	// SlotID - ячейка, которую нужно зарезервировать, только для команды reserve; пустое значение -
+	// лучшая ячейка алгоритма. Оркестратор передает его, распределив ячейки между строками пакета
+	SlotID string `json:"slot_id,omitempty"`
+
+	ItemID   string `json:"item_id"`
+	BatchID  string `json:"batch_id"`
+	Quantity int    `json:"quantity"`
+
+	Weight          float64 `json:"weight"`
+	Volume          float64 `json:"volume"`
+	TurnoverRate    float64 `json:"turnover_rate"`
+	DemandRate      float64 `json:"demand_rate"`
+	Seasonality     float64 `json:"seasonality"`
+	ABCClass        string  `json:"abc_class"`
+	XYZClass        string  `json:"xyz_class"`
+	IsHeavy         bool    `json:"is_heavy"`
+	IsFragile       bool    `json:"is_fragile"`
+	IsHazardous     bool    `json:"is_hazardous"`
+	StorageTemp     float64 `json:"storage_temp"`
+	StorageHumidity float64 `json:"storage_humidity"`
+
+	WarehouseLoad  float64 `json:"warehouse_load"`
+	HasFixedSlot   bool    `json:"has_fixed_slot"`
+	FastAccessZone bool    `json:"fast_access_zone"`
+
+	ReservationToken      string `json:"reservation_token,omitempty"`
+	ReservationTTLSeconds int    `json:"reservation_ttl_seconds,omitempty"`
+}
+
+// Response - ответ сервиса размещения
+type Response struct {
+	SchemaVersion int `json:"schema_version"`
+
+	Success bool    `json:"success"`
+	SlotID  string  `json:"slot_id,omitempty"`
+	Comment string  `json:"comment"`
+	Score   float64 `json:"score"`
+
+	// BaseScore - оценка ячейки самим алгоритмом (S0 в политике оценки оркестратора)
+	BaseScore float64 `json:"base_score"`
+	// DistanceToExit - расстояние от ячейки до выхода (Δd); nil - алгоритм расстояние не знает
+	DistanceToExit *float64 `json:"distance_to_exit,omitempty"`
+
+	ReservationToken string     `json:"reservation_token,omitempty"`
+	ReservedUntil    *time.Time `json:"reserved_until,omitempty"`
+}
+
+// Service - алгоритм размещения. Команды те же, что у HTTP- и gRPC-контракта сервисов, поле Command
+// запроса задает сам метод. Place, Reserve, Commit и Release учитывают ключ идемпотентности
+// из контекста (WithIdempotencyKey)
+type Service interface {
+	// Analyze подбирает ячейку без размещения
+	Analyze(ctx context.Context, req *Request) (*Response, error)
+	// Place подбирает ячейку и размещает в ней товар
+	Place(ctx context.Context, req *Request) (*Response, error)
+	// Reserve подбирает ячейку и удерживает ее на ReservationTTLSeconds
+	Reserve(ctx context.Context, req *Request) (*Response, error)
+	// Commit размещает товар в ячейке, удерживаемой ReservationToken
+	Commit(ctx context.Context, req *Request) (*Response, error)
+	// Release снимает резерв ReservationToken
+	Release(ctx context.Context, req *Request) (*Response, error)
+	// Candidates возвращает до limit подходящих ячеек, начиная с лучшей (0 - все);
+	// если ни одна не подходит, возвращается один ответ с Success = false и причиной
+	Candidates(ctx context.Context, req *Request, limit int) ([]Response, error)
+	// HealthCheck проверяет, что алгоритму доступна база данных
+	HealthCheck(ctx context.Context) error
+}
+
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey передает алгоритму ключ идемпотентности команды
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	if key == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
+}
+
+// IdempotencyKey возвращает ключ идемпотентности команды из контекста
+func IdempotencyKey(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key
+}
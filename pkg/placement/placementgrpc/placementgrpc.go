@@ -0,0 +1,233 @@
+// Package placementgrpc - gRPC-транспорт контракта placement: сервер placement.v1 поверх любой
+// реализации placement.Service и преобразование запросов и ответов между контрактом и сообщениями
+// protobuf. Сервер используют все сервисы размещения, преобразования - еще и клиент оркестратора
+package placementgrpc
+
+import (
+	"context"
+	"errors"
+
+	"warehouse/pkg/idempotency"
+	"warehouse/pkg/placement"
+	placementv1 "warehouse/proto/placement/v1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// IdempotencyKeyMetadata - ключ метаданных gRPC с ключом идемпотентности, аналог заголовка Idempotency-Key
+const IdempotencyKeyMetadata = "idempotency-key"
+
+// Server обслуживает контракт размещения по gRPC рядом с маршрутами Gin
+type Server struct {
+	placementv1.UnimplementedPlacementServiceServer
+	service placement.Service
+}
+
+// NewServer создает gRPC-сервер алгоритма размещения
+func NewServer(service placement.Service) *Server {
+	return &Server{service: service}
+}
+
+// Register регистрирует сервис размещения на gRPC-сервере
+func (s *Server) Register(server *grpc.Server) {
+	placementv1.RegisterPlacementServiceServer(server, s)
+}
+
+func (s *Server) Analyze(ctx context.Context, req *placementv1.PlacementRequest) (*placementv1.PlacementResponse, error) {
+	return s.call(ctx, req, placement.CommandAnalyze, s.service.Analyze)
+}
+
+func (s *Server) Place(ctx context.Context, req *placementv1.PlacementRequest) (*placementv1.PlacementResponse, error) {
+	return s.call(ctx, req, placement.CommandPlace, s.service.Place)
+}
+
+func (s *Server) Reserve(ctx context.Context, req *placementv1.PlacementRequest) (*placementv1.PlacementResponse, error) {
+	return s.call(ctx, req, placement.CommandReserve, s.service.Reserve)
+}
+
+func (s *Server) Commit(ctx context.Context, req *placementv1.PlacementRequest) (*placementv1.PlacementResponse, error) {
+	return s.call(ctx, req, placement.CommandCommit, s.service.Commit)
+}
+
+func (s *Server) Release(ctx context.Context, req *placementv1.PlacementRequest) (*placementv1.PlacementResponse, error) {
+	return s.call(ctx, req, placement.CommandRelease, s.service.Release)
+}
+
+// StreamCandidates передает подходящие ячейки по одной, начиная с лучшей; если ни одна
+// не подходит, передается один ответ с success = false и причиной
+func (s *Server) StreamCandidates(req *placementv1.CandidatesRequest, stream placementv1.PlacementService_StreamCandidatesServer) error {
+	if req.GetRequest() == nil {
+		return status.Error(codes.InvalidArgument, "не передан запрос")
+	}
+
+	request, err := validRequest(req.GetRequest(), placement.CommandAnalyze)
+	if err != nil {
+		return err
+	}
+
+	candidates, err := s.service.Candidates(stream.Context(), request, int(req.GetLimit()))
+	if err != nil {
+		return grpcError(err)
+	}
+	for i := range candidates {
+		if err := stream.Send(ResponseToProto(&candidates[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// call проверяет запрос по контракту, передает алгоритму ключ идемпотентности из метаданных
+// и выполняет команду
+func (s *Server) call(ctx context.Context, req *placementv1.PlacementRequest, command string, run func(ctx context.Context, req *placement.Request) (*placement.Response, error)) (*placementv1.PlacementResponse, error) {
+	request, err := validRequest(req, command)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := run(placement.WithIdempotencyKey(ctx, idempotencyKey(ctx)), request)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return ResponseToProto(response), nil
+}
+
+// idempotencyKey читает ключ идемпотентности из метаданных idempotency-key
+func idempotencyKey(ctx context.Context) string {
+	values := metadata.ValueFromIncomingContext(ctx, IdempotencyKeyMetadata)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+// grpcError переводит ошибки алгоритма в коды статуса gRPC
+func grpcError(err error) error {
+	switch {
+	case errors.Is(err, idempotency.ErrKeyReused):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, idempotency.ErrInProgress):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	default:
+		return status.Error(codes.Internal, "внутренняя ошибка сервера")
+	}
+}
+
+// validRequest преобразует запрос gRPC и проверяет его по контракту placement
+func validRequest(req *placementv1.PlacementRequest, command string) (*placement.Request, error) {
+	request := RequestFromProto(req, command)
+	if err := request.Validate(); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return request, nil
+}
+
+// RequestFromProto преобразует запрос gRPC в запрос текущей версии контракта с командой command
+func RequestFromProto(req *placementv1.PlacementRequest, command string) *placement.Request {
+	return &placement.Request{
+		SchemaVersion: placement.SchemaVersion,
+		Command:       command,
+		WarehouseID:   req.GetWarehouseId(),
+		DockID:        req.GetDockId(),
+		SlotID:        req.GetSlotId(),
+
+		ItemID:   req.GetItemId(),
+		BatchID:  req.GetBatchId(),
+		Quantity: int(req.GetQuantity()),
+
+		Weight:          req.GetWeight(),
+		Volume:          req.GetVolume(),
+		TurnoverRate:    req.GetTurnoverRate(),
+		DemandRate:      req.GetDemandRate(),
+		Seasonality:     req.GetSeasonality(),
+		ABCClass:        req.GetAbcClass(),
+		XYZClass:        req.GetXyzClass(),
+		IsHeavy:         req.GetIsHeavy(),
+		IsFragile:       req.GetIsFragile(),
+		IsHazardous:     req.GetIsHazardous(),
+		StorageTemp:     req.GetStorageTemp(),
+		StorageHumidity: req.GetStorageHumidity(),
+
+		WarehouseLoad:  req.GetWarehouseLoad(),
+		HasFixedSlot:   req.GetHasFixedSlot(),
+		FastAccessZone: req.GetFastAccessZone(),
+
+		ReservationToken:      req.GetReservationToken(),
+		ReservationTTLSeconds: int(req.GetReservationTtlSeconds()),
+	}
+}
+
+// RequestToProto преобразует запрос контракта в запрос gRPC; команду задает вызываемый метод
+func RequestToProto(req *placement.Request) *placementv1.PlacementRequest {
+	return &placementv1.PlacementRequest{
+		WarehouseId: req.WarehouseID,
+		DockId:      req.DockID,
+		SlotId:      req.SlotID,
+
+		ItemId:   req.ItemID,
+		BatchId:  req.BatchID,
+		Quantity: int32(req.Quantity),
+
+		Weight:          req.Weight,
+		Volume:          req.Volume,
+		TurnoverRate:    req.TurnoverRate,
+		DemandRate:      req.DemandRate,
+		Seasonality:     req.Seasonality,
+		AbcClass:        req.ABCClass,
+		XyzClass:        req.XYZClass,
+		IsHeavy:         req.IsHeavy,
+		IsFragile:       req.IsFragile,
+		IsHazardous:     req.IsHazardous,
+		StorageTemp:     req.StorageTemp,
+		StorageHumidity: req.StorageHumidity,
+
+		WarehouseLoad:  req.WarehouseLoad,
+		HasFixedSlot:   req.HasFixedSlot,
+		FastAccessZone: req.FastAccessZone,
+
+		ReservationToken:      req.ReservationToken,
+		ReservationTtlSeconds: int32(req.ReservationTTLSeconds),
+	}
+}
+
+// ResponseToProto преобразует ответ контракта в ответ gRPC
+func ResponseToProto(response *placement.Response) *placementv1.PlacementResponse {
+	resp := &placementv1.PlacementResponse{
+		Success:          response.Success,
+		SlotId:           response.SlotID,
+		Comment:          response.Comment,
+		Score:            response.Score,
+		BaseScore:        response.BaseScore,
+		DistanceToExit:   response.DistanceToExit,
+		ReservationToken: response.ReservationToken,
+	}
+	if response.ReservedUntil != nil {
+		resp.ReservedUntil = timestamppb.New(*response.ReservedUntil)
+	}
+	return resp
+}
+
+// ResponseFromProto преобразует ответ gRPC в ответ текущей версии контракта
+func ResponseFromProto(resp *placementv1.PlacementResponse) *placement.Response {
+	result := &placement.Response{
+		SchemaVersion:    placement.SchemaVersion,
+		Success:          resp.GetSuccess(),
+		SlotID:           resp.GetSlotId(),
+		Comment:          resp.GetComment(),
+		Score:            resp.GetScore(),
+		BaseScore:        resp.GetBaseScore(),
+		DistanceToExit:   resp.DistanceToExit,
+		ReservationToken: resp.GetReservationToken(),
+	}
+	if resp.GetReservedUntil() != nil {
+		reservedUntil := resp.GetReservedUntil().AsTime()
+		result.ReservedUntil = &reservedUntil
+	}
+	return result
+}
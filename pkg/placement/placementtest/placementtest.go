@@ -0,0 +1,175 @@
+// Package placementtest - контрактный набор проверок HTTP-обработчика сервиса размещения.
+// Его должен проходить обработчик каждого сервиса, подключенный к рабочей или тестовой базе.
+// Набор ничего не меняет в базе: запросы либо отклоняются проверкой контракта, либо выполняют
+// analyze для заведомо отсутствующего товара
+package placementtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"warehouse/pkg/placement"
+)
+
+// MissingItemID и MissingBatchID - товар и партия, которых нет в справочнике
+const (
+	MissingItemID  = "contract-check-missing-item"
+	MissingBatchID = "contract-check-missing-batch"
+)
+
+// Case - одна проверка контракта: тело запроса и ожидаемый ответ
+type Case struct {
+	Name   string
+	Body   string
+	Status int
+	// Check дополнительно проверяет тело ответа со статусом 200
+	Check func(resp *placement.Response) error
+}
+
+// Cases возвращает проверки контракта текущей версии
+func Cases() []Case {
+	return []Case{
+		{
+			Name:   "некорректный JSON",
+			Body:   `{"command":`,
+			Status: http.StatusBadRequest,
+		},
+		{
+			Name:   "неподдерживаемая версия схемы",
+			Body:   fmt.Sprintf(`{"schema_version":%d,"command":"analyze","item_id":%q}`, placement.SchemaVersion+1, MissingItemID),
+			Status: http.StatusBadRequest,
+		},
+		{
+			Name:   "неизвестная команда",
+			Body:   fmt.Sprintf(`{"schema_version":%d,"command":"teleport","item_id":%q}`, placement.SchemaVersion, MissingItemID),
+			Status: http.StatusBadRequest,
+		},
+		{
+			Name:   "нет item_id",
+			Body:   fmt.Sprintf(`{"schema_version":%d,"command":"analyze"}`, placement.SchemaVersion),
+			Status: http.StatusBadRequest,
+		},
+		{
+			Name:   "отрицательное количество",
+			Body:   fmt.Sprintf(`{"schema_version":%d,"command":"place","item_id":%q,"quantity":-1}`, placement.SchemaVersion, MissingItemID),
+			Status: http.StatusBadRequest,
+		},
+		{
+			Name:   "доля вне диапазона 0-1",
+			Body:   fmt.Sprintf(`{"schema_version":%d,"command":"analyze","item_id":%q,"warehouse_load":1.5}`, placement.SchemaVersion, MissingItemID),
+			Status: http.StatusBadRequest,
+		},
+		{
+			Name:   "класс в нижнем регистре в текущей версии",
+			Body:   fmt.Sprintf(`{"schema_version":%d,"command":"analyze","item_id":%q,"abc_class":"a"}`, placement.SchemaVersion, MissingItemID),
+			Status: http.StatusBadRequest,
+		},
+		{
+			Name:   "commit без токена резерва",
+			Body:   fmt.Sprintf(`{"schema_version":%d,"command":"commit","item_id":%q}`, placement.SchemaVersion, MissingItemID),
+			Status: http.StatusBadRequest,
+		},
+		{
+			Name:   "release без токена резерва",
+			Body:   fmt.Sprintf(`{"schema_version":%d,"command":"release","item_id":%q}`, placement.SchemaVersion, MissingItemID),
+			Status: http.StatusBadRequest,
+		},
+		{
+			Name:   "slot_id в команде analyze",
+			Body:   fmt.Sprintf(`{"schema_version":%d,"command":"analyze","item_id":%q,"slot_id":"SLOT001"}`, placement.SchemaVersion, MissingItemID),
+			Status: http.StatusBadRequest,
+		},
+		{
+			Name:   "analyze неизвестного товара",
+			Body:   fmt.Sprintf(`{"schema_version":%d,"command":"analyze","item_id":%q,"batch_id":%q}`, placement.SchemaVersion, MissingItemID, MissingBatchID),
+			Status: http.StatusOK,
+			Check:  rejected,
+		},
+		{
+			Name:   "analyze неизвестного товара на указанном складе",
+			Body:   fmt.Sprintf(`{"schema_version":%d,"command":"analyze","warehouse_id":"WH002","item_id":%q,"batch_id":%q}`, placement.SchemaVersion, MissingItemID, MissingBatchID),
+			Status: http.StatusOK,
+			Check:  rejected,
+		},
+		{
+			Name:   "запрос версии 1 без schema_version",
+			Body:   fmt.Sprintf(`{"command":"analyze","item_id":%q,"batch_id":%q,"abc_class":"a","xyz_class":" x "}`, MissingItemID, MissingBatchID),
+			Status: http.StatusOK,
+			Check:  rejected,
+		},
+	}
+}
+
+// Check прогоняет Cases на обработчике, принимающем запросы на path, и возвращает все нарушения
+// контракта одной ошибкой; nil - обработчик соблюдает контракт
+func Check(handler http.Handler, path string) error {
+	var failures []error
+	for _, c := range Cases() {
+		if err := run(handler, path, c); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", c.Name, err))
+		}
+	}
+	return errors.Join(failures...)
+}
+
+func run(handler http.Handler, path string, c Case) error {
+	request := httptest.NewRequest(http.MethodPost, path, bytes.NewBufferString(c.Body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	if recorder.Code != c.Status {
+		return fmt.Errorf("статус %d, ожидался %d: %s", recorder.Code, c.Status, recorder.Body.String())
+	}
+
+	if c.Status != http.StatusOK {
+		var body struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(recorder.Body.Bytes(), &body); err != nil || body.Error == "" {
+			return fmt.Errorf("ответ с ошибкой должен содержать поле error: %s", recorder.Body.String())
+		}
+		return nil
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(recorder.Body.Bytes(), &raw); err != nil {
+		return fmt.Errorf("ответ не является объектом JSON: %w", err)
+	}
+	for _, field := range []string{"schema_version", "success", "comment", "score", "base_score"} {
+		if _, ok := raw[field]; !ok {
+			return fmt.Errorf("в ответе нет поля %s", field)
+		}
+	}
+
+	var resp placement.Response
+	if err := json.Unmarshal(recorder.Body.Bytes(), &resp); err != nil {
+		return fmt.Errorf("ошибка разбора ответа: %w", err)
+	}
+	if resp.SchemaVersion != placement.SchemaVersion {
+		return fmt.Errorf("schema_version ответа %d, ожидалась %d", resp.SchemaVersion, placement.SchemaVersion)
+	}
+	if c.Check != nil {
+		return c.Check(&resp)
+	}
+	return nil
+}
+
+// rejected проверяет отказ: success = false, причина в comment, ячейка и резерв не выданы
+func rejected(resp *placement.Response) error {
+	switch {
+	case resp.Success:
+		return errors.New("ожидался отказ, получен success = true")
+	case resp.Comment == "":
+		return errors.New("отказ без причины в comment")
+	case resp.SlotID != "":
+		return fmt.Errorf("отказ с ячейкой %s", resp.SlotID)
+	case resp.ReservationToken != "" || resp.ReservedUntil != nil:
+		return errors.New("отказ с резервом")
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+package placement
+
+// Зоны склада в порядке удаления от выхода
+const (
+	ZoneFastAccess = "fast-access"
+	ZoneRegular    = "regular"
+	ZoneDeep       = "deep"
+)
+
+// ABCClass определяет ABC-категорию товара по оборачиваемости. Пороги общие для сервиса ABC-размещения
+// и оркестратора, который по ним дополняет запрос и планирует перераскладку
+func ABCClass(turnover float64) string {
+	switch {
+	case turnover >= 0.8:
+		return "A"
+	case turnover >= 0.15:
+		return "B"
+	default:
+		return "C"
+	}
+}
+
+// XYZClass определяет XYZ-категорию товара по коэффициенту вариации спроса mr
+func XYZClass(mr float64) string {
+	switch {
+	case mr < 0.1:
+		return "X"
+	case mr < 0.25:
+		return "Y"
+	default:
+		return "Z"
+	}
+}
+
+// ClassZone возвращает зону для ABC- или XYZ-категории: A и X - fast-access, B и Y - regular,
+// C и Z - deep; неизвестная категория размещается в regular
+func ClassZone(class string) string {
+	switch class {
+	case "A", "X":
+		return ZoneFastAccess
+	case "C", "Z":
+		return ZoneDeep
+	default:
+		return ZoneRegular
+	}
+}
+
+// FasterZone возвращает зону на шаг ближе к выходу, куда размещаются короткие партии;
+// false - зона уже ближайшая к выходу или неизвестна
+func FasterZone(zone string) (string, bool) {
+	switch zone {
+	case ZoneDeep:
+		return ZoneRegular, true
+	case ZoneRegular:
+		return ZoneFastAccess, true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,34 @@
+package placement
+
+import "testing"
+
+func TestClassZone(t *testing.T) {
+	tests := []struct {
+		name       string
+		class      string
+		wantZone   string
+		wantFast   string
+		wantFaster bool
+	}{
+		{name: "A - у выхода", class: ABCClass(0.8), wantZone: ZoneFastAccess},
+		{name: "B - обычная зона", class: ABCClass(0.15), wantZone: ZoneRegular, wantFast: ZoneFastAccess, wantFaster: true},
+		{name: "C - дальняя зона", class: ABCClass(0.1499), wantZone: ZoneDeep, wantFast: ZoneRegular, wantFaster: true},
+		{name: "X - у выхода", class: XYZClass(0.0999), wantZone: ZoneFastAccess},
+		{name: "Y - обычная зона", class: XYZClass(0.1), wantZone: ZoneRegular, wantFast: ZoneFastAccess, wantFaster: true},
+		{name: "Z - дальняя зона", class: XYZClass(0.25), wantZone: ZoneDeep, wantFast: ZoneRegular, wantFaster: true},
+		{name: "неизвестная категория - обычная зона", class: "Q", wantZone: ZoneRegular, wantFast: ZoneFastAccess, wantFaster: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zone := ClassZone(tt.class)
+			if zone != tt.wantZone {
+				t.Fatalf("ClassZone(%s) = %s, ожидалось %s", tt.class, zone, tt.wantZone)
+			}
+			faster, ok := FasterZone(zone)
+			if faster != tt.wantFast || ok != tt.wantFaster {
+				t.Errorf("FasterZone(%s) = %s, %v, ожидалось %s, %v", zone, faster, ok, tt.wantFast, tt.wantFaster)
+			}
+		})
+	}
+}
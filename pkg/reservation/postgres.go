@@ -0,0 +1,145 @@
+package reservation
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"warehouse/pkg/capacity"
+	"warehouse/pkg/placement"
+	"warehouse/pkg/stock"
+)
+
+// ErrSlotReserved означает, что ячейку удерживает резерв другого запроса и занять ее без токена резерва нельзя
+var ErrSlotReserved = errors.New("ячейка зарезервирована другим запросом")
+
+// SlotStore - Store поверх таблиц slots и placement_requests. Репозитории сервисов размещения
+// встраивают его, поэтому SQL резервирования и размещения у всех сервисов один
+type SlotStore struct {
+	db *sql.DB
+}
+
+func NewSlotStore(db *sql.DB) *SlotStore {
+	return &SlotStore{db: db}
+}
+
+// ResolveLoad рассчитывает груз размещаемого количества; 0 - вся партия
+func (s *SlotStore) ResolveLoad(ctx context.Context, itemID, batchID string, quantity int) (capacity.Load, error) {
+	return capacity.ResolveLoad(ctx, s.db, itemID, batchID, quantity)
+}
+
+// ReserveSlot удерживает ячейку склада за token на ttl, если она не зарезервирована и груз в нее помещается
+func (s *SlotStore) ReserveSlot(ctx context.Context, warehouseID, slotID, token string, ttl time.Duration, load capacity.Load) (bool, error) {
+	condition, args := capacity.FitsCondition(load, 5)
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE slots s SET reservation_token = $1, reserved_until = NOW() + $2 * INTERVAL '1 second' WHERE s.slot_id = $3 AND s.warehouse_id = $4 AND (s.reserved_until IS NULL OR s.reserved_until < NOW()) AND "+condition,
+		append([]interface{}{token, ttl.Seconds(), slotID, warehouseID}, args...)...,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// CommitReservation занимает ячейку, удерживаемую token, и записывает размещение в той же транзакции
+func (s *SlotStore) CommitReservation(ctx context.Context, warehouseID, token, itemID, batchID, algorithm string, quantity int) (string, error) {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var slotID string
+	err = tx.QueryRowContext(ctx,
+		"UPDATE slots SET reservation_token = NULL, reserved_until = NULL WHERE reservation_token = $1 AND warehouse_id = $2 AND reserved_until >= NOW() RETURNING slot_id",
+		token, warehouseID,
+	).Scan(&slotID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if err := occupy(ctx, tx, slotID, itemID, batchID, algorithm, quantity); err != nil {
+		return "", err
+	}
+	return slotID, tx.Commit()
+}
+
+// PlaceInSlot занимает ячейку и записывает журнал размещения и событие ItemPlaced в outbox одной транзакцией.
+// Ячейку, зарезервированную другим запросом, размещение без резерва не занимает: резерв проверяется
+// под блокировкой строки ячейки, и занятая резервом ячейка отклоняется ErrSlotReserved
+func (s *SlotStore) PlaceInSlot(ctx context.Context, slotID, itemID, batchID, algorithm string, quantity int) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var reserved bool
+	err = tx.QueryRowContext(ctx,
+		"SELECT COALESCE(reserved_until >= NOW(), false) FROM slots WHERE slot_id = $1 FOR UPDATE", slotID,
+	).Scan(&reserved)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("%w %s", capacity.ErrInsufficientCapacity, slotID)
+	}
+	if err != nil {
+		return err
+	}
+	if reserved {
+		return fmt.Errorf("%w %s", ErrSlotReserved, slotID)
+	}
+
+	if err := occupy(ctx, tx, slotID, itemID, batchID, algorithm, quantity); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// occupy добавляет груз к заполненности ячейки и записывает размещение в рамках tx
+func occupy(ctx context.Context, tx *sql.Tx, slotID, itemID, batchID, algorithm string, quantity int) error {
+	load, err := capacity.ResolveLoad(ctx, tx, itemID, batchID, quantity)
+	if err != nil {
+		return err
+	}
+	if err := capacity.Occupy(ctx, tx, slotID, load); err != nil {
+		return err
+	}
+	return stock.RecordPlacement(ctx, tx, slotID, itemID, batchID, algorithm, load.Units)
+}
+
+// ReleaseReservation снимает резерв token; false - резерва нет
+func (s *SlotStore) ReleaseReservation(ctx context.Context, warehouseID, token string) (bool, error) {
+	result, err := s.db.ExecContext(ctx,
+		"UPDATE slots SET reservation_token = NULL, reserved_until = NULL WHERE reservation_token = $1 AND warehouse_id = $2",
+		token, warehouseID,
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// CreatePlacementRequest записывает запрос размещения в placement_requests и возвращает его номер
+func (s *SlotStore) CreatePlacementRequest(ctx context.Context, req *placement.Request) (int, error) {
+	var requestID int
+	err := s.db.QueryRowContext(ctx,
+		"INSERT INTO placement_requests (warehouse_id, item_id, batch_id, quantity) VALUES ($1, $2, $3, $4) RETURNING request_id",
+		req.Warehouse(), req.ItemID, req.BatchID, req.Quantity,
+	).Scan(&requestID)
+	return requestID, err
+}
+
+// CreatePlacementResponse записывает ответ на запрос размещения в placement_responses
+func (s *SlotStore) CreatePlacementResponse(ctx context.Context, requestID int, success bool, slotID, algorithm string, score float64, comment string) error {
+	_, err := s.db.ExecContext(ctx,
+		"INSERT INTO placement_responses (request_id, success, slot_id, algorithm_used, score, comment) VALUES ($1, $2, $3, $4, $5, $6)",
+		requestID, success, slotID, algorithm, score, comment,
+	)
+	return err
+}
@@ -0,0 +1,219 @@
+// Package reservation реализует протокол резервирования ячеек, общий для всех сервисов размещения:
+// reserve подбирает ячейку так же, как analyze (или берет указанную в slot_id, если она подходит),
+// и удерживает ее за запросом до истечения TTL, commit
+// занимает ровно удерживаемую ячейку, release возвращает ее в пул свободных. Сервисы различаются только
+// подбором ячейки и хранилищем, поэтому передают их Manager
+package reservation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	"warehouse/pkg/capacity"
+	"warehouse/pkg/placement"
+)
+
+// DefaultTTL - срок резерва, если в запросе не указан reservation_ttl_seconds
+const DefaultTTL = 30 * time.Second
+
+// maxReserveAttempts - сколько раз подобрать ячейку заново, если подобранную успел занять параллельный запрос
+const maxReserveAttempts = 3
+
+// Store - хранилище резервов сервиса размещения
+type Store interface {
+	ResolveLoad(ctx context.Context, itemID, batchID string, quantity int) (capacity.Load, error)
+	ReserveSlot(ctx context.Context, warehouseID, slotID, token string, ttl time.Duration, load capacity.Load) (bool, error)
+	// CommitReservation занимает ячейку резерва и возвращает ее; пустая строка - резерв не найден или истек
+	CommitReservation(ctx context.Context, warehouseID, token, itemID, batchID, algorithm string, quantity int) (string, error)
+	ReleaseReservation(ctx context.Context, warehouseID, token string) (bool, error)
+
+	CreatePlacementRequest(ctx context.Context, req *placement.Request) (int, error)
+	CreatePlacementResponse(ctx context.Context, requestID int, success bool, slotID, algorithm string, score float64, comment string) error
+}
+
+// Ranker возвращает до limit подходящих ячеек, начиная с лучшей (limit <= 0 - все), или единственный
+// отказ с Success = false, как метод Candidates сервиса
+type Ranker func(ctx context.Context, req *placement.Request, limit int) ([]placement.Response, error)
+
+// Manager выполняет команды reserve, commit и release одного алгоритма размещения
+type Manager struct {
+	store     Store
+	algorithm string
+	rank      Ranker
+}
+
+// NewManager создает протокол резервирования алгоритма algorithm (например, abc_placement),
+// который подбирает ячейки через rank
+func NewManager(store Store, algorithm string, rank Ranker) *Manager {
+	return &Manager{store: store, algorithm: algorithm, rank: rank}
+}
+
+// Reserve подбирает ячейку и удерживает ее за запросом на reservation_ttl_seconds (по умолчанию DefaultTTL).
+// Если подобранную ячейку успел зарезервировать параллельный запрос, подбор повторяется. Ячейка из slot_id
+// резервируется, только если алгоритм считает ее подходящей, и без повторного подбора
+func (m *Manager) Reserve(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	ttl := DefaultTTL
+	if req.ReservationTTLSeconds > 0 {
+		ttl = time.Duration(req.ReservationTTLSeconds) * time.Second
+	}
+
+	load, err := m.store.ResolveLoad(ctx, req.ItemID, req.BatchID, req.Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расчета груза: %w", err)
+	}
+
+	for attempt := 0; attempt < maxReserveAttempts; attempt++ {
+		analysis, err := m.candidate(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		if !analysis.Success {
+			return analysis, nil
+		}
+
+		token, err := NewToken()
+		if err != nil {
+			return nil, fmt.Errorf("ошибка генерации токена резерва: %w", err)
+		}
+
+		reserved, err := m.store.ReserveSlot(ctx, req.Warehouse(), analysis.SlotID, token, ttl, load)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка резервирования ячейки: %w", err)
+		}
+		if !reserved {
+			if req.SlotID != "" {
+				return &placement.Response{
+					Success: false,
+					Comment: fmt.Sprintf("Ячейка %s уже зарезервирована параллельным запросом", req.SlotID),
+					Score:   0,
+				}, nil
+			}
+			continue
+		}
+
+		reservedUntil := time.Now().Add(ttl)
+		analysis.ReservationToken = token
+		analysis.ReservedUntil = &reservedUntil
+		analysis.Comment = fmt.Sprintf("Ячейка %s зарезервирована до %s", analysis.SlotID, reservedUntil.Format(time.RFC3339))
+		return analysis, nil
+	}
+
+	return &placement.Response{
+		Success: false,
+		Comment: "Подходящие ячейки были зарезервированы параллельными запросами, повторите попытку",
+		Score:   0,
+	}, nil
+}
+
+// candidate подбирает лучшую ячейку для запроса или, если указан slot_id, находит ее среди подходящих
+func (m *Manager) candidate(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	limit := 1
+	if req.SlotID != "" {
+		limit = 0
+	}
+	candidates, err := m.rank(ctx, req, limit)
+	if err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 {
+		return &placement.Response{Success: false, Comment: "Подходящая ячейка не найдена", Score: 0}, nil
+	}
+	if req.SlotID == "" || !candidates[0].Success {
+		return &candidates[0], nil
+	}
+
+	for i := range candidates {
+		if candidates[i].Success && candidates[i].SlotID == req.SlotID {
+			return &candidates[i], nil
+		}
+	}
+	return &placement.Response{
+		Success: false,
+		Comment: fmt.Sprintf("Ячейка %s не подходит для запроса", req.SlotID),
+		Score:   0,
+	}, nil
+}
+
+// Commit занимает ровно ту ячейку, которая удерживается токеном резерва
+func (m *Manager) Commit(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	if req.ReservationToken == "" {
+		return &placement.Response{
+			Success: false,
+			Comment: "Не указан токен резерва",
+			Score:   0,
+		}, nil
+	}
+
+	requestID, err := m.store.CreatePlacementRequest(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сохранения запроса на размещение: %w", err)
+	}
+
+	slotID, err := m.store.CommitReservation(ctx, req.Warehouse(), req.ReservationToken, req.ItemID, req.BatchID, m.algorithm, req.Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка подтверждения резерва: %w", err)
+	}
+	if slotID == "" {
+		return &placement.Response{
+			Success: false,
+			Comment: "Резерв не найден или истек",
+			Score:   0,
+		}, nil
+	}
+
+	comment := fmt.Sprintf("Товар размещен в зарезервированной ячейке %s", slotID)
+	if err := m.store.CreatePlacementResponse(ctx, requestID, true, slotID, m.algorithm, 1.0, comment); err != nil {
+		// Товар уже размещен, поэтому ошибка журнала не отменяет ответ
+		log.Printf("Ошибка сохранения ответа на размещение: %v", err)
+	}
+
+	return &placement.Response{
+		Success:   true,
+		SlotID:    slotID,
+		Comment:   comment,
+		Score:     1.0,
+		BaseScore: 1.0,
+	}, nil
+}
+
+// Release снимает резерв и возвращает ячейку в пул свободных
+func (m *Manager) Release(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	if req.ReservationToken == "" {
+		return &placement.Response{
+			Success: false,
+			Comment: "Не указан токен резерва",
+			Score:   0,
+		}, nil
+	}
+
+	released, err := m.store.ReleaseReservation(ctx, req.Warehouse(), req.ReservationToken)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка снятия резерва: %w", err)
+	}
+	if !released {
+		return &placement.Response{
+			Success: false,
+			Comment: "Резерв не найден",
+			Score:   0,
+		}, nil
+	}
+
+	return &placement.Response{
+		Success: true,
+		Comment: "Резерв снят",
+		Score:   0,
+	}, nil
+}
+
+// NewToken генерирует случайный токен резерва
+func NewToken() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
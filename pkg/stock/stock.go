@@ -0,0 +1,97 @@
+// Package stock ведет учет остатков: таблица slot_stock хранит количество каждой партии товара
+// в каждой ячейке. Остатки меняются в той же транзакции, что и размещение, отбор или перемещение,
+// поэтому всегда соответствуют журналу placement_logs и заполненности ячеек
+package stock
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"warehouse/pkg/outbox"
+)
+
+// ErrInsufficientStock означает, что в ячейке меньше товара, чем требуется списать
+var ErrInsufficientStock = errors.New("недостаточно товара в ячейке")
+
+// Receive добавляет quantity единиц партии batchID товара itemID к остатку ячейки в рамках tx.
+// Пустой batchID - товар без партии; quantity <= 0 остаток не меняет
+func Receive(ctx context.Context, tx *sql.Tx, slotID, itemID, batchID string, quantity int) error {
+	if quantity <= 0 {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `
+		INSERT INTO slot_stock (slot_id, item_id, batch_id, quantity)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (slot_id, item_id, batch_id)
+		DO UPDATE SET quantity = slot_stock.quantity + EXCLUDED.quantity, updated_at = NOW()`,
+		slotID, itemID, batchID, quantity,
+	)
+	if err != nil {
+		return fmt.Errorf("ошибка учета остатка: %w", err)
+	}
+	return nil
+}
+
+// Issue списывает quantity единиц партии товара из ячейки в рамках tx и возвращает остаток партии
+// в ячейке. Строка с нулевым остатком удаляется; если товара не хватает, возвращается ErrInsufficientStock
+func Issue(ctx context.Context, tx *sql.Tx, slotID, itemID, batchID string, quantity int) (int, error) {
+	var left int
+	err := tx.QueryRowContext(ctx, `
+		UPDATE slot_stock SET quantity = quantity - $4, updated_at = NOW()
+		WHERE slot_id = $1 AND item_id = $2 AND batch_id = $3 AND quantity >= $4
+		RETURNING quantity`,
+		slotID, itemID, batchID, quantity,
+	).Scan(&left)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("%w %s: %s/%s", ErrInsufficientStock, slotID, itemID, batchID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ошибка списания остатка: %w", err)
+	}
+
+	if left == 0 {
+		if _, err := tx.ExecContext(ctx,
+			"DELETE FROM slot_stock WHERE slot_id = $1 AND item_id = $2 AND batch_id = $3 AND quantity = 0",
+			slotID, itemID, batchID,
+		); err != nil {
+			return 0, fmt.Errorf("ошибка списания остатка: %w", err)
+		}
+	}
+	return left, nil
+}
+
+// Move переносит quantity единиц партии товара из ячейки fromSlotID в toSlotID в рамках tx
+// и возвращает остаток партии в исходной ячейке
+func Move(ctx context.Context, tx *sql.Tx, fromSlotID, toSlotID, itemID, batchID string, quantity int) (int, error) {
+	left, err := Issue(ctx, tx, fromSlotID, itemID, batchID, quantity)
+	if err != nil {
+		return 0, err
+	}
+	return left, Receive(ctx, tx, toSlotID, itemID, batchID, quantity)
+}
+
+// RecordPlacement записывает размещение quantity единиц в ячейку алгоритмом algorithm в рамках tx:
+// строку placed журнала placement_logs, приход в slot_stock и событие ItemPlaced в outbox
+func RecordPlacement(ctx context.Context, tx *sql.Tx, slotID, itemID, batchID, algorithm string, quantity int) error {
+	_, err := tx.ExecContext(ctx,
+		"INSERT INTO placement_logs (slot_id, item_id, batch_id, algorithm, movement, quantity) VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, 'placed', NULLIF($5, 0))",
+		slotID, itemID, batchID, algorithm, quantity,
+	)
+	if err != nil {
+		return err
+	}
+	if err := Receive(ctx, tx, slotID, itemID, batchID, quantity); err != nil {
+		return err
+	}
+
+	return outbox.Insert(ctx, tx, outbox.Event{
+		Type:     outbox.EventItemPlaced,
+		SlotID:   slotID,
+		ItemID:   itemID,
+		BatchID:  batchID,
+		Quantity: quantity,
+		Source:   algorithm,
+	})
+}
@@ -0,0 +1,61 @@
+package topology
+
+import "sort"
+
+// SlotDistance - расстояния ячейки-кандидата до выхода. Сервисы размещения встраивают его в свою
+// модель ячейки, чтобы упорядочивать кандидатов через OrderSlots
+type SlotDistance struct {
+	SlotID           string `json:"slot_id"`
+	DistanceFromExit int    `json:"distance_from_exit"`
+	// TravelDistance - путь от ворот по топологии склада; nil - топология не задана или ячейка недостижима
+	TravelDistance *float64 `json:"travel_distance,omitempty"`
+}
+
+// Distance возвращает расстояния ячейки; через него OrderSlots работает с моделями ячеек сервисов
+func (d *SlotDistance) Distance() *SlotDistance {
+	return d
+}
+
+// ExitDistance - расстояние, которое сервис передает оркестратору как distance_to_exit:
+// путь по топологии, если он известен, иначе distance_from_exit
+func (d SlotDistance) ExitDistance() *float64 {
+	if d.TravelDistance != nil {
+		distance := *d.TravelDistance
+		return &distance
+	}
+	distance := float64(d.DistanceFromExit)
+	return &distance
+}
+
+// Slot - модель ячейки сервиса, встраивающая SlotDistance
+type Slot[S any] interface {
+	*S
+	Distance() *SlotDistance
+}
+
+// SetTravelDistances заполняет TravelDistance ячеек, до которых есть путь в distances (результат FromDock)
+func SetTravelDistances[S any, P Slot[S]](slots []S, distances map[string]float64) {
+	for i := range slots {
+		slot := P(&slots[i]).Distance()
+		if distance, ok := distances[slot.SlotID]; ok {
+			slot.TravelDistance = &distance
+		}
+	}
+}
+
+// OrderSlots заполняет TravelDistance и сортирует ячейки по пути от ворот, если для склада задана топология,
+// и по distance_from_exit иначе. Ячейки, недостижимые по топологии, идут в конце. При равных расстояниях
+// сохраняется исходный порядок
+func OrderSlots[S any, P Slot[S]](slots []S, distances map[string]float64) {
+	SetTravelDistances[S, P](slots, distances)
+	sort.SliceStable(slots, func(i, j int) bool {
+		a, b := P(&slots[i]).Distance(), P(&slots[j]).Distance()
+		if (a.TravelDistance == nil) != (b.TravelDistance == nil) {
+			return a.TravelDistance != nil
+		}
+		if a.TravelDistance != nil && *a.TravelDistance != *b.TravelDistance {
+			return *a.TravelDistance < *b.TravelDistance
+		}
+		return a.DistanceFromExit < b.DistanceFromExit
+	})
+}
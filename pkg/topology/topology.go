@@ -0,0 +1,285 @@
+// Package topology описывает топологию склада: узлы (ворота, перекрестки проходов и точки доступа
+// к ячейкам) и взвешенные ребра между ними из таблиц topology_nodes и topology_edges. Кратчайшие
+// расстояния от каждых ворот до каждой ячейки рассчитываются алгоритмом Дейкстры и кэшируются. Сервисы
+// размещения используют их вместо slots.distance_from_exit, если для склада задана топология
+package topology
+
+import (
+	"container/heap"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Типы узлов топологии
+const (
+	// NodeDock - ворота, от которых считается путь
+	NodeDock = "dock"
+	// NodeIntersection - перекресток проходов
+	NodeIntersection = "intersection"
+	// NodeSlotAccess - точка доступа к ячейке slot_id
+	NodeSlotAccess = "slot_access"
+)
+
+// DefaultCacheTTL - сколько рассчитанные расстояния склада хранятся в кэше. Стеллажи
+// переставляют редко, поэтому изменения топологии применяются с такой задержкой
+const DefaultCacheTTL = 5 * time.Minute
+
+// ErrUnknownDock означает, что в топологии склада нет указанных ворот
+var ErrUnknownDock = errors.New("ворота не найдены в топологии склада")
+
+// Node - узел топологии; SlotID заполнен только у точки доступа к ячейке
+type Node struct {
+	NodeID   string
+	NodeType string
+	SlotID   string
+}
+
+// Edge - проход между узлами длиной Distance. Проход с OneWay можно пройти только от From к To
+type Edge struct {
+	From     string
+	To       string
+	Distance float64
+	OneWay   bool
+}
+
+type arc struct {
+	to       string
+	distance float64
+}
+
+// Graph - ориентированный граф склада
+type Graph struct {
+	arcs  map[string][]arc
+	docks []string
+	// slots - ячейка точки доступа; к ячейке может вести несколько точек доступа
+	slots map[string]string
+}
+
+// NewGraph строит граф из узлов и ребер; ребра к неизвестным узлам пропускаются
+func NewGraph(nodes []Node, edges []Edge) *Graph {
+	g := &Graph{arcs: map[string][]arc{}, slots: map[string]string{}}
+	for _, node := range nodes {
+		g.arcs[node.NodeID] = nil
+		switch node.NodeType {
+		case NodeDock:
+			g.docks = append(g.docks, node.NodeID)
+		case NodeSlotAccess:
+			g.slots[node.NodeID] = node.SlotID
+		}
+	}
+	sort.Strings(g.docks)
+
+	for _, edge := range edges {
+		_, fromKnown := g.arcs[edge.From]
+		_, toKnown := g.arcs[edge.To]
+		if !fromKnown || !toKnown {
+			continue
+		}
+		g.arcs[edge.From] = append(g.arcs[edge.From], arc{to: edge.To, distance: edge.Distance})
+		if !edge.OneWay {
+			g.arcs[edge.To] = append(g.arcs[edge.To], arc{to: edge.From, distance: edge.Distance})
+		}
+	}
+	return g
+}
+
+// ShortestPaths возвращает кратчайшие расстояния от узла from до всех достижимых из него узлов
+func (g *Graph) ShortestPaths(from string) map[string]float64 {
+	dist := map[string]float64{}
+	if _, ok := g.arcs[from]; !ok {
+		return dist
+	}
+
+	dist[from] = 0
+	queue := &nodeQueue{{node: from}}
+	for queue.Len() > 0 {
+		current := heap.Pop(queue).(queuedNode)
+		if current.distance > dist[current.node] {
+			continue
+		}
+		for _, next := range g.arcs[current.node] {
+			distance := current.distance + next.distance
+			if known, ok := dist[next.to]; !ok || distance < known {
+				dist[next.to] = distance
+				heap.Push(queue, queuedNode{node: next.to, distance: distance})
+			}
+		}
+	}
+	return dist
+}
+
+// Distances рассчитывает кратчайшие расстояния от всех ворот до всех ячеек
+func (g *Graph) Distances() *Distances {
+	d := &Distances{docks: g.docks, bySlot: map[string]map[string]float64{}}
+	for _, dock := range g.docks {
+		slots := map[string]float64{}
+		for node, distance := range g.ShortestPaths(dock) {
+			slotID, ok := g.slots[node]
+			if !ok {
+				continue
+			}
+			if known, ok := slots[slotID]; !ok || distance < known {
+				slots[slotID] = distance
+			}
+		}
+		d.bySlot[dock] = slots
+	}
+	return d
+}
+
+// Distances - кратчайшие расстояния от ворот склада до ячеек
+type Distances struct {
+	docks  []string
+	bySlot map[string]map[string]float64
+}
+
+// Docks возвращает ворота склада в порядке идентификаторов
+func (d *Distances) Docks() []string {
+	return d.docks
+}
+
+// Distance возвращает расстояние от ворот dockID до ячейки; false - ворот нет или ячейка от них недостижима
+func (d *Distances) Distance(dockID, slotID string) (float64, bool) {
+	distance, ok := d.bySlot[dockID][slotID]
+	return distance, ok
+}
+
+// FromDock возвращает расстояния от ворот dockID до достижимых ячеек. Пустой dockID - расстояние
+// до каждой ячейки от ближайших к ней ворот; неизвестные ворота - ErrUnknownDock
+func (d *Distances) FromDock(dockID string) (map[string]float64, error) {
+	if dockID != "" {
+		slots, ok := d.bySlot[dockID]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownDock, dockID)
+		}
+		return slots, nil
+	}
+
+	nearest := map[string]float64{}
+	for _, slots := range d.bySlot {
+		for slotID, distance := range slots {
+			if known, ok := nearest[slotID]; !ok || distance < known {
+				nearest[slotID] = distance
+			}
+		}
+	}
+	return nearest, nil
+}
+
+// Load читает топологию склада; ребра между узлами разных складов не учитываются
+func Load(ctx context.Context, db *sql.DB, warehouseID string) (*Graph, error) {
+	rows, err := db.QueryContext(ctx,
+		"SELECT node_id, node_type, COALESCE(slot_id, '') FROM topology_nodes WHERE warehouse_id = $1",
+		warehouseID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения узлов топологии: %w", err)
+	}
+	var nodes []Node
+	for rows.Next() {
+		var node Node
+		if err := rows.Scan(&node.NodeID, &node.NodeType, &node.SlotID); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("ошибка чтения узлов топологии: %w", err)
+		}
+		nodes = append(nodes, node)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения узлов топологии: %w", err)
+	}
+
+	rows, err = db.QueryContext(ctx, `
+		SELECT e.from_node_id, e.to_node_id, e.distance, e.one_way
+		FROM topology_edges e
+		JOIN topology_nodes f ON f.node_id = e.from_node_id
+		JOIN topology_nodes t ON t.node_id = e.to_node_id
+		WHERE f.warehouse_id = $1 AND t.warehouse_id = $1`,
+		warehouseID,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения ребер топологии: %w", err)
+	}
+	defer rows.Close()
+	var edges []Edge
+	for rows.Next() {
+		var edge Edge
+		if err := rows.Scan(&edge.From, &edge.To, &edge.Distance, &edge.OneWay); err != nil {
+			return nil, fmt.Errorf("ошибка чтения ребер топологии: %w", err)
+		}
+		edges = append(edges, edge)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения ребер топологии: %w", err)
+	}
+	return NewGraph(nodes, edges), nil
+}
+
+// Cache хранит рассчитанные расстояния складов в течение ttl. Безопасен для параллельного использования
+type Cache struct {
+	db  *sql.DB
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	distances *Distances
+	loadedAt  time.Time
+}
+
+// NewCache создает кэш расстояний складов базы db
+func NewCache(db *sql.DB, ttl time.Duration) *Cache {
+	return &Cache{db: db, ttl: ttl, entries: map[string]cacheEntry{}}
+}
+
+// Distances возвращает расстояния склада; nil - топология склада не задана (в ней нет ворот)
+func (c *Cache) Distances(ctx context.Context, warehouseID string) (*Distances, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[warehouseID]
+	c.mu.Unlock()
+	if ok && time.Since(entry.loadedAt) < c.ttl {
+		return entry.distances, nil
+	}
+
+	// Граф строится без блокировки: параллельные пересчеты дадут одинаковый результат
+	graph, err := Load(ctx, c.db, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+	entry = cacheEntry{loadedAt: time.Now()}
+	if len(graph.docks) > 0 {
+		entry.distances = graph.Distances()
+	}
+
+	c.mu.Lock()
+	c.entries[warehouseID] = entry
+	c.mu.Unlock()
+	return entry.distances, nil
+}
+
+type queuedNode struct {
+	node     string
+	distance float64
+}
+
+// nodeQueue - очередь узлов с приоритетом по расстоянию для алгоритма Дейкстры
+type nodeQueue []queuedNode
+
+func (q nodeQueue) Len() int            { return len(q) }
+func (q nodeQueue) Less(i, j int) bool  { return q[i].distance < q[j].distance }
+func (q nodeQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *nodeQueue) Push(x interface{}) { *q = append(*q, x.(queuedNode)) }
+
+func (q *nodeQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
@@ -0,0 +1,209 @@
+package topology
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// testGraph - склад с двумя воротами:
+//
+//	D1 --2-- X --3-- S1a (SLOT1)
+//	          \--1-> S2a (SLOT2), только от X
+//	D2 --1-- S1b (SLOT1)
+//	S3a (SLOT3) ни с чем не соединена
+func testGraph() *Graph {
+	nodes := []Node{
+		{NodeID: "D1", NodeType: NodeDock},
+		{NodeID: "D2", NodeType: NodeDock},
+		{NodeID: "X", NodeType: NodeIntersection},
+		{NodeID: "S1a", NodeType: NodeSlotAccess, SlotID: "SLOT1"},
+		{NodeID: "S1b", NodeType: NodeSlotAccess, SlotID: "SLOT1"},
+		{NodeID: "S2a", NodeType: NodeSlotAccess, SlotID: "SLOT2"},
+		{NodeID: "S3a", NodeType: NodeSlotAccess, SlotID: "SLOT3"},
+	}
+	edges := []Edge{
+		{From: "D1", To: "X", Distance: 2},
+		{From: "X", To: "S1a", Distance: 3},
+		{From: "X", To: "S2a", Distance: 1, OneWay: true},
+		{From: "D2", To: "S1b", Distance: 1},
+		{From: "X", To: "UNKNOWN", Distance: 1},
+	}
+	return NewGraph(nodes, edges)
+}
+
+func TestShortestPaths(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		want map[string]float64
+	}{
+		{
+			name: "от ворот через перекресток",
+			from: "D1",
+			want: map[string]float64{"D1": 0, "X": 2, "S1a": 5, "S2a": 3},
+		},
+		{
+			name: "односторонний проход в обратную сторону не проходится",
+			from: "S2a",
+			want: map[string]float64{"S2a": 0},
+		},
+		{
+			name: "двусторонний проход проходится в обе стороны",
+			from: "S1a",
+			want: map[string]float64{"S1a": 0, "X": 3, "D1": 5, "S2a": 4},
+		},
+		{
+			name: "изолированный узел",
+			from: "S3a",
+			want: map[string]float64{"S3a": 0},
+		},
+		{
+			name: "неизвестный узел",
+			from: "NOPE",
+			want: map[string]float64{},
+		},
+	}
+
+	graph := testGraph()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := graph.ShortestPaths(tt.from); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ShortestPaths(%s) = %v, ожидалось %v", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShortestPathsPrefersShorterDetour(t *testing.T) {
+	graph := NewGraph(
+		[]Node{{NodeID: "A"}, {NodeID: "B"}, {NodeID: "C"}},
+		[]Edge{
+			{From: "A", To: "C", Distance: 10},
+			{From: "A", To: "B", Distance: 2},
+			{From: "B", To: "C", Distance: 3},
+		},
+	)
+	if got := graph.ShortestPaths("A")["C"]; got != 5 {
+		t.Errorf("расстояние до C = %v, ожидалось 5 через B", got)
+	}
+}
+
+func TestDistancesFromDock(t *testing.T) {
+	tests := []struct {
+		name    string
+		dock    string
+		want    map[string]float64
+		wantErr error
+	}{
+		{
+			name: "первые ворота",
+			dock: "D1",
+			want: map[string]float64{"SLOT1": 5, "SLOT2": 3},
+		},
+		{
+			name: "вторые ворота",
+			dock: "D2",
+			want: map[string]float64{"SLOT1": 1},
+		},
+		{
+			name: "без ворот - от ближайших к ячейке",
+			want: map[string]float64{"SLOT1": 1, "SLOT2": 3},
+		},
+		{
+			name:    "неизвестные ворота",
+			dock:    "D9",
+			wantErr: ErrUnknownDock,
+		},
+	}
+
+	distances := testGraph().Distances()
+	if docks := distances.Docks(); !reflect.DeepEqual(docks, []string{"D1", "D2"}) {
+		t.Fatalf("ворота %v, ожидались [D1 D2]", docks)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := distances.FromDock(tt.dock)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ошибка %v, ожидалась %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FromDock(%q) = %v, ожидалось %v", tt.dock, got, tt.want)
+			}
+		})
+	}
+}
+
+// testSlot - модель ячейки сервиса размещения
+type testSlot struct {
+	SlotDistance
+	ZoneType string
+}
+
+func slot(slotID string, distanceFromExit int) testSlot {
+	return testSlot{SlotDistance: SlotDistance{SlotID: slotID, DistanceFromExit: distanceFromExit}}
+}
+
+func TestOrderSlots(t *testing.T) {
+	tests := []struct {
+		name      string
+		slots     []testSlot
+		distances map[string]float64
+		want      []string
+		// exit - ожидаемый distance_to_exit каждой ячейки в порядке want
+		exit []float64
+	}{
+		{
+			name:  "без топологии - по distance_from_exit",
+			slots: []testSlot{slot("A", 7), slot("B", 2), slot("C", 5)},
+			want:  []string{"B", "C", "A"},
+			exit:  []float64{2, 5, 7},
+		},
+		{
+			name:      "по пути от ворот, а не по distance_from_exit",
+			slots:     []testSlot{slot("A", 1), slot("B", 9)},
+			distances: map[string]float64{"A": 12.5, "B": 3},
+			want:      []string{"B", "A"},
+			exit:      []float64{3, 12.5},
+		},
+		{
+			name:      "недостижимые ячейки в конце",
+			slots:     []testSlot{slot("A", 1), slot("B", 9), slot("C", 4)},
+			distances: map[string]float64{"B": 20},
+			want:      []string{"B", "A", "C"},
+			exit:      []float64{20, 1, 4},
+		},
+		{
+			name:      "равные пути - по distance_from_exit",
+			slots:     []testSlot{slot("A", 6), slot("B", 2)},
+			distances: map[string]float64{"A": 4, "B": 4},
+			want:      []string{"B", "A"},
+			exit:      []float64{4, 4},
+		},
+		{
+			name:  "равные расстояния сохраняют исходный порядок",
+			slots: []testSlot{slot("A", 3), slot("B", 3), slot("C", 3)},
+			want:  []string{"A", "B", "C"},
+			exit:  []float64{3, 3, 3},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			OrderSlots(tt.slots, tt.distances)
+
+			got := make([]string, 0, len(tt.slots))
+			exit := make([]float64, 0, len(tt.slots))
+			for _, s := range tt.slots {
+				got = append(got, s.SlotID)
+				exit = append(exit, *s.ExitDistance())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("порядок %v, ожидался %v", got, tt.want)
+			}
+			if !reflect.DeepEqual(exit, tt.exit) {
+				t.Errorf("distance_to_exit %v, ожидалось %v", exit, tt.exit)
+			}
+		})
+	}
+}
@@ -0,0 +1,322 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.23.4
+// source: proto/placement/v1/placement.proto
+
+package placementv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	PlacementService_Analyze_FullMethodName          = "/placement.v1.PlacementService/Analyze"
+	PlacementService_Place_FullMethodName            = "/placement.v1.PlacementService/Place"
+	PlacementService_Reserve_FullMethodName          = "/placement.v1.PlacementService/Reserve"
+	PlacementService_Commit_FullMethodName           = "/placement.v1.PlacementService/Commit"
+	PlacementService_Release_FullMethodName          = "/placement.v1.PlacementService/Release"
+	PlacementService_StreamCandidates_FullMethodName = "/placement.v1.PlacementService/StreamCandidates"
+)
+
+// PlacementServiceClient is the client API for PlacementService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type PlacementServiceClient interface {
+	Analyze(ctx context.Context, in *PlacementRequest, opts ...grpc.CallOption) (*PlacementResponse, error)
+	Place(ctx context.Context, in *PlacementRequest, opts ...grpc.CallOption) (*PlacementResponse, error)
+	Reserve(ctx context.Context, in *PlacementRequest, opts ...grpc.CallOption) (*PlacementResponse, error)
+	Commit(ctx context.Context, in *PlacementRequest, opts ...grpc.CallOption) (*PlacementResponse, error)
+	Release(ctx context.Context, in *PlacementRequest, opts ...grpc.CallOption) (*PlacementResponse, error)
+	StreamCandidates(ctx context.Context, in *CandidatesRequest, opts ...grpc.CallOption) (PlacementService_StreamCandidatesClient, error)
+}
+
+type placementServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewPlacementServiceClient(cc grpc.ClientConnInterface) PlacementServiceClient {
+	return &placementServiceClient{cc}
+}
+
+func (c *placementServiceClient) Analyze(ctx context.Context, in *PlacementRequest, opts ...grpc.CallOption) (*PlacementResponse, error) {
+	out := new(PlacementResponse)
+	err := c.cc.Invoke(ctx, PlacementService_Analyze_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *placementServiceClient) Place(ctx context.Context, in *PlacementRequest, opts ...grpc.CallOption) (*PlacementResponse, error) {
+	out := new(PlacementResponse)
+	err := c.cc.Invoke(ctx, PlacementService_Place_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *placementServiceClient) Reserve(ctx context.Context, in *PlacementRequest, opts ...grpc.CallOption) (*PlacementResponse, error) {
+	out := new(PlacementResponse)
+	err := c.cc.Invoke(ctx, PlacementService_Reserve_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *placementServiceClient) Commit(ctx context.Context, in *PlacementRequest, opts ...grpc.CallOption) (*PlacementResponse, error) {
+	out := new(PlacementResponse)
+	err := c.cc.Invoke(ctx, PlacementService_Commit_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *placementServiceClient) Release(ctx context.Context, in *PlacementRequest, opts ...grpc.CallOption) (*PlacementResponse, error) {
+	out := new(PlacementResponse)
+	err := c.cc.Invoke(ctx, PlacementService_Release_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *placementServiceClient) StreamCandidates(ctx context.Context, in *CandidatesRequest, opts ...grpc.CallOption) (PlacementService_StreamCandidatesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &PlacementService_ServiceDesc.Streams[0], PlacementService_StreamCandidates_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &placementServiceStreamCandidatesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type PlacementService_StreamCandidatesClient interface {
+	Recv() (*PlacementResponse, error)
+	grpc.ClientStream
+}
+
+type placementServiceStreamCandidatesClient struct {
+	grpc.ClientStream
+}
+
+func (x *placementServiceStreamCandidatesClient) Recv() (*PlacementResponse, error) {
+	m := new(PlacementResponse)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// PlacementServiceServer is the server API for PlacementService service.
+// All implementations must embed UnimplementedPlacementServiceServer
+// for forward compatibility
+type PlacementServiceServer interface {
+	Analyze(context.Context, *PlacementRequest) (*PlacementResponse, error)
+	Place(context.Context, *PlacementRequest) (*PlacementResponse, error)
+	Reserve(context.Context, *PlacementRequest) (*PlacementResponse, error)
+	Commit(context.Context, *PlacementRequest) (*PlacementResponse, error)
+	Release(context.Context, *PlacementRequest) (*PlacementResponse, error)
+	StreamCandidates(*CandidatesRequest, PlacementService_StreamCandidatesServer) error
+	mustEmbedUnimplementedPlacementServiceServer()
+}
+
+// UnimplementedPlacementServiceServer must be embedded to have forward compatible implementations.
+type UnimplementedPlacementServiceServer struct {
+}
+
+func (UnimplementedPlacementServiceServer) Analyze(context.Context, *PlacementRequest) (*PlacementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Analyze not implemented")
+}
+func (UnimplementedPlacementServiceServer) Place(context.Context, *PlacementRequest) (*PlacementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Place not implemented")
+}
+func (UnimplementedPlacementServiceServer) Reserve(context.Context, *PlacementRequest) (*PlacementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Reserve not implemented")
+}
+func (UnimplementedPlacementServiceServer) Commit(context.Context, *PlacementRequest) (*PlacementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Commit not implemented")
+}
+func (UnimplementedPlacementServiceServer) Release(context.Context, *PlacementRequest) (*PlacementResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Release not implemented")
+}
+func (UnimplementedPlacementServiceServer) StreamCandidates(*CandidatesRequest, PlacementService_StreamCandidatesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamCandidates not implemented")
+}
+func (UnimplementedPlacementServiceServer) mustEmbedUnimplementedPlacementServiceServer() {}
+
+// UnsafePlacementServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to PlacementServiceServer will
+// result in compilation errors.
+type UnsafePlacementServiceServer interface {
+	mustEmbedUnimplementedPlacementServiceServer()
+}
+
+func RegisterPlacementServiceServer(s grpc.ServiceRegistrar, srv PlacementServiceServer) {
+	s.RegisterService(&PlacementService_ServiceDesc, srv)
+}
+
+func _PlacementService_Analyze_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlacementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlacementServiceServer).Analyze(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlacementService_Analyze_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlacementServiceServer).Analyze(ctx, req.(*PlacementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlacementService_Place_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlacementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlacementServiceServer).Place(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlacementService_Place_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlacementServiceServer).Place(ctx, req.(*PlacementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlacementService_Reserve_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlacementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlacementServiceServer).Reserve(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlacementService_Reserve_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlacementServiceServer).Reserve(ctx, req.(*PlacementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlacementService_Commit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlacementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlacementServiceServer).Commit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlacementService_Commit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlacementServiceServer).Commit(ctx, req.(*PlacementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlacementService_Release_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PlacementRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PlacementServiceServer).Release(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: PlacementService_Release_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PlacementServiceServer).Release(ctx, req.(*PlacementRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _PlacementService_StreamCandidates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CandidatesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(PlacementServiceServer).StreamCandidates(m, &placementServiceStreamCandidatesServer{stream})
+}
+
+type PlacementService_StreamCandidatesServer interface {
+	Send(*PlacementResponse) error
+	grpc.ServerStream
+}
+
+type placementServiceStreamCandidatesServer struct {
+	grpc.ServerStream
+}
+
+func (x *placementServiceStreamCandidatesServer) Send(m *PlacementResponse) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// PlacementService_ServiceDesc is the grpc.ServiceDesc for PlacementService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var PlacementService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "placement.v1.PlacementService",
+	HandlerType: (*PlacementServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Analyze",
+			Handler:    _PlacementService_Analyze_Handler,
+		},
+		{
+			MethodName: "Place",
+			Handler:    _PlacementService_Place_Handler,
+		},
+		{
+			MethodName: "Reserve",
+			Handler:    _PlacementService_Reserve_Handler,
+		},
+		{
+			MethodName: "Commit",
+			Handler:    _PlacementService_Commit_Handler,
+		},
+		{
+			MethodName: "Release",
+			Handler:    _PlacementService_Release_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamCandidates",
+			Handler:       _PlacementService_StreamCandidates_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "proto/placement/v1/placement.proto",
+}
@@ -4,15 +4,26 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 
+	"warehouse/pkg/capacity"
+	"warehouse/pkg/idempotency"
+	"warehouse/pkg/reservation"
+	"warehouse/pkg/topology"
 	"warehouse/services/abc-placement/internal/domain"
 )
 type PostgresRepository struct {
+	// SlotStore and KeyStore provide reservation, placement and idempotency keys shared by all services
+	*reservation.SlotStore
+	*idempotency.KeyStore
+
 	db *sql.DB
+	// distances caches shortest paths over the warehouse topology
+	distances *topology.Cache
 }
 
 func NewPostgresRepository(db *sql.DB) *PostgresRepository {
-	return &PostgresRepository{db: db}
+	return &PostgresRepository{SlotStore: reservation.NewSlotStore(db), KeyStore: idempotency.NewKeyStore(db), db: db, distances: topology.NewCache(db, topology.DefaultCacheTTL)}
 }
 
 
@@ -29,6 +40,19 @@ func (r *PostgresRepository) BatchExists(ctx context.Context, batchID string) (b
 	return exists, err
 }
 
+// GetBatchExpiry returns the expiry date of the batch, or nil if it has none
+func (r *PostgresRepository) GetBatchExpiry(ctx context.Context, batchID string) (*time.Time, error) {
+	var expiresAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, "SELECT expires_at FROM batches WHERE batch_id = $1", batchID).Scan(&expiresAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil || !expiresAt.Valid {
+		return nil, err
+	}
+	return &expiresAt.Time, nil
+}
+
 
 func (r *PostgresRepository) GetItemDetails(ctx context.Context, itemID string) (*domain.Item, error) {
 	var item domain.Item
@@ -40,8 +64,13 @@ func (r *PostgresRepository) GetItemDetails(ctx context.Context, itemID string)
 }
 
 
-func (r *PostgresRepository) GetAvailableSlots(ctx context.Context, zoneType string) ([]domain.Slot, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT slot_id, is_occupied, zone_type, distance_from_exit FROM slots WHERE is_occupied = false AND zone_type = $1 ORDER BY distance_from_exit ASC", zoneType)
+// GetAvailableSlots returns unreserved slots of the warehouse zone that can take the load, with their remaining capacity
+func (r *PostgresRepository) GetAvailableSlots(ctx context.Context, warehouseID, zoneType string, load capacity.Load) ([]domain.Slot, error) {
+	condition, args := capacity.FitsCondition(load, 3)
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT s.slot_id, s.is_occupied, s.zone_type, s.distance_from_exit, "+capacity.RemainingColumns+" FROM slots s WHERE (s.reserved_until IS NULL OR s.reserved_until < NOW()) AND s.warehouse_id = $1 AND s.zone_type = $2 AND "+condition+" ORDER BY s.distance_from_exit ASC",
+		append([]interface{}{warehouseID, zoneType}, args...)...,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get available slots: %w", err)
 	}
@@ -50,7 +79,7 @@ func (r *PostgresRepository) GetAvailableSlots(ctx context.Context, zoneType str
 	var slots []domain.Slot
 	for rows.Next() {
 		var slot domain.Slot
-		if err := rows.Scan(&slot.SlotID, &slot.IsOccupied, &slot.ZoneType, &slot.DistanceFromExit); err != nil {
+		if err := rows.Scan(&slot.SlotID, &slot.IsOccupied, &slot.ZoneType, &slot.DistanceFromExit, &slot.Remaining.Weight, &slot.Remaining.Volume, &slot.Remaining.Units); err != nil {
 			return nil, fmt.Errorf("failed to scan slot row: %w", err)
 		}
 		slots = append(slots, slot)
@@ -64,24 +93,20 @@ func (r *PostgresRepository) GetAvailableSlots(ctx context.Context, zoneType str
 }
 
 
-func (r *PostgresRepository) IsSlotOccupied(ctx context.Context, slotID string) (bool, error) {
+// IsSlotOccupied reports whether the warehouse slot is reserved or cannot take the load
+func (r *PostgresRepository) IsSlotOccupied(ctx context.Context, warehouseID, slotID string, load capacity.Load) (bool, error) {
+	condition, args := capacity.FitsCondition(load, 3)
 	var isOccupied bool
-	err := r.db.QueryRowContext(ctx, "SELECT is_occupied FROM slots WHERE slot_id = $1", slotID).Scan(&isOccupied)
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COALESCE(s.reserved_until >= NOW(), false) OR NOT "+condition+" FROM slots s WHERE s.slot_id = $1 AND s.warehouse_id = $2",
+		append([]interface{}{slotID, warehouseID}, args...)...,
+	).Scan(&isOccupied)
 	if err == sql.ErrNoRows {
 		return false, nil 
 	}
 	return isOccupied, err
 }
 
-func (r *PostgresRepository) CreatePlacementRequest(ctx context.Context, req *domain.PlaceRequest) (int, error) {
-	var requestID int
-	err := r.db.QueryRowContext(ctx,
-		"INSERT INTO placement_requests (item_id, batch_id, quantity) VALUES ($1, $2, $3) RETURNING request_id",
-		req.ItemID, req.BatchID, req.Quantity,
-	).Scan(&requestID)
-	return requestID, err
-}
-
 func (r *PostgresRepository) CreatePlacementLog(ctx context.Context, slotID, itemID, batchID, algorithm string) error {
 	_, err := r.db.ExecContext(ctx,
 		"INSERT INTO placement_logs (slot_id, item_id, batch_id, algorithm) VALUES ($1, $2, $3, $4)",
@@ -96,10 +121,17 @@ func (r *PostgresRepository) UpdateSlotOccupation(ctx context.Context, slotID st
 }
 
 
-func (r *PostgresRepository) CreatePlacementResponse(ctx context.Context, requestID int, success bool, slotID, algorithm string, score float64, comment string) error {
-	_, err := r.db.ExecContext(ctx,
-		"INSERT INTO placement_responses (request_id, success, slot_id, algorithm_used, score, comment) VALUES ($1, $2, $3, $4, $5, $6)",
-		requestID, success, slotID, algorithm, score, comment,
-	)
-	return err
-} 
\ No newline at end of file
+// GetTravelDistances returns walking distances from the dock (the nearest one if empty) to the slots
+// of the warehouse, or nil if the warehouse has no topology
+func (r *PostgresRepository) GetTravelDistances(ctx context.Context, warehouseID, dockID string) (map[string]float64, error) {
+	distances, err := r.distances.Distances(ctx, warehouseID)
+	if err != nil || distances == nil {
+		return nil, err
+	}
+	return distances.FromDock(dockID)
+}
+
+func (r *PostgresRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
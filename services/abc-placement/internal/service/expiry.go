@@ -0,0 +1,27 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"warehouse/pkg/placement"
+)
+
+// zoneForExpiry moves a short-dated batch one zone closer to the exit so that it is picked before
+// it expires. Batches without an expiry date, with a long shelf life or already expired keep their zone
+func (s *PlacementService) zoneForExpiry(ctx context.Context, batchID, zoneType string) (string, error) {
+	expiresAt, err := s.repo.GetBatchExpiry(ctx, batchID)
+	if err != nil {
+		return "", fmt.Errorf("error getting batch expiry: %w", err)
+	}
+
+	now := time.Now()
+	if placement.ExpiryUrgency(expiresAt, now) == 0 || placement.Expired(expiresAt, now) {
+		return zoneType, nil
+	}
+	if faster, ok := placement.FasterZone(zoneType); ok {
+		return faster, nil
+	}
+	return zoneType, nil
+}
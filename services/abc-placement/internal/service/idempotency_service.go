@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+
+	"warehouse/pkg/idempotency"
+	"warehouse/services/abc-placement/internal/domain"
+)
+
+const idempotencyScope = "abc_placement"
+
+// Idempotency errors, see package idempotency
+var (
+	ErrIdempotencyKeyReused        = idempotency.ErrKeyReused
+	ErrIdempotentRequestInProgress = idempotency.ErrInProgress
+)
+
+// ExecuteIdempotent runs a state-changing command at most once per idempotency key:
+// a retry with the same key and body returns the stored response, a different body is rejected
+func (s *PlacementService) ExecuteIdempotent(ctx context.Context, key string, req *domain.PlaceRequest, run func(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error)) (*domain.PlaceResponse, error) {
+	return idempotency.Execute(ctx, s.repo, idempotencyScope, key, req, run)
+}
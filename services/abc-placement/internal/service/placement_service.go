@@ -3,36 +3,50 @@ package service
 import (
 	"context"
 	"fmt"
-	"sort"
 
+	"warehouse/pkg/placement"
+	"warehouse/pkg/reservation"
 	"warehouse/services/abc-placement/internal/domain"
 	"warehouse/services/abc-placement/internal/repository"
 )
 
 
 type PlacementService struct {
-	repo repository.Repository
+	repo         repository.Repository
+	reservations *reservation.Manager
 }
 
 
 func NewPlacementService(repo repository.Repository) *PlacementService {
-	return &PlacementService{repo: repo}
+	s := &PlacementService{repo: repo}
+	s.reservations = reservation.NewManager(repo, "abc_placement", s.Candidates)
+	return s
 }
 
 
 func (s *PlacementService) AnalyzePlacement(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
+	candidates, rejection, err := s.RankCandidates(ctx, req, 1)
+	if err != nil || rejection != nil {
+		return rejection, err
+	}
+	return &candidates[0], nil
+}
+
+// RankCandidates returns up to limit suitable slots, best first (limit <= 0 means all).
+// If no slot fits, it returns a rejection response explaining why
+func (s *PlacementService) RankCandidates(ctx context.Context, req *domain.PlaceRequest, limit int) ([]domain.PlaceResponse, *domain.PlaceResponse, error) {
 
 	itemExists, err := s.repo.ItemExists(ctx, req.ItemID)
 	if err != nil {
-		return nil, fmt.Errorf("error checking item existence: %w", err)
+		return nil, nil, fmt.Errorf("error checking item existence: %w", err)
 	}
 	batchExists, err := s.repo.BatchExists(ctx, req.BatchID)
 	if err != nil {
-		return nil, fmt.Errorf("error checking batch existence: %w", err)
+		return nil, nil, fmt.Errorf("error checking batch existence: %w", err)
 	}
 
 	if !itemExists || !batchExists {
-		return &domain.PlaceResponse{
+		return nil, &domain.PlaceResponse{
 			Success: false,
 			Comment: "Item or batch not found",
 			Score:   0,
@@ -42,11 +56,11 @@ func (s *PlacementService) AnalyzePlacement(ctx context.Context, req *domain.Pla
 
 	item, err := s.repo.GetItemDetails(ctx, req.ItemID)
 	if err != nil {
-		return nil, fmt.Errorf("error getting item details: %w", err)
+		return nil, nil, fmt.Errorf("error getting item details: %w", err)
 	}
 	
 	if item == nil {
-		return &domain.PlaceResponse{
+		return nil, &domain.PlaceResponse{
 			Success: false,
 			Comment: "Item details not found",
 			Score:   0,
@@ -54,59 +68,60 @@ func (s *PlacementService) AnalyzePlacement(ctx context.Context, req *domain.Pla
 	}
 
 
-	var abcCategory string
-	if item.Turnover >= 0.8 {
-		abcCategory = "A"
-	} else if item.Turnover >= 0.15 {
-		abcCategory = "B"
-	} else {
-		abcCategory = "C"
+	abcCategory := placement.ABCClass(item.Turnover)
+	targetZoneType := placement.ClassZone(abcCategory)
+
+	// Short-dated batches go one zone closer to the exit (FEFO)
+	targetZoneType, err = s.zoneForExpiry(ctx, req.BatchID, targetZoneType)
+	if err != nil {
+		return nil, nil, err
 	}
 
 
-	var targetZoneType string
-	switch abcCategory {
-	case "A":
-		targetZoneType = "fast-access"
-	case "B":
-		targetZoneType = "regular"
-	case "C":
-		targetZoneType = "deep"
-	default:
-		targetZoneType = "regular" 
+	load, err := s.repo.ResolveLoad(ctx, req.ItemID, req.BatchID, req.Quantity)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error resolving load: %w", err)
 	}
 
-
-	slots, err := s.repo.GetAvailableSlots(ctx, targetZoneType)
+	slots, err := s.repo.GetAvailableSlots(ctx, req.Warehouse(), targetZoneType, load)
 	if err != nil {
-		return nil, fmt.Errorf("error getting available slots: %w", err)
+		return nil, nil, fmt.Errorf("error getting available slots: %w", err)
 	}
 
 
-	sort.Slice(slots, func(i, j int) bool {
-		return slots[i].DistanceFromExit < slots[j].DistanceFromExit
-	})
+	if rejection, err := s.orderSlots(ctx, req, slots); err != nil || rejection != nil {
+		return nil, rejection, err
+	}
 
 
-	var suggestedSlotID string
-	if len(slots) > 0 {
-		suggestedSlotID = slots[0].SlotID
-		return &domain.PlaceResponse{
-			Success: true,
-			SlotID:  suggestedSlotID,
-			Comment: fmt.Sprintf("Suggested placement in zone %s (ABC category %s)", targetZoneType, abcCategory),
-			Score:   0.9,
+	if len(slots) == 0 {
+		return nil, &domain.PlaceResponse{
+			Success: false,
+			Comment: fmt.Sprintf("No available slots found in zone %s (ABC category %s)", targetZoneType, abcCategory),
+			Score:   0,
 		}, nil
 	}
 
-	return &domain.PlaceResponse{
-		Success: false,
-		Comment: fmt.Sprintf("No available slots found in zone %s (ABC category %s)", targetZoneType, abcCategory),
-		Score:   0,
-	}, nil
+	if limit > 0 && len(slots) > limit {
+		slots = slots[:limit]
+	}
+
+	candidates := make([]domain.PlaceResponse, 0, len(slots))
+	for _, slot := range slots {
+		candidates = append(candidates, domain.PlaceResponse{
+			Success:        true,
+			SlotID:         slot.SlotID,
+			Comment:        fmt.Sprintf("Suggested placement in zone %s (ABC category %s)", targetZoneType, abcCategory),
+			Score:          0.9,
+			BaseScore:      0.9,
+			DistanceToExit: slot.ExitDistance(),
+		})
+	}
+	return candidates, nil, nil
 }
 
 
+
 func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
 	
 	requestID, err := s.repo.CreatePlacementRequest(ctx, req)
@@ -147,37 +162,29 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 	}
 
 
-	var abcCategory string
-	if item.Turnover >= 0.8 {
-		abcCategory = "A"
-	} else if item.Turnover >= 0.15 {
-		abcCategory = "B"
-	} else {
-		abcCategory = "C"
+	abcCategory := placement.ABCClass(item.Turnover)
+	targetZoneType := placement.ClassZone(abcCategory)
+
+	// Short-dated batches go one zone closer to the exit (FEFO)
+	targetZoneType, err = s.zoneForExpiry(ctx, req.BatchID, targetZoneType)
+	if err != nil {
+		return nil, err
 	}
 
-	
-	var targetZoneType string
-	switch abcCategory {
-	case "A":
-		targetZoneType = "fast-access"
-	case "B":
-		targetZoneType = "regular"
-	case "C":
-		targetZoneType = "deep"
-	default:
-		targetZoneType = "regular"
-	}
-
-	slots, err := s.repo.GetAvailableSlots(ctx, targetZoneType)
+	load, err := s.repo.ResolveLoad(ctx, req.ItemID, req.BatchID, req.Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving load: %w", err)
+	}
+
+	slots, err := s.repo.GetAvailableSlots(ctx, req.Warehouse(), targetZoneType, load)
 	if err != nil {
 		return nil, fmt.Errorf("error getting available slots: %w", err)
 	}
 
 
-	sort.Slice(slots, func(i, j int) bool {
-		return slots[i].DistanceFromExit < slots[j].DistanceFromExit
-	})
+	if rejection, err := s.orderSlots(ctx, req, slots); err != nil || rejection != nil {
+		return rejection, err
+	}
 
 
 	var chosenSlotID string
@@ -185,14 +192,8 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 		chosenSlotID = slots[0].SlotID
 		
 	
-		if err := s.repo.UpdateSlotOccupation(ctx, chosenSlotID, true); err != nil {
-			return nil, fmt.Errorf("error updating slot occupation: %w", err)
-		}
-
-
-		if err := s.repo.CreatePlacementLog(ctx, chosenSlotID, req.ItemID, req.BatchID, "abc_placement"); err != nil {
-
-			fmt.Printf("Error creating placement log: %v\n", err)
+		if err := s.repo.PlaceInSlot(ctx, chosenSlotID, req.ItemID, req.BatchID, "abc_placement", req.Quantity); err != nil {
+			return nil, fmt.Errorf("error placing item in slot: %w", err)
 		}
 
 
@@ -202,10 +203,12 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 		}
 
 		return &domain.PlaceResponse{
-			Success: true,
-			SlotID:  chosenSlotID,
-			Comment: fmt.Sprintf("Item placed successfully in slot %s", chosenSlotID),
-			Score:   1.0,
+			Success:        true,
+			SlotID:         chosenSlotID,
+			Comment:        fmt.Sprintf("Item placed successfully in slot %s", chosenSlotID),
+			Score:          1.0,
+			BaseScore:      1.0,
+			DistanceToExit: slots[0].ExitDistance(),
 		}, nil
 	}
 
@@ -214,4 +217,12 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 		Comment: fmt.Sprintf("No available slots found in zone %s (ABC category %s) for placement", targetZoneType, abcCategory),
 		Score:   0,
 	}, nil
-} 
\ No newline at end of file
+}
+
+// HealthCheck verifies that the service can reach its database
+func (s *PlacementService) HealthCheck(ctx context.Context) error {
+	if err := s.repo.Ping(ctx); err != nil {
+		return fmt.Errorf("database is unavailable: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"warehouse/services/abc-placement/internal/domain"
+)
+
+// The reserve, commit and release commands follow the shared protocol from package reservation
+
+// ReserveSlot selects a slot the same way AnalyzePlacement does and holds it for the request until the TTL expires
+func (s *PlacementService) ReserveSlot(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
+	return s.reservations.Reserve(ctx, req)
+}
+
+// CommitReservation occupies exactly the slot held by the reservation token
+func (s *PlacementService) CommitReservation(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
+	return s.reservations.Commit(ctx, req)
+}
+
+// ReleaseReservation returns a reserved slot back to the pool of available slots
+func (s *PlacementService) ReleaseReservation(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
+	return s.reservations.Release(ctx, req)
+}
@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"warehouse/pkg/placement/placementtest"
+	"warehouse/services/fixed-placement/internal/repository"
+	"warehouse/services/fixed-placement/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contractRepo - репозиторий без товаров и партий: контрактные проверки либо отклоняются до обращения
+// к базе, либо выполняют analyze отсутствующего товара. Остальные методы набору не нужны
+type contractRepo struct {
+	repository.Repository
+}
+
+func (r *contractRepo) ItemExists(ctx context.Context, itemID string) (bool, error) {
+	return false, nil
+}
+
+func (r *contractRepo) BatchExists(ctx context.Context, batchID string) (bool, error) {
+	return false, nil
+}
+
+// TestContract прогоняет контрактный набор placementtest на маршрутах сервиса
+func TestContract(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewPlacementHandler(service.NewPlacementService(&contractRepo{})).RegisterRoutes(router)
+
+	if err := placementtest.Check(router, "/api/v1/fixed-placement"); err != nil {
+		t.Fatal(err)
+	}
+}
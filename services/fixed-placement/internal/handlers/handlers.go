@@ -20,13 +20,6 @@ func (h *Handler) GetProducts(c *gin.Context) {
 	})
 }
 
-func (h *Handler) GetWarehouses(c *gin.Context) {
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Warehouses endpoint",
-	})
-}
-
 func (h *Handler) GetInventory(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{
@@ -38,7 +31,6 @@ func (h *Handler) RegisterRoutes(router *gin.Engine) {
 	api := router.Group("/api/v1")
 	{
 		api.GET("/products", h.GetProducts)
-		api.GET("/warehouses", h.GetWarehouses)
 		api.GET("/inventory", h.GetInventory)
 	}
 } 
\ No newline at end of file
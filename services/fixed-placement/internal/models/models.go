@@ -11,15 +11,6 @@ type Product struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
-type Warehouse struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Location  string    `json:"location"`
-	Capacity  int       `json:"capacity"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-}
-
 type Inventory struct {
 	ID          int64     `json:"id"`
 	ProductID   int64     `json:"product_id"`
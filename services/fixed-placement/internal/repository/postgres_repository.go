@@ -3,15 +3,22 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"warehouse/pkg/capacity"
+	"warehouse/pkg/idempotency"
+	"warehouse/pkg/reservation"
 	"warehouse/services/fixed-placement/internal/domain"
 )
 
 type PostgresRepository struct {
+	// SlotStore и KeyStore - резервирование, размещение и ключи идемпотентности, общие для всех сервисов
+	*reservation.SlotStore
+	*idempotency.KeyStore
+
 	db *sql.DB
 }
 
 func NewPostgresRepository(db *sql.DB) *PostgresRepository {
-	return &PostgresRepository{db: db}
+	return &PostgresRepository{SlotStore: reservation.NewSlotStore(db), KeyStore: idempotency.NewKeyStore(db), db: db}
 }
 
 func (r *PostgresRepository) ItemExists(ctx context.Context, itemID string) (bool, error) {
@@ -26,28 +33,31 @@ func (r *PostgresRepository) BatchExists(ctx context.Context, batchID string) (b
 	return exists, err
 }
 
-func (r *PostgresRepository) GetFixedSlot(ctx context.Context, itemID string) (string, error) {
-	var slotID string
-	err := r.db.QueryRowContext(ctx, "SELECT slot_id FROM item_slot_map WHERE item_id = $1", itemID).Scan(&slotID)
+// GetFixedSlot возвращает фиксированную ячейку товара на складе; nil - ячейки нет
+func (r *PostgresRepository) GetFixedSlot(ctx context.Context, warehouseID, itemID string) (*domain.Slot, error) {
+	var slot domain.Slot
+	err := r.db.QueryRowContext(ctx,
+		"SELECT m.slot_id, s.distance_from_exit FROM item_slot_map m JOIN slots s ON s.slot_id = m.slot_id AND s.warehouse_id = m.warehouse_id WHERE m.warehouse_id = $1 AND m.item_id = $2",
+		warehouseID, itemID,
+	).Scan(&slot.SlotID, &slot.DistanceFromExit)
 	if err == sql.ErrNoRows {
-		return "", nil
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
 	}
-	return slotID, err
+	return &slot, nil
 }
 
-func (r *PostgresRepository) IsSlotOccupied(ctx context.Context, slotID string) (bool, error) {
+// IsSlotOccupied сообщает, что ячейка склада зарезервирована или груз в нее не помещается
+func (r *PostgresRepository) IsSlotOccupied(ctx context.Context, warehouseID, slotID string, load capacity.Load) (bool, error) {
+	condition, args := capacity.FitsCondition(load, 3)
 	var isOccupied bool
-	err := r.db.QueryRowContext(ctx, "SELECT is_occupied FROM slots WHERE slot_id = $1", slotID).Scan(&isOccupied)
-	return isOccupied, err
-}
-
-func (r *PostgresRepository) CreatePlacementRequest(ctx context.Context, req *domain.PlaceRequest) (int, error) {
-	var requestID int
 	err := r.db.QueryRowContext(ctx,
-		"INSERT INTO placement_requests (item_id, batch_id, quantity) VALUES ($1, $2, $3) RETURNING request_id",
-		req.ItemID, req.BatchID, req.Quantity,
-	).Scan(&requestID)
-	return requestID, err
+		"SELECT COALESCE(s.reserved_until >= NOW(), false) OR NOT "+condition+" FROM slots s WHERE s.slot_id = $1 AND s.warehouse_id = $2",
+		append([]interface{}{slotID, warehouseID}, args...)...,
+	).Scan(&isOccupied)
+	return isOccupied, err
 }
 
 func (r *PostgresRepository) UpdateSlotOccupation(ctx context.Context, slotID string, isOccupied bool) error {
@@ -63,10 +73,7 @@ func (r *PostgresRepository) CreatePlacementLog(ctx context.Context, slotID, ite
 	return err
 }
 
-func (r *PostgresRepository) CreatePlacementResponse(ctx context.Context, requestID int, success bool, slotID, algorithm string, score float64, comment string) error {
-	_, err := r.db.ExecContext(ctx,
-		"INSERT INTO placement_responses (request_id, success, slot_id, algorithm_used, score, comment) VALUES ($1, $2, $3, $4, $5, $6)",
-		requestID, success, slotID, algorithm, score, comment,
-	)
-	return err
-} 
\ No newline at end of file
+func (r *PostgresRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
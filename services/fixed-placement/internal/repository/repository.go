@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"time"
+
+	"warehouse/pkg/capacity"
 	"warehouse/services/fixed-placement/internal/domain"
 )
 
@@ -11,8 +14,8 @@ type Repository interface {
 
 	BatchExists(ctx context.Context, batchID string) (bool, error)
 
-	GetFixedSlot(ctx context.Context, itemID string) (string, error)
-	IsSlotOccupied(ctx context.Context, slotID string) (bool, error)
+	GetFixedSlot(ctx context.Context, warehouseID, itemID string) (*domain.Slot, error)
+	IsSlotOccupied(ctx context.Context, warehouseID, slotID string, load capacity.Load) (bool, error)
 	CreatePlacementRequest(ctx context.Context, req *domain.PlaceRequest) (int, error)
 
 	UpdateSlotOccupation(ctx context.Context, slotID string, isOccupied bool) error
@@ -20,4 +23,22 @@ type Repository interface {
 	CreatePlacementLog(ctx context.Context, slotID, itemID, batchID, algorithm string) error
 	
 	CreatePlacementResponse(ctx context.Context, requestID int, success bool, slotID, algorithm string, score float64, comment string) error
-} 
\ No newline at end of file
+
+	ResolveLoad(ctx context.Context, itemID, batchID string, quantity int) (capacity.Load, error)
+
+	ReserveSlot(ctx context.Context, warehouseID, slotID, token string, ttl time.Duration, load capacity.Load) (bool, error)
+
+	CommitReservation(ctx context.Context, warehouseID, token, itemID, batchID, algorithm string, quantity int) (string, error)
+
+	PlaceInSlot(ctx context.Context, slotID, itemID, batchID, algorithm string, quantity int) error
+
+	ReleaseReservation(ctx context.Context, warehouseID, token string) (bool, error)
+
+	Ping(ctx context.Context) error
+
+	BeginIdempotentRequest(ctx context.Context, scope, key, requestHash string) (*domain.IdempotencyRecord, bool, error)
+
+	CompleteIdempotentRequest(ctx context.Context, scope, key string, response []byte) error
+
+	DeleteIdempotentRequest(ctx context.Context, scope, key string) error
+}
@@ -2,35 +2,49 @@ package service
 
 import (
 	"context"
+	"warehouse/pkg/reservation"
 	"warehouse/services/fixed-placement/internal/domain"
 	"warehouse/services/fixed-placement/internal/repository"
 )
 
 // PlacementService реализует бизнес-логику размещения товаров
 type PlacementService struct {
-	repo repository.Repository
+	repo         repository.Repository
+	reservations *reservation.Manager
 }
 
 // NewPlacementService создает новый экземпляр PlacementService
 func NewPlacementService(repo repository.Repository) *PlacementService {
-	return &PlacementService{repo: repo}
+	s := &PlacementService{repo: repo}
+	s.reservations = reservation.NewManager(repo, "fixed_placement", s.Candidates)
+	return s
 }
 
 // AnalyzePlacement анализирует возможность размещения товара
 func (s *PlacementService) AnalyzePlacement(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
+	candidates, rejection, err := s.RankCandidates(ctx, req, 1)
+	if err != nil || rejection != nil {
+		return rejection, err
+	}
+	return &candidates[0], nil
+}
+
+// RankCandidates возвращает подходящие ячейки, начиная с лучшей. У товара не больше одной
+// закрепленной ячейки, поэтому кандидат всегда один; если ячейка не подходит, возвращается отказ с причиной
+func (s *PlacementService) RankCandidates(ctx context.Context, req *domain.PlaceRequest, limit int) ([]domain.PlaceResponse, *domain.PlaceResponse, error) {
 	// Проверяем существование товара и партии
 	itemExists, err := s.repo.ItemExists(ctx, req.ItemID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	batchExists, err := s.repo.BatchExists(ctx, req.BatchID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if !itemExists || !batchExists {
-		return &domain.PlaceResponse{
+		return nil, &domain.PlaceResponse{
 			Success: false,
 			Comment: "Товар или партия не найдены",
 			Score:   0,
@@ -38,51 +52,60 @@ func (s *PlacementService) AnalyzePlacement(ctx context.Context, req *domain.Pla
 	}
 
 	// Проверяем закрепленную ячейку
-	slotID, err := s.repo.GetFixedSlot(ctx, req.ItemID)
+	slot, err := s.repo.GetFixedSlot(ctx, req.Warehouse(), req.ItemID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if slotID == "" {
-		return &domain.PlaceResponse{
+	if slot == nil {
+		return nil, &domain.PlaceResponse{
 			Success: false,
 			Comment: "Нет закреплённой ячейки для данного товара",
 			Score:   0,
 		}, nil
 	}
 
-	// Проверяем занятость ячейки
-	isOccupied, err := s.repo.IsSlotOccupied(ctx, slotID)
+	// Проверяем, помещается ли груз в ячейку
+	load, err := s.repo.ResolveLoad(ctx, req.ItemID, req.BatchID, req.Quantity)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	slotID := slot.SlotID
+	isOccupied, err := s.repo.IsSlotOccupied(ctx, req.Warehouse(), slotID, load)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	if isOccupied {
-		return &domain.PlaceResponse{
+		return nil, &domain.PlaceResponse{
 			Success: false,
 			SlotID:  slotID,
-			Comment: "Закреплённая ячейка занята",
+			Comment: "В закреплённой ячейке нет места для груза",
 			Score:   0.2,
 		}, nil
 	}
 
-	return &domain.PlaceResponse{
-		Success: true,
-		SlotID:  slotID,
-		Comment: "Ячейка доступна для размещения",
-		Score:   0.95,
-	}, nil
+	return []domain.PlaceResponse{{
+		Success:        true,
+		SlotID:         slotID,
+		Comment:        "Ячейка доступна для размещения",
+		Score:          0.95,
+		BaseScore:      0.95,
+		DistanceToExit: slot.ExitDistance(),
+	}}, nil, nil
 }
 
+
 // PlaceItem размещает товар в закрепленную ячейку
 func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
 	// Проверяем закрепленную ячейку
-	slotID, err := s.repo.GetFixedSlot(ctx, req.ItemID)
+	slot, err := s.repo.GetFixedSlot(ctx, req.Warehouse(), req.ItemID)
 	if err != nil {
 		return nil, err
 	}
 
-	if slotID == "" {
+	if slot == nil {
 		return &domain.PlaceResponse{
 			Success: false,
 			Comment: "Невозможно разместить: нет закреплённой ячейки",
@@ -90,8 +113,14 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 		}, nil
 	}
 
-	// Проверяем занятость ячейки
-	isOccupied, err := s.repo.IsSlotOccupied(ctx, slotID)
+	// Проверяем, помещается ли груз в ячейку
+	load, err := s.repo.ResolveLoad(ctx, req.ItemID, req.BatchID, req.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	slotID := slot.SlotID
+	isOccupied, err := s.repo.IsSlotOccupied(ctx, req.Warehouse(), slotID, load)
 	if err != nil {
 		return nil, err
 	}
@@ -100,7 +129,7 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 		return &domain.PlaceResponse{
 			Success: false,
 			SlotID:  slotID,
-			Comment: "Ячейка уже занята",
+			Comment: "В ячейке нет места для груза",
 			Score:   0.1,
 		}, nil
 	}
@@ -111,13 +140,8 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 		return nil, err
 	}
 
-	// Обновляем статус ячейки
-	if err := s.repo.UpdateSlotOccupation(ctx, slotID, true); err != nil {
-		return nil, err
-	}
-
-	// Создаем запись в логе размещений
-	if err := s.repo.CreatePlacementLog(ctx, slotID, req.ItemID, req.BatchID, "fixed_placement"); err != nil {
+	// Занимаем ячейку, записываем журнал размещений и событие ItemPlaced
+	if err := s.repo.PlaceInSlot(ctx, slotID, req.ItemID, req.BatchID, "fixed_placement", req.Quantity); err != nil {
 		return nil, err
 	}
 
@@ -127,9 +151,16 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 	}
 
 	return &domain.PlaceResponse{
-		Success: true,
-		SlotID:  slotID,
-		Comment: "Товар успешно размещён в закреплённой ячейке",
-		Score:   1.0,
+		Success:        true,
+		SlotID:         slotID,
+		Comment:        "Товар успешно размещён в закреплённой ячейке",
+		Score:          1.0,
+		BaseScore:      1.0,
+		DistanceToExit: slot.ExitDistance(),
 	}, nil
-} 
\ No newline at end of file
+}
+
+// HealthCheck проверяет доступность базы данных
+func (s *PlacementService) HealthCheck(ctx context.Context) error {
+	return s.repo.Ping(ctx)
+}
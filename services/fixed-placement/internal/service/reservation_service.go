@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+
+	"warehouse/services/fixed-placement/internal/domain"
+)
+
+// Команды reserve, commit и release выполняются по общему протоколу из пакета reservation
+
+// ReserveSlot подбирает ячейку так же, как AnalyzePlacement, и удерживает ее за запросом до истечения TTL
+func (s *PlacementService) ReserveSlot(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
+	return s.reservations.Reserve(ctx, req)
+}
+
+// CommitReservation занимает ровно ту ячейку, которая удерживается токеном резерва
+func (s *PlacementService) CommitReservation(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
+	return s.reservations.Commit(ctx, req)
+}
+
+// ReleaseReservation возвращает зарезервированную ячейку в пул свободных
+func (s *PlacementService) ReleaseReservation(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
+	return s.reservations.Release(ctx, req)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"net"
 
 	"warehouse/services/free-placement/internal/config"
 	"warehouse/services/free-placement/internal/handler"
@@ -10,6 +11,7 @@ import (
 	"warehouse/services/free-placement/pkg/database"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -34,6 +36,19 @@ func main() {
 	placementService := service.NewPlacementService(repo)
 	placementHandler := handler.NewPlacementHandler(placementService)
 
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		log.Fatalf("Ошибка открытия порта gRPC: %v", err)
+	}
+	grpcServer := grpc.NewServer()
+	handler.NewPlacementGRPCServer(placementService).Register(grpcServer)
+	go func() {
+		log.Printf("Free Placement gRPC-сервер запущен на :%s", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalf("Ошибка запуска gRPC-сервера: %v", err)
+		}
+	}()
+
 
 	router := gin.Default()
 	placementHandler.RegisterRoutes(router)
@@ -1,34 +1,28 @@
 package domain
 
-type PlaceRequest struct {
-	ItemID   string  `json:"item_id"`
-	BatchID  string  `json:"batch_id"`
-	Quantity int     `json:"quantity"`
-	Command  string  `json:"command"` 
+import (
+	"warehouse/pkg/idempotency"
+	"warehouse/pkg/placement"
+)
 
-	
-	Weight        float64 `json:"weight"`
-	Volume        float64 `json:"volume"`
-	TurnoverRate  float64 `json:"turnover_rate"`
-	DemandRate    float64 `json:"demand_rate"`
-	Seasonality   float64 `json:"seasonality"`
-	ABCClass      string  `json:"abc_class"`
-	XYZClass      string  `json:"xyz_class"`
-	IsHeavy       bool    `json:"is_heavy"`
-	IsFragile     bool    `json:"is_fragile"`
-	IsHazardous   bool    `json:"is_hazardous"`
-	StorageTemp   float64 `json:"storage_temp"`
-	StorageHumidity float64 `json:"storage_humidity"`
+// PlaceRequest и PlaceResponse - общий контракт сервисов размещения, см. пакет placement
+type (
+	PlaceRequest  = placement.Request
+	PlaceResponse = placement.Response
+)
 
 
-	WarehouseLoad float64 `json:"warehouse_load"`
-	HasFixedSlot  bool    `json:"has_fixed_slot"`
-	FastAccessZone bool   `json:"fast_access_zone"`
+// Slot - свободная ячейка склада
+type Slot struct {
+	SlotID           string `json:"slot_id"`
+	DistanceFromExit int    `json:"distance_from_exit"`
 }
 
-type PlaceResponse struct {
-	Success bool    `json:"success"`
-	SlotID  string  `json:"slot_id"`
-	Comment string  `json:"comment"`
-	Score   float64 `json:"score"`
-} 
\ No newline at end of file
+// ExitDistance - расстояние от ячейки до выхода, которое передается оркестратору как distance_to_exit
+func (s Slot) ExitDistance() *float64 {
+	distance := float64(s.DistanceFromExit)
+	return &distance
+}
+
+// IdempotencyRecord - сохраненный отпечаток запроса и ответ на него, см. пакет idempotency
+type IdempotencyRecord = idempotency.Record
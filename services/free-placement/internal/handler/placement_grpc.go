@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"warehouse/pkg/placement/placementgrpc"
+	"warehouse/services/free-placement/internal/service"
+)
+
+// NewPlacementGRPCServer обслуживает контракт размещения по gRPC рядом с маршрутами Gin,
+// см. пакет placementgrpc
+func NewPlacementGRPCServer(service *service.PlacementService) *placementgrpc.Server {
+	return placementgrpc.NewServer(service)
+}
@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"errors"
+	"io"
 	"net/http"
 
+	"warehouse/pkg/placement"
 	"warehouse/services/free-placement/internal/domain"
 	"warehouse/services/free-placement/internal/service"
 
@@ -18,34 +21,65 @@ func NewPlacementHandler(service *service.PlacementService) *PlacementHandler {
 }
 
 
-func (h *PlacementHandler) RegisterRoutes(router *gin.Engine) {
+func (h *PlacementHandler) RegisterRoutes(router gin.IRouter) {
 	router.POST("/process-placement", h.ProcessPlacementRequest)
+	router.GET("/healthz", h.Healthz)
 }
 
 func (h *PlacementHandler) ProcessPlacementRequest(c *gin.Context) {
-	var req domain.PlaceRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req, err := placement.DecodeRequest(body)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
 	var response *domain.PlaceResponse
-	var err error
+	idempotencyKey := c.GetHeader("Idempotency-Key")
 
 	switch req.Command {
 	case "analyze":
-		response, err = h.service.AnalyzePlacement(c.Request.Context(), &req)
+		response, err = h.service.AnalyzePlacement(c.Request.Context(), req)
 	case "place":
-		response, err = h.service.PlaceItem(c.Request.Context(), &req)
+		response, err = h.service.ExecuteIdempotent(c.Request.Context(), idempotencyKey, req, h.service.PlaceItem)
+	case "reserve":
+		response, err = h.service.ExecuteIdempotent(c.Request.Context(), idempotencyKey, req, h.service.ReserveSlot)
+	case "commit":
+		response, err = h.service.ExecuteIdempotent(c.Request.Context(), idempotencyKey, req, h.service.CommitReservation)
+	case "release":
+		response, err = h.service.ExecuteIdempotent(c.Request.Context(), idempotencyKey, req, h.service.ReleaseReservation)
 	default:
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Неизвестная команда: " + req.Command})
 		return
 	}
 
+	if errors.Is(err, service.ErrIdempotencyKeyReused) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+		return
+	}
+	if errors.Is(err, service.ErrIdempotentRequestInProgress) {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Внутренняя ошибка сервера"})
 		return
 	}
 
+	response.SchemaVersion = placement.SchemaVersion
 	c.JSON(http.StatusOK, response)
-} 
\ No newline at end of file
+}
+
+// Healthz сообщает, доступны ли сервис и его база данных
+func (h *PlacementHandler) Healthz(c *gin.Context) {
+	if err := h.service.HealthCheck(c.Request.Context()); err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
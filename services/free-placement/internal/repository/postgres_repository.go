@@ -3,16 +3,23 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"warehouse/pkg/capacity"
+	"warehouse/pkg/idempotency"
+	"warehouse/pkg/reservation"
 	"warehouse/services/free-placement/internal/domain"
 )
 
 
 type PostgresRepository struct {
+	// SlotStore и KeyStore - резервирование, размещение и ключи идемпотентности, общие для всех сервисов
+	*reservation.SlotStore
+	*idempotency.KeyStore
+
 	db *sql.DB
 }
 
 func NewPostgresRepository(db *sql.DB) *PostgresRepository {
-	return &PostgresRepository{db: db}
+	return &PostgresRepository{SlotStore: reservation.NewSlotStore(db), KeyStore: idempotency.NewKeyStore(db), db: db}
 }
 
 func (r *PostgresRepository) ItemExists(ctx context.Context, itemID string) (bool, error) {
@@ -27,29 +34,56 @@ func (r *PostgresRepository) BatchExists(ctx context.Context, batchID string) (b
 	return exists, err
 }
 
-func (r *PostgresRepository) GetFirstFreeSlot(ctx context.Context) (string, error) {
-	var slotID string
-	err := r.db.QueryRowContext(ctx, 
-		"SELECT slot_id FROM slots WHERE is_occupied = false ORDER BY slot_id LIMIT 1").Scan(&slotID)
+// GetFirstFreeSlot возвращает первую незарезервированную ячейку склада, в которую помещается груз;
+// nil - такой ячейки нет
+func (r *PostgresRepository) GetFirstFreeSlot(ctx context.Context, warehouseID string, load capacity.Load) (*domain.Slot, error) {
+	condition, args := capacity.FitsCondition(load, 2)
+	var slot domain.Slot
+	err := r.db.QueryRowContext(ctx,
+		"SELECT s.slot_id, s.distance_from_exit FROM slots s WHERE (s.reserved_until IS NULL OR s.reserved_until < NOW()) AND s.warehouse_id = $1 AND "+condition+" ORDER BY s.slot_id LIMIT 1",
+		append([]interface{}{warehouseID}, args...)...,
+	).Scan(&slot.SlotID, &slot.DistanceFromExit)
 	if err == sql.ErrNoRows {
-		return "", nil
+		return nil, nil
 	}
-	return slotID, err
+	if err != nil {
+		return nil, err
+	}
+	return &slot, nil
 }
 
-func (r *PostgresRepository) IsSlotOccupied(ctx context.Context, slotID string) (bool, error) {
-	var isOccupied bool
-	err := r.db.QueryRowContext(ctx, "SELECT is_occupied FROM slots WHERE slot_id = $1", slotID).Scan(&isOccupied)
-	return isOccupied, err
+// GetFreeSlots возвращает до limit незарезервированных ячеек склада, в которые помещается груз (0 - все)
+func (r *PostgresRepository) GetFreeSlots(ctx context.Context, warehouseID string, limit int, load capacity.Load) ([]domain.Slot, error) {
+	condition, args := capacity.FitsCondition(load, 3)
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT s.slot_id, s.distance_from_exit FROM slots s WHERE (s.reserved_until IS NULL OR s.reserved_until < NOW()) AND s.warehouse_id = $1 AND "+condition+" ORDER BY s.slot_id LIMIT NULLIF($2, 0)",
+		append([]interface{}{warehouseID, limit}, args...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slots []domain.Slot
+	for rows.Next() {
+		var slot domain.Slot
+		if err := rows.Scan(&slot.SlotID, &slot.DistanceFromExit); err != nil {
+			return nil, err
+		}
+		slots = append(slots, slot)
+	}
+	return slots, rows.Err()
 }
 
-func (r *PostgresRepository) CreatePlacementRequest(ctx context.Context, req *domain.PlaceRequest) (int, error) {
-	var requestID int
+// IsSlotOccupied сообщает, что ячейка склада зарезервирована или груз в нее не помещается
+func (r *PostgresRepository) IsSlotOccupied(ctx context.Context, warehouseID, slotID string, load capacity.Load) (bool, error) {
+	condition, args := capacity.FitsCondition(load, 3)
+	var isOccupied bool
 	err := r.db.QueryRowContext(ctx,
-		"INSERT INTO placement_requests (item_id, batch_id, quantity) VALUES ($1, $2, $3) RETURNING request_id",
-		req.ItemID, req.BatchID, req.Quantity,
-	).Scan(&requestID)
-	return requestID, err
+		"SELECT COALESCE(s.reserved_until >= NOW(), false) OR NOT "+condition+" FROM slots s WHERE s.slot_id = $1 AND s.warehouse_id = $2",
+		append([]interface{}{slotID, warehouseID}, args...)...,
+	).Scan(&isOccupied)
+	return isOccupied, err
 }
 
 func (r *PostgresRepository) UpdateSlotOccupation(ctx context.Context, slotID string, isOccupied bool) error {
@@ -65,10 +99,7 @@ func (r *PostgresRepository) CreatePlacementLog(ctx context.Context, slotID, ite
 	return err
 }
 
-func (r *PostgresRepository) CreatePlacementResponse(ctx context.Context, requestID int, success bool, slotID, algorithm string, score float64, comment string) error {
-	_, err := r.db.ExecContext(ctx,
-		"INSERT INTO placement_responses (request_id, success, slot_id, algorithm_used, score, comment) VALUES ($1, $2, $3, $4, $5, $6)",
-		requestID, success, slotID, algorithm, score, comment,
-	)
-	return err
-} 
\ No newline at end of file
+func (r *PostgresRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"time"
+
+	"warehouse/pkg/capacity"
 	"warehouse/services/free-placement/internal/domain"
 )
 
@@ -11,9 +14,11 @@ type Repository interface {
 
 	BatchExists(ctx context.Context, batchID string) (bool, error)
 
-	GetFirstFreeSlot(ctx context.Context) (string, error)
+	GetFirstFreeSlot(ctx context.Context, warehouseID string, load capacity.Load) (*domain.Slot, error)
+
+	GetFreeSlots(ctx context.Context, warehouseID string, limit int, load capacity.Load) ([]domain.Slot, error)
 
-	IsSlotOccupied(ctx context.Context, slotID string) (bool, error)
+	IsSlotOccupied(ctx context.Context, warehouseID, slotID string, load capacity.Load) (bool, error)
 
 	CreatePlacementRequest(ctx context.Context, req *domain.PlaceRequest) (int, error)
 
@@ -22,4 +27,22 @@ type Repository interface {
 	CreatePlacementLog(ctx context.Context, slotID, itemID, batchID, algorithm string) error
 
 	CreatePlacementResponse(ctx context.Context, requestID int, success bool, slotID, algorithm string, score float64, comment string) error
-} 
+
+	ResolveLoad(ctx context.Context, itemID, batchID string, quantity int) (capacity.Load, error)
+
+	ReserveSlot(ctx context.Context, warehouseID, slotID, token string, ttl time.Duration, load capacity.Load) (bool, error)
+
+	CommitReservation(ctx context.Context, warehouseID, token, itemID, batchID, algorithm string, quantity int) (string, error)
+
+	PlaceInSlot(ctx context.Context, slotID, itemID, batchID, algorithm string, quantity int) error
+
+	ReleaseReservation(ctx context.Context, warehouseID, token string) (bool, error)
+
+	Ping(ctx context.Context) error
+
+	BeginIdempotentRequest(ctx context.Context, scope, key, requestHash string) (*domain.IdempotencyRecord, bool, error)
+
+	CompleteIdempotentRequest(ctx context.Context, scope, key string, response []byte) error
+
+	DeleteIdempotentRequest(ctx context.Context, scope, key string) error
+}
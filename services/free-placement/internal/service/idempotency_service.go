@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+
+	"warehouse/pkg/idempotency"
+	"warehouse/services/free-placement/internal/domain"
+)
+
+const idempotencyScope = "free_placement"
+
+// Ошибки идемпотентности, см. пакет idempotency
+var (
+	ErrIdempotencyKeyReused        = idempotency.ErrKeyReused
+	ErrIdempotentRequestInProgress = idempotency.ErrInProgress
+)
+
+// ExecuteIdempotent выполняет изменяющую команду не более одного раза на ключ идемпотентности:
+// повтор с тем же ключом и телом возвращает сохраненный ответ, другое тело отклоняется
+func (s *PlacementService) ExecuteIdempotent(ctx context.Context, key string, req *domain.PlaceRequest, run func(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error)) (*domain.PlaceResponse, error) {
+	return idempotency.Execute(ctx, s.repo, idempotencyScope, key, req, run)
+}
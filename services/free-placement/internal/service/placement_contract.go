@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+
+	"warehouse/pkg/placement"
+	"warehouse/services/free-placement/internal/domain"
+)
+
+// Методы ниже реализуют placement.Service, чтобы оркестратор мог вызывать алгоритм
+// в своем процессе, без HTTP и gRPC
+var _ placement.Service = (*PlacementService)(nil)
+
+// Analyze подбирает ячейку без размещения
+func (s *PlacementService) Analyze(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	return toContract(s.AnalyzePlacement(ctx, fromContract(req, placement.CommandAnalyze)))
+}
+
+// Place подбирает ячейку и размещает товар с учетом ключа идемпотентности из контекста
+func (s *PlacementService) Place(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	return toContract(s.ExecuteIdempotent(ctx, placement.IdempotencyKey(ctx), fromContract(req, placement.CommandPlace), s.PlaceItem))
+}
+
+// Reserve подбирает ячейку и удерживает ее за запросом
+func (s *PlacementService) Reserve(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	return toContract(s.ExecuteIdempotent(ctx, placement.IdempotencyKey(ctx), fromContract(req, placement.CommandReserve), s.ReserveSlot))
+}
+
+// Commit размещает товар в ячейке, удерживаемой токеном резерва
+func (s *PlacementService) Commit(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	return toContract(s.ExecuteIdempotent(ctx, placement.IdempotencyKey(ctx), fromContract(req, placement.CommandCommit), s.CommitReservation))
+}
+
+// Release снимает резерв
+func (s *PlacementService) Release(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	return toContract(s.ExecuteIdempotent(ctx, placement.IdempotencyKey(ctx), fromContract(req, placement.CommandRelease), s.ReleaseReservation))
+}
+
+// Candidates возвращает до limit подходящих ячеек, начиная с лучшей, или единственный отказ
+func (s *PlacementService) Candidates(ctx context.Context, req *placement.Request, limit int) ([]placement.Response, error) {
+	candidates, rejection, err := s.RankCandidates(ctx, fromContract(req, placement.CommandAnalyze), limit)
+	if err != nil {
+		return nil, err
+	}
+	if rejection != nil {
+		candidates = []domain.PlaceResponse{*rejection}
+	}
+
+	for i := range candidates {
+		candidates[i].SchemaVersion = placement.SchemaVersion
+	}
+	return candidates, nil
+}
+
+// fromContract копирует запрос и задает ему команду; сам запрос вызывающего не меняется
+func fromContract(req *placement.Request, command string) *domain.PlaceRequest {
+	request := *req
+	request.SchemaVersion = placement.SchemaVersion
+	request.Command = command
+	return &request
+}
+
+// toContract проставляет ответу текущую версию схемы
+func toContract(response *domain.PlaceResponse, err error) (*placement.Response, error) {
+	if err != nil {
+		return nil, err
+	}
+	response.SchemaVersion = placement.SchemaVersion
+	return response, nil
+}
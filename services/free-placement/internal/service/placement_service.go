@@ -2,34 +2,48 @@ package service
 
 import (
 	"context"
+	"warehouse/pkg/reservation"
 	"warehouse/services/free-placement/internal/domain"
 	"warehouse/services/free-placement/internal/repository"
 )
 
 
 type PlacementService struct {
-	repo repository.Repository
+	repo         repository.Repository
+	reservations *reservation.Manager
 }
 
 
 func NewPlacementService(repo repository.Repository) *PlacementService {
-	return &PlacementService{repo: repo}
+	s := &PlacementService{repo: repo}
+	s.reservations = reservation.NewManager(repo, "free_placement", s.Candidates)
+	return s
 }
 
 func (s *PlacementService) AnalyzePlacement(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
+	candidates, rejection, err := s.RankCandidates(ctx, req, 1)
+	if err != nil || rejection != nil {
+		return rejection, err
+	}
+	return &candidates[0], nil
+}
+
+// RankCandidates возвращает до limit свободных ячеек в порядке их номеров (limit <= 0 - все).
+// Если свободных ячеек нет, возвращается отказ с причиной
+func (s *PlacementService) RankCandidates(ctx context.Context, req *domain.PlaceRequest, limit int) ([]domain.PlaceResponse, *domain.PlaceResponse, error) {
 
 	itemExists, err := s.repo.ItemExists(ctx, req.ItemID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	batchExists, err := s.repo.BatchExists(ctx, req.BatchID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if !itemExists || !batchExists {
-		return &domain.PlaceResponse{
+		return nil, &domain.PlaceResponse{
 			Success: false,
 			Comment: "Товар или партия не найдены",
 			Score:   0,
@@ -37,36 +51,53 @@ func (s *PlacementService) AnalyzePlacement(ctx context.Context, req *domain.Pla
 	}
 
 
-	slotID, err := s.repo.GetFirstFreeSlot(ctx)
+	load, err := s.repo.ResolveLoad(ctx, req.ItemID, req.BatchID, req.Quantity)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	if slotID == "" {
-		return &domain.PlaceResponse{
+	slots, err := s.repo.GetFreeSlots(ctx, req.Warehouse(), limit, load)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(slots) == 0 {
+		return nil, &domain.PlaceResponse{
 			Success: false,
 			Comment: "Нет свободных ячеек для размещения",
 			Score:   0,
 		}, nil
 	}
 
-	return &domain.PlaceResponse{
-		Success: true,
-		SlotID:  slotID,
-		Comment: "Найдена свободная ячейка для размещения",
-		Score:   0.9,
-	}, nil
+	candidates := make([]domain.PlaceResponse, 0, len(slots))
+	for _, slot := range slots {
+		candidates = append(candidates, domain.PlaceResponse{
+			Success:        true,
+			SlotID:         slot.SlotID,
+			Comment:        "Найдена свободная ячейка для размещения",
+			Score:          0.9,
+			BaseScore:      0.9,
+			DistanceToExit: slot.ExitDistance(),
+		})
+	}
+	return candidates, nil, nil
 }
 
 
+
 func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
 
-	slotID, err := s.repo.GetFirstFreeSlot(ctx)
+	load, err := s.repo.ResolveLoad(ctx, req.ItemID, req.BatchID, req.Quantity)
 	if err != nil {
 		return nil, err
 	}
 
-	if slotID == "" {
+	slot, err := s.repo.GetFirstFreeSlot(ctx, req.Warehouse(), load)
+	if err != nil {
+		return nil, err
+	}
+
+	if slot == nil {
 		return &domain.PlaceResponse{
 			Success: false,
 			Comment: "Нет свободных ячеек для размещения",
@@ -75,7 +106,8 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 	}
 
 
-	isOccupied, err := s.repo.IsSlotOccupied(ctx, slotID)
+	slotID := slot.SlotID
+	isOccupied, err := s.repo.IsSlotOccupied(ctx, req.Warehouse(), slotID, load)
 	if err != nil {
 		return nil, err
 	}
@@ -84,7 +116,7 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 		return &domain.PlaceResponse{
 			Success: false,
 			SlotID:  slotID,
-			Comment: "Ячейка уже занята",
+			Comment: "В ячейке нет места для груза",
 			Score:   0.1,
 		}, nil
 	}
@@ -96,12 +128,7 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 	}
 
 
-	if err := s.repo.UpdateSlotOccupation(ctx, slotID, true); err != nil {
-		return nil, err
-	}
-
-
-	if err := s.repo.CreatePlacementLog(ctx, slotID, req.ItemID, req.BatchID, "free_placement"); err != nil {
+	if err := s.repo.PlaceInSlot(ctx, slotID, req.ItemID, req.BatchID, "free_placement", req.Quantity); err != nil {
 		return nil, err
 	}
 
@@ -111,9 +138,16 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 	}
 
 	return &domain.PlaceResponse{
-		Success: true,
-		SlotID:  slotID,
-		Comment: "Товар успешно размещён в свободной ячейке",
-		Score:   1.0,
+		Success:        true,
+		SlotID:         slotID,
+		Comment:        "Товар успешно размещён в свободной ячейке",
+		Score:          1.0,
+		BaseScore:      1.0,
+		DistanceToExit: slot.ExitDistance(),
 	}, nil
-} 
\ No newline at end of file
+}
+
+// HealthCheck проверяет доступность базы данных
+func (s *PlacementService) HealthCheck(ctx context.Context) error {
+	return s.repo.Ping(ctx)
+}
@@ -0,0 +1,39 @@
+// Package embedded собирает сервис Free Placement поверх подключения к базе данных, которым владеет
+// другой процесс, например единый бинарник монолита
+package embedded
+
+import (
+	"database/sql"
+
+	"warehouse/pkg/placement"
+	"warehouse/services/free-placement/internal/handler"
+	"warehouse/services/free-placement/internal/repository"
+	"warehouse/services/free-placement/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Embedded - сервис Free Placement, работающий внутри другого процесса
+type Embedded struct {
+	service *service.PlacementService
+	handler *handler.PlacementHandler
+}
+
+// New собирает сервис поверх общего подключения к базе данных
+func New(db *sql.DB) *Embedded {
+	placementService := service.NewPlacementService(repository.NewPostgresRepository(db))
+	return &Embedded{
+		service: placementService,
+		handler: handler.NewPlacementHandler(placementService),
+	}
+}
+
+// Service возвращает алгоритм для вызова в том же процессе
+func (e *Embedded) Service() placement.Service {
+	return e.service
+}
+
+// RegisterRoutes подключает HTTP-маршруты сервиса к роутеру процесса
+func (e *Embedded) RegisterRoutes(router gin.IRouter) {
+	e.handler.RegisterRoutes(router)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"net"
 
 	"warehouse/services/genetic-placement/internal/config"
 	"warehouse/services/genetic-placement/internal/handler"
@@ -10,6 +11,7 @@ import (
 	"warehouse/services/genetic-placement/pkg/database"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -48,6 +50,19 @@ func main() {
 	log.Println("Initializing handler...")
 	placementHandler := handler.NewPlacementHandler(placementService)
 
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		log.Fatalf("Failed to listen for gRPC: %v", err)
+	}
+	grpcServer := grpc.NewServer()
+	handler.NewPlacementGRPCServer(placementService).Register(grpcServer)
+	go func() {
+		log.Printf("Genetic Placement gRPC server starting on :%s", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalf("Failed to run gRPC server: %v", err)
+		}
+	}()
+
 
 	log.Println("Setting up router...")
 	router := gin.Default()
@@ -7,6 +7,7 @@ import (
 
 type Config struct {
 	ServerPort string
+	GRPCPort   string
 	DBHost     string
 	DBPort     string
 	DBPortInt  int
@@ -30,6 +31,7 @@ func LoadConfig() *Config {
 
 	return &Config{
 		ServerPort: getEnv("SERVER_PORT", "8085"),
+		GRPCPort:   getEnv("GRPC_PORT", "9085"),
 		DBHost:     getEnv("DB_HOST", "localhost"),
 		DBPort:     port,
 		DBPortInt:  portInt,
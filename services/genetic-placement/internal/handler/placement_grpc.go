@@ -0,0 +1,12 @@
+package handler
+
+import (
+	"warehouse/pkg/placement/placementgrpc"
+	"warehouse/services/genetic-placement/internal/service"
+)
+
+// NewPlacementGRPCServer serves the placement contract over gRPC alongside the Gin routes,
+// see package placementgrpc
+func NewPlacementGRPCServer(service *service.PlacementService) *placementgrpc.Server {
+	return placementgrpc.NewServer(service)
+}
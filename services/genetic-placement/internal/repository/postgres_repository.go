@@ -5,17 +5,27 @@ import (
 	"database/sql"
 	"fmt"
 
+	"warehouse/pkg/capacity"
+	"warehouse/pkg/idempotency"
+	"warehouse/pkg/reservation"
+	"warehouse/pkg/topology"
 	"warehouse/services/genetic-placement/internal/domain"
 
 	_ "github.com/lib/pq"
 )
 
 type PostgresRepository struct {
+	// SlotStore and KeyStore provide reservation, placement and idempotency keys shared by all services
+	*reservation.SlotStore
+	*idempotency.KeyStore
+
 	db *sql.DB
+	// distances caches shortest paths over the warehouse topology
+	distances *topology.Cache
 }
 
 func NewPostgresRepository(db *sql.DB) *PostgresRepository {
-	return &PostgresRepository{db: db}
+	return &PostgresRepository{SlotStore: reservation.NewSlotStore(db), KeyStore: idempotency.NewKeyStore(db), db: db, distances: topology.NewCache(db, topology.DefaultCacheTTL)}
 }
 
 
@@ -52,15 +62,19 @@ func (r *PostgresRepository) GetItemDetails(ctx context.Context, itemID string)
 }
 
 
-func (r *PostgresRepository) GetAllAvailableSlots(ctx context.Context) ([]domain.Slot, error) {
+// GetAllAvailableSlots returns unreserved warehouse slots that can take the load, with their remaining capacity
+func (r *PostgresRepository) GetAllAvailableSlots(ctx context.Context, warehouseID string, load capacity.Load) ([]domain.Slot, error) {
 	var slots []domain.Slot
 
+	condition, args := capacity.FitsCondition(load, 2)
 	rows, err := r.db.QueryContext(ctx, `
-		SELECT slot_id, location_description, max_weight, max_length, max_width, 
-		       max_height, storage_conditions, is_occupied, zone_type, level, 
-		       distance_from_exit 
-		FROM slots 
-		WHERE is_occupied = false`)
+		SELECT s.slot_id, s.location_description, s.max_weight, s.max_length, s.max_width,
+		       s.max_height, s.storage_conditions, s.is_occupied, s.zone_type, s.level,
+		       s.distance_from_exit, `+capacity.RemainingColumns+`
+		FROM slots s
+		WHERE (s.reserved_until IS NULL OR s.reserved_until < NOW()) AND s.warehouse_id = $1 AND `+condition,
+		append([]interface{}{warehouseID}, args...)...,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all available slots: %w", err)
 	}
@@ -72,6 +86,7 @@ func (r *PostgresRepository) GetAllAvailableSlots(ctx context.Context) ([]domain
 			&slot.SlotID, &slot.LocationDescription, &slot.MaxWeight, &slot.MaxLength, 
 			&slot.MaxWidth, &slot.MaxHeight, &slot.StorageConditions, &slot.IsOccupied, 
 			&slot.ZoneType, &slot.Level, &slot.DistanceFromExit,
+			&slot.Remaining.Weight, &slot.Remaining.Volume, &slot.Remaining.Units,
 		); err != nil {
 			return nil, fmt.Errorf("failed to scan slot row: %w", err)
 		}
@@ -86,16 +101,6 @@ func (r *PostgresRepository) GetAllAvailableSlots(ctx context.Context) ([]domain
 }
 
 
-func (r *PostgresRepository) CreatePlacementRequest(ctx context.Context, req *domain.PlaceRequest) (int, error) {
-	var requestID int
-	err := r.db.QueryRowContext(ctx,
-		"INSERT INTO placement_requests (item_id, batch_id, quantity) VALUES ($1, $2, $3) RETURNING request_id",
-		req.ItemID, req.BatchID, req.Quantity,
-	).Scan(&requestID)
-	return requestID, err
-}
-
-
 func (r *PostgresRepository) UpdateSlotOccupation(ctx context.Context, slotID string, isOccupied bool) error {
 	_, err := r.db.ExecContext(ctx, "UPDATE slots SET is_occupied = $1 WHERE slot_id = $2", isOccupied, slotID)
 	return err
@@ -111,10 +116,17 @@ func (r *PostgresRepository) CreatePlacementLog(ctx context.Context, slotID, ite
 }
 
 
-func (r *PostgresRepository) CreatePlacementResponse(ctx context.Context, requestID int, success bool, slotID, algorithm string, score float64, comment string) error {
-	_, err := r.db.ExecContext(ctx,
-		"INSERT INTO placement_responses (request_id, success, slot_id, algorithm_used, score, comment) VALUES ($1, $2, $3, $4, $5, $6)",
-		requestID, success, slotID, algorithm, score, comment,
-	)
-	return err
-} 
\ No newline at end of file
+// GetTravelDistances returns walking distances from the dock (the nearest one if empty) to the slots
+// of the warehouse, or nil if the warehouse has no topology
+func (r *PostgresRepository) GetTravelDistances(ctx context.Context, warehouseID, dockID string) (map[string]float64, error) {
+	distances, err := r.distances.Distances(ctx, warehouseID)
+	if err != nil || distances == nil {
+		return nil, err
+	}
+	return distances.FromDock(dockID)
+}
+
+func (r *PostgresRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+
+	"warehouse/pkg/placement"
+	"warehouse/services/genetic-placement/internal/domain"
+)
+
+// The methods below implement placement.Service, so the orchestrator can call this
+// algorithm in-process instead of over HTTP or gRPC
+var _ placement.Service = (*PlacementService)(nil)
+
+// Analyze selects a slot without placing the item
+func (s *PlacementService) Analyze(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	return toContract(s.AnalyzePlacement(ctx, fromContract(req, placement.CommandAnalyze)))
+}
+
+// Place selects a slot and places the item, honoring the idempotency key from the context
+func (s *PlacementService) Place(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	return toContract(s.ExecuteIdempotent(ctx, placement.IdempotencyKey(ctx), fromContract(req, placement.CommandPlace), s.PlaceItem))
+}
+
+// Reserve selects a slot and holds it for the request
+func (s *PlacementService) Reserve(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	return toContract(s.ExecuteIdempotent(ctx, placement.IdempotencyKey(ctx), fromContract(req, placement.CommandReserve), s.ReserveSlot))
+}
+
+// Commit places the item into the slot held by the reservation token
+func (s *PlacementService) Commit(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	return toContract(s.ExecuteIdempotent(ctx, placement.IdempotencyKey(ctx), fromContract(req, placement.CommandCommit), s.CommitReservation))
+}
+
+// Release drops the reservation
+func (s *PlacementService) Release(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	return toContract(s.ExecuteIdempotent(ctx, placement.IdempotencyKey(ctx), fromContract(req, placement.CommandRelease), s.ReleaseReservation))
+}
+
+// Candidates returns up to limit suitable slots, best first, or the single rejection
+func (s *PlacementService) Candidates(ctx context.Context, req *placement.Request, limit int) ([]placement.Response, error) {
+	candidates, rejection, err := s.RankCandidates(ctx, fromContract(req, placement.CommandAnalyze), limit)
+	if err != nil {
+		return nil, err
+	}
+	if rejection != nil {
+		candidates = []domain.PlaceResponse{*rejection}
+	}
+
+	for i := range candidates {
+		candidates[i].SchemaVersion = placement.SchemaVersion
+	}
+	return candidates, nil
+}
+
+// fromContract copies the request and sets its command, leaving the caller's request untouched
+func fromContract(req *placement.Request, command string) *domain.PlaceRequest {
+	request := *req
+	request.SchemaVersion = placement.SchemaVersion
+	request.Command = command
+	return &request
+}
+
+// toContract stamps the response with the current schema version
+func toContract(response *domain.PlaceResponse, err error) (*placement.Response, error) {
+	if err != nil {
+		return nil, err
+	}
+	response.SchemaVersion = placement.SchemaVersion
+	return response, nil
+}
@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"fmt"
+	"sort"
 
+	"warehouse/pkg/reservation"
 	"warehouse/services/genetic-placement/internal/config"
 	"warehouse/services/genetic-placement/internal/domain"
 	"warehouse/services/genetic-placement/internal/repository"
@@ -11,29 +13,42 @@ import (
 
 
 type PlacementService struct {
-	repo   repository.Repository
-	config *config.Config 
+	repo         repository.Repository
+	config       *config.Config 
+	reservations *reservation.Manager
 }
 
 
 func NewPlacementService(repo repository.Repository, config *config.Config) *PlacementService {
-	return &PlacementService{repo: repo, config: config}
+	s := &PlacementService{repo: repo, config: config}
+	s.reservations = reservation.NewManager(repo, "genetic_placement", s.Candidates)
+	return s
 }
 
 
 func (s *PlacementService) AnalyzePlacement(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
+	candidates, rejection, err := s.RankCandidates(ctx, req, 1)
+	if err != nil || rejection != nil {
+		return rejection, err
+	}
+	return &candidates[0], nil
+}
+
+// RankCandidates returns up to limit available slots ordered by fitness, best first (limit <= 0 means all).
+// If no slot is available, it returns a rejection response explaining why
+func (s *PlacementService) RankCandidates(ctx context.Context, req *domain.PlaceRequest, limit int) ([]domain.PlaceResponse, *domain.PlaceResponse, error) {
 
 	itemExists, err := s.repo.ItemExists(ctx, req.ItemID)
 	if err != nil {
-		return nil, fmt.Errorf("error checking item existence: %w", err)
+		return nil, nil, fmt.Errorf("error checking item existence: %w", err)
 	}
 	batchExists, err := s.repo.BatchExists(ctx, req.BatchID)
 	if err != nil {
-		return nil, fmt.Errorf("error checking batch existence: %w", err)
+		return nil, nil, fmt.Errorf("error checking batch existence: %w", err)
 	}
 
 	if !itemExists || !batchExists {
-		return &domain.PlaceResponse{
+		return nil, &domain.PlaceResponse{
 			Success: false,
 			Comment: "Item or batch not found",
 			Score:   0,
@@ -43,64 +58,75 @@ func (s *PlacementService) AnalyzePlacement(ctx context.Context, req *domain.Pla
 
 	item, err := s.repo.GetItemDetails(ctx, req.ItemID)
 	if err != nil {
-		return nil, fmt.Errorf("error getting item details: %w", err)
+		return nil, nil, fmt.Errorf("error getting item details: %w", err)
 	}
 	if item == nil {
-		return &domain.PlaceResponse{
+		return nil, &domain.PlaceResponse{
 			Success: false,
 			Comment: "Item details not found",
 			Score:   0,
 		}, nil
 	}
 
-	availableSlots, err := s.repo.GetAllAvailableSlots(ctx)
+	load, err := s.repo.ResolveLoad(ctx, req.ItemID, req.BatchID, req.Quantity)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error resolving load: %w", err)
+	}
+
+	availableSlots, err := s.repo.GetAllAvailableSlots(ctx, req.Warehouse(), load)
 	if err != nil {
-		return nil, fmt.Errorf("error getting available slots: %w", err)
+		return nil, nil, fmt.Errorf("error getting available slots: %w", err)
 	}
 
 	if len(availableSlots) == 0 {
-		return &domain.PlaceResponse{
+		return nil, &domain.PlaceResponse{
 			Success: false,
 			Comment: "No available slots found",
 			Score:   0,
 		}, nil
 	}
 
+	useTopology, rejection, err := s.applyTravelDistances(ctx, req, availableSlots)
+	if err != nil || rejection != nil {
+		return nil, rejection, err
+	}
 
-	var bestCandidate *domain.PlacementCandidate
-	maxFitness := -1.0
 
-	for _, slot := range availableSlots {
-		candidate := &domain.PlacementCandidate{
+	ranked := make([]domain.PlacementCandidate, 0, len(availableSlots))
+	for i := range availableSlots {
+		candidate := domain.PlacementCandidate{
 			Item: item,
-			Slot: &slot,
+			Slot: &availableSlots[i],
 		}
-		candidate.Fitness = s.calculateFitness(candidate)
+		candidate.Fitness = s.calculateFitness(&candidate, useTopology)
+		ranked = append(ranked, candidate)
+	}
 
+	// Stable sort keeps the repository order among equal fitness, so the best slot matches the old strict-max pick
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Fitness > ranked[j].Fitness
+	})
 
-		if candidate.Fitness > maxFitness {
-			maxFitness = candidate.Fitness
-			bestCandidate = candidate
-		}
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
 	}
 
-	if bestCandidate != nil {
-		return &domain.PlaceResponse{
-			Success: true,
-			SlotID:  bestCandidate.Slot.SlotID,
-			Comment: fmt.Sprintf("Suggested placement in slot %s with fitness %.2f", bestCandidate.Slot.SlotID, bestCandidate.Fitness),
-			Score:   bestCandidate.Fitness,
-		}, nil
+	candidates := make([]domain.PlaceResponse, 0, len(ranked))
+	for _, candidate := range ranked {
+		candidates = append(candidates, domain.PlaceResponse{
+			Success:        true,
+			SlotID:         candidate.Slot.SlotID,
+			Comment:        fmt.Sprintf("Suggested placement in slot %s with fitness %.2f", candidate.Slot.SlotID, candidate.Fitness),
+			Score:          candidate.Fitness,
+			BaseScore:      candidate.Fitness,
+			DistanceToExit: candidate.Slot.ExitDistance(),
+		})
 	}
-
-	return &domain.PlaceResponse{
-		Success: false,
-		Comment: "Could not determine best placement",
-		Score:   0,
-	}, nil
+	return candidates, nil, nil
 }
 
 
+
 func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
 
 	requestID, err := s.repo.CreatePlacementRequest(ctx, req)
@@ -130,36 +156,42 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 	chosenSlotID := analyzeResponse.SlotID
 
 
-	if err := s.repo.UpdateSlotOccupation(ctx, chosenSlotID, true); err != nil {
+	if err := s.repo.PlaceInSlot(ctx, chosenSlotID, req.ItemID, req.BatchID, "genetic_placement", req.Quantity); err != nil {
 		// Log the placement response
-		if err := s.repo.CreatePlacementResponse(ctx, requestID, false, chosenSlotID, "genetic_placement", 0, fmt.Sprintf("Error updating slot occupation: %v", err)); err != nil {
+		if err := s.repo.CreatePlacementResponse(ctx, requestID, false, chosenSlotID, "genetic_placement", 0, fmt.Sprintf("Error placing item in slot: %v", err)); err != nil {
 			fmt.Printf("Error creating placement response for occupation error: %v\n", err)
 		}
-		return nil, fmt.Errorf("error updating slot occupation: %w", err)
+		return nil, fmt.Errorf("error placing item in slot: %w", err)
 	}
 
 	
-	if err := s.repo.CreatePlacementLog(ctx, chosenSlotID, req.ItemID, req.BatchID, "genetic_placement"); err != nil {
-		fmt.Printf("Error creating placement log: %v\n", err)
-	}
-
-
 	if err := s.repo.CreatePlacementResponse(ctx, requestID, true, chosenSlotID, "genetic_placement", analyzeResponse.Score, fmt.Sprintf("Item placed successfully in slot %s", chosenSlotID)); err != nil {
 		fmt.Printf("Error creating placement response: %v\n", err)
 	}
 
 	return &domain.PlaceResponse{
-		Success: true,
-		SlotID:  chosenSlotID,
-		Comment: fmt.Sprintf("Item placed successfully in slot %s", chosenSlotID),
-		Score:   analyzeResponse.Score,
+		Success:        true,
+		SlotID:         chosenSlotID,
+		Comment:        fmt.Sprintf("Item placed successfully in slot %s", chosenSlotID),
+		Score:          analyzeResponse.Score,
+		BaseScore:      analyzeResponse.BaseScore,
+		DistanceToExit: analyzeResponse.DistanceToExit,
 	}, nil
 }
 
-func (s *PlacementService) calculateFitness(candidate *domain.PlacementCandidate) float64 {
+// calculateFitness scores a candidate slot. With a warehouse topology the distance is the walking distance
+// from the dock, and slots the topology does not reach get no distance credit
+func (s *PlacementService) calculateFitness(candidate *domain.PlacementCandidate, useTopology bool) float64 {
 
 	maxPossibleDistance := 1000.0
-	normalizedDistance := 1.0 - (float64(candidate.Slot.DistanceFromExit) / maxPossibleDistance)
+	distance := float64(candidate.Slot.DistanceFromExit)
+	if useTopology {
+		distance = maxPossibleDistance
+		if candidate.Slot.TravelDistance != nil {
+			distance = *candidate.Slot.TravelDistance
+		}
+	}
+	normalizedDistance := 1.0 - (distance / maxPossibleDistance)
 	if normalizedDistance < 0 {
 		normalizedDistance = 0
 	}
@@ -181,7 +213,8 @@ func (s *PlacementService) calculateFitness(candidate *domain.PlacementCandidate
 
 
 	itemVolume := candidate.Item.Length * candidate.Item.Width * candidate.Item.Height
-	slotVolume := candidate.Slot.MaxLength * candidate.Slot.MaxWidth * candidate.Slot.MaxHeight
+	// Compare against the space left in the slot, so partially filled slots that still fit rank as tighter fits
+	slotVolume := candidate.Slot.Remaining.Volume
 	if slotVolume > 0 {
 		sizeCompatibility = itemVolume / slotVolume
 	} else {
@@ -200,4 +233,12 @@ func (s *PlacementService) calculateFitness(candidate *domain.PlacementCandidate
 		(s.config.WeightStorageConditions * storageConditionsCompatibility)
 
 	return fitness
-} 
\ No newline at end of file
+}
+
+// HealthCheck verifies that the service can reach its database
+func (s *PlacementService) HealthCheck(ctx context.Context) error {
+	if err := s.repo.Ping(ctx); err != nil {
+		return fmt.Errorf("database is unavailable: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"warehouse/pkg/topology"
+	"warehouse/services/genetic-placement/internal/domain"
+)
+
+// applyTravelDistances sets TravelDistance of the slots reachable from the request's dock and reports
+// whether the warehouse has a topology. An unknown dock yields a rejection response
+func (s *PlacementService) applyTravelDistances(ctx context.Context, req *domain.PlaceRequest, slots []domain.Slot) (bool, *domain.PlaceResponse, error) {
+	distances, err := s.repo.GetTravelDistances(ctx, req.Warehouse(), req.DockID)
+	if errors.Is(err, topology.ErrUnknownDock) {
+		return false, &domain.PlaceResponse{
+			Success: false,
+			Comment: err.Error(),
+			Score:   0,
+		}, nil
+	}
+	if err != nil {
+		return false, nil, fmt.Errorf("error getting travel distances: %w", err)
+	}
+
+	topology.SetTravelDistances(slots, distances)
+	return distances != nil, nil, nil
+}
@@ -0,0 +1,41 @@
+// Package embedded builds the Genetic Placement service on top of a database connection owned by another
+// process, such as the single-binary monolith
+package embedded
+
+import (
+	"database/sql"
+
+	"warehouse/pkg/placement"
+	"warehouse/services/genetic-placement/internal/config"
+	"warehouse/services/genetic-placement/internal/handler"
+	"warehouse/services/genetic-placement/internal/repository"
+	"warehouse/services/genetic-placement/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Embedded is the Genetic Placement service running inside a host process
+type Embedded struct {
+	service *service.PlacementService
+	handler *handler.PlacementHandler
+}
+
+// New wires the service over the shared database connection; algorithm weights come from the
+// same environment variables as in the standalone service
+func New(db *sql.DB) *Embedded {
+	placementService := service.NewPlacementService(repository.NewPostgresRepository(db), config.LoadConfig())
+	return &Embedded{
+		service: placementService,
+		handler: handler.NewPlacementHandler(placementService),
+	}
+}
+
+// Service returns the algorithm for in-process calls
+func (e *Embedded) Service() placement.Service {
+	return e.service
+}
+
+// RegisterRoutes mounts the service HTTP routes on the host router
+func (e *Embedded) RegisterRoutes(router gin.IRouter) {
+	e.handler.RegisterRoutes(router)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"log"
+	"net"
 
 	"warehouse/services/greedy-placement/internal/config"
 	"warehouse/services/greedy-placement/internal/handler"
@@ -10,6 +11,7 @@ import (
 	"warehouse/services/greedy-placement/pkg/database"
 
 	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -36,6 +38,19 @@ func main() {
 	placementService := service.NewPlacementService(repo)
 	placementHandler := handler.NewPlacementHandler(placementService)
 
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		log.Fatalf("Failed to listen for gRPC: %v", err)
+	}
+	grpcServer := grpc.NewServer()
+	handler.NewPlacementGRPCServer(placementService).Register(grpcServer)
+	go func() {
+		log.Printf("Greedy Placement gRPC server starting on :%s", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalf("Failed to run gRPC server: %v", err)
+		}
+	}()
+
 
 	router := gin.Default()
 
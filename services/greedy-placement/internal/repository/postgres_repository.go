@@ -5,6 +5,10 @@ import (
 	"database/sql"
 	"fmt"
 
+	"warehouse/pkg/capacity"
+	"warehouse/pkg/idempotency"
+	"warehouse/pkg/reservation"
+	"warehouse/pkg/topology"
 	"warehouse/services/greedy-placement/internal/domain"
 
 	_ "github.com/lib/pq"
@@ -12,12 +16,18 @@ import (
 
 
 type PostgresRepository struct {
+	// SlotStore and KeyStore provide reservation, placement and idempotency keys shared by all services
+	*reservation.SlotStore
+	*idempotency.KeyStore
+
 	db *sql.DB
+	// distances caches shortest paths over the warehouse topology
+	distances *topology.Cache
 }
 
 
 func NewPostgresRepository(db *sql.DB) *PostgresRepository {
-	return &PostgresRepository{db: db}
+	return &PostgresRepository{SlotStore: reservation.NewSlotStore(db), KeyStore: idempotency.NewKeyStore(db), db: db, distances: topology.NewCache(db, topology.DefaultCacheTTL)}
 }
 
 func (r *PostgresRepository) ItemExists(ctx context.Context, itemID string) (bool, error) {
@@ -34,8 +44,13 @@ func (r *PostgresRepository) BatchExists(ctx context.Context, batchID string) (b
 }
 
 
-func (r *PostgresRepository) GetAllAvailableSlotsOrderedByDistance(ctx context.Context) ([]domain.Slot, error) {
-	rows, err := r.db.QueryContext(ctx, "SELECT slot_id, is_occupied, zone_type, distance_from_exit FROM slots WHERE is_occupied = false ORDER BY distance_from_exit ASC")
+// GetAllAvailableSlotsOrderedByDistance returns unreserved warehouse slots that can take the load, nearest to the exit first
+func (r *PostgresRepository) GetAllAvailableSlotsOrderedByDistance(ctx context.Context, warehouseID string, load capacity.Load) ([]domain.Slot, error) {
+	condition, args := capacity.FitsCondition(load, 2)
+	rows, err := r.db.QueryContext(ctx,
+		"SELECT s.slot_id, s.is_occupied, s.zone_type, s.distance_from_exit, "+capacity.RemainingColumns+" FROM slots s WHERE (s.reserved_until IS NULL OR s.reserved_until < NOW()) AND s.warehouse_id = $1 AND "+condition+" ORDER BY s.distance_from_exit ASC",
+		append([]interface{}{warehouseID}, args...)...,
+	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get all available slots: %w", err)
 	}
@@ -44,7 +59,7 @@ func (r *PostgresRepository) GetAllAvailableSlotsOrderedByDistance(ctx context.C
 	var slots []domain.Slot
 	for rows.Next() {
 		var slot domain.Slot
-		if err := rows.Scan(&slot.SlotID, &slot.IsOccupied, &slot.ZoneType, &slot.DistanceFromExit); err != nil {
+		if err := rows.Scan(&slot.SlotID, &slot.IsOccupied, &slot.ZoneType, &slot.DistanceFromExit, &slot.Remaining.Weight, &slot.Remaining.Volume, &slot.Remaining.Units); err != nil {
 			return nil, fmt.Errorf("failed to scan slot row: %w", err)
 		}
 		slots = append(slots, slot)
@@ -58,16 +73,6 @@ func (r *PostgresRepository) GetAllAvailableSlotsOrderedByDistance(ctx context.C
 }
 
 
-func (r *PostgresRepository) CreatePlacementRequest(ctx context.Context, req *domain.PlaceRequest) (int, error) {
-	var requestID int
-	err := r.db.QueryRowContext(ctx,
-		"INSERT INTO placement_requests (item_id, batch_id, quantity) VALUES ($1, $2, $3) RETURNING request_id",
-		req.ItemID, req.BatchID, req.Quantity,
-	).Scan(&requestID)
-	return requestID, err
-}
-
-
 func (r *PostgresRepository) UpdateSlotOccupation(ctx context.Context, slotID string, isOccupied bool) error {
 	_, err := r.db.ExecContext(ctx, "UPDATE slots SET is_occupied = $1 WHERE slot_id = $2", isOccupied, slotID)
 	return err
@@ -83,10 +88,17 @@ func (r *PostgresRepository) CreatePlacementLog(ctx context.Context, slotID, ite
 }
 
 
-func (r *PostgresRepository) CreatePlacementResponse(ctx context.Context, requestID int, success bool, slotID, algorithm string, score float64, comment string) error {
-	_, err := r.db.ExecContext(ctx,
-		"INSERT INTO placement_responses (request_id, success, slot_id, algorithm_used, score, comment) VALUES ($1, $2, $3, $4, $5, $6)",
-		requestID, success, slotID, algorithm, score, comment,
-	)
-	return err
-} 
\ No newline at end of file
+// GetTravelDistances returns walking distances from the dock (the nearest one if empty) to the slots
+// of the warehouse, or nil if the warehouse has no topology
+func (r *PostgresRepository) GetTravelDistances(ctx context.Context, warehouseID, dockID string) (map[string]float64, error) {
+	distances, err := r.distances.Distances(ctx, warehouseID)
+	if err != nil || distances == nil {
+		return nil, err
+	}
+	return distances.FromDock(dockID)
+}
+
+func (r *PostgresRepository) Ping(ctx context.Context) error {
+	return r.db.PingContext(ctx)
+}
+
@@ -4,34 +4,48 @@ import (
 	"context"
 	"fmt"
 
+	"warehouse/pkg/reservation"
 	"warehouse/services/greedy-placement/internal/domain"
 	"warehouse/services/greedy-placement/internal/repository"
 )
 
 
 type PlacementService struct {
-	repo repository.Repository
+	repo         repository.Repository
+	reservations *reservation.Manager
 }
 
 
 func NewPlacementService(repo repository.Repository) *PlacementService {
-	return &PlacementService{repo: repo}
+	s := &PlacementService{repo: repo}
+	s.reservations = reservation.NewManager(repo, "greedy_placement", s.Candidates)
+	return s
 }
 
 
 func (s *PlacementService) AnalyzePlacement(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
+	candidates, rejection, err := s.RankCandidates(ctx, req, 1)
+	if err != nil || rejection != nil {
+		return rejection, err
+	}
+	return &candidates[0], nil
+}
+
+// RankCandidates returns up to limit available slots ordered by distance to the exit (limit <= 0 means all).
+// If no slot is available, it returns a rejection response explaining why
+func (s *PlacementService) RankCandidates(ctx context.Context, req *domain.PlaceRequest, limit int) ([]domain.PlaceResponse, *domain.PlaceResponse, error) {
 	// Check if item and batch exist (optional for greedy, but good practice)
 	itemExists, err := s.repo.ItemExists(ctx, req.ItemID)
 	if err != nil {
-		return nil, fmt.Errorf("error checking item existence: %w", err)
+		return nil, nil, fmt.Errorf("error checking item existence: %w", err)
 	}
 	batchExists, err := s.repo.BatchExists(ctx, req.BatchID)
 	if err != nil {
-		return nil, fmt.Errorf("error checking batch existence: %w", err)
+		return nil, nil, fmt.Errorf("error checking batch existence: %w", err)
 	}
 
 	if !itemExists || !batchExists {
-		return &domain.PlaceResponse{
+		return nil, &domain.PlaceResponse{
 			Success: false,
 			Comment: "Item or batch not found",
 			Score:   0,
@@ -39,30 +53,47 @@ func (s *PlacementService) AnalyzePlacement(ctx context.Context, req *domain.Pla
 	}
 
 
-	slots, err := s.repo.GetAllAvailableSlotsOrderedByDistance(ctx)
+	load, err := s.repo.ResolveLoad(ctx, req.ItemID, req.BatchID, req.Quantity)
 	if err != nil {
-		return nil, fmt.Errorf("error getting available slots: %w", err)
+		return nil, nil, fmt.Errorf("error resolving load: %w", err)
 	}
 
+	slots, err := s.repo.GetAllAvailableSlotsOrderedByDistance(ctx, req.Warehouse(), load)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting available slots: %w", err)
+	}
+	if rejection, err := s.orderSlots(ctx, req, slots); err != nil || rejection != nil {
+		return nil, rejection, err
+	}
 
-	var suggestedSlotID string
-	if len(slots) > 0 {
-		suggestedSlotID = slots[0].SlotID
-		return &domain.PlaceResponse{
-			Success: true,
-			SlotID:  suggestedSlotID,
-			Comment: fmt.Sprintf("Suggested placement in the closest available slot %s (Zone: %s, Distance: %d)", suggestedSlotID, slots[0].ZoneType, slots[0].DistanceFromExit),
-			Score:   1.0,
+
+	if len(slots) == 0 {
+		return nil, &domain.PlaceResponse{
+			Success: false,
+			Comment: "No available slots found",
+			Score:   0,
 		}, nil
 	}
 
-	return &domain.PlaceResponse{
-		Success: false,
-		Comment: "No available slots found",
-		Score:   0,
-	}, nil
+	if limit > 0 && len(slots) > limit {
+		slots = slots[:limit]
+	}
+
+	candidates := make([]domain.PlaceResponse, 0, len(slots))
+	for _, slot := range slots {
+		candidates = append(candidates, domain.PlaceResponse{
+			Success:        true,
+			SlotID:         slot.SlotID,
+			Comment:        fmt.Sprintf("Suggested placement in the closest available slot %s (Zone: %s, %s)", slot.SlotID, slot.ZoneType, describeDistance(slot)),
+			Score:          1.0,
+			BaseScore:      1.0,
+			DistanceToExit: slot.ExitDistance(),
+		})
+	}
+	return candidates, nil, nil
 }
 
+
 func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
 
 	requestID, err := s.repo.CreatePlacementRequest(ctx, req)
@@ -93,7 +124,12 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 	}
 
 
-	slots, err := s.repo.GetAllAvailableSlotsOrderedByDistance(ctx)
+	load, err := s.repo.ResolveLoad(ctx, req.ItemID, req.BatchID, req.Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving load: %w", err)
+	}
+
+	slots, err := s.repo.GetAllAvailableSlotsOrderedByDistance(ctx, req.Warehouse(), load)
 	if err != nil {
 		// Log the placement response
 		if err := s.repo.CreatePlacementResponse(ctx, requestID, false, "", "greedy_placement", 0, fmt.Sprintf("Error getting available slots: %v", err)); err != nil {
@@ -101,6 +137,16 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 		}
 		return nil, fmt.Errorf("error getting available slots: %w", err)
 	}
+	rejection, err := s.orderSlots(ctx, req, slots)
+	if err != nil {
+		return nil, err
+	}
+	if rejection != nil {
+		if err := s.repo.CreatePlacementResponse(ctx, requestID, false, "", "greedy_placement", 0, rejection.Comment); err != nil {
+			fmt.Printf("Error creating placement response for unknown dock: %v\n", err)
+		}
+		return rejection, nil
+	}
 
 
 	var chosenSlotID string
@@ -108,31 +154,28 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 		chosenSlotID = slots[0].SlotID
 
 
-		if err := s.repo.UpdateSlotOccupation(ctx, chosenSlotID, true); err != nil {
+		if err := s.repo.PlaceInSlot(ctx, chosenSlotID, req.ItemID, req.BatchID, "greedy_placement", req.Quantity); err != nil {
 
-			if err := s.repo.CreatePlacementResponse(ctx, requestID, false, chosenSlotID, "greedy_placement", 0, fmt.Sprintf("Error updating slot occupation: %v", err)); err != nil {
+			if err := s.repo.CreatePlacementResponse(ctx, requestID, false, chosenSlotID, "greedy_placement", 0, fmt.Sprintf("Error placing item in slot: %v", err)); err != nil {
 				fmt.Printf("Error creating placement response for occupation error: %v\n", err)
 			}
-			return nil, fmt.Errorf("error updating slot occupation: %w", err)
+			return nil, fmt.Errorf("error placing item in slot: %w", err)
 		}
 
 
-		if err := s.repo.CreatePlacementLog(ctx, chosenSlotID, req.ItemID, req.BatchID, "greedy_placement"); err != nil {
-
-			fmt.Printf("Error creating placement log: %v\n", err)
-		}
-
 		// Create placement response
-		if err := s.repo.CreatePlacementResponse(ctx, requestID, true, chosenSlotID, "greedy_placement", 1.0, fmt.Sprintf("Item placed in slot %s (Zone: %s, Distance: %d)", chosenSlotID, slots[0].ZoneType, slots[0].DistanceFromExit)); err != nil {
+		if err := s.repo.CreatePlacementResponse(ctx, requestID, true, chosenSlotID, "greedy_placement", 1.0, fmt.Sprintf("Item placed in slot %s (Zone: %s, %s)", chosenSlotID, slots[0].ZoneType, describeDistance(slots[0]))); err != nil {
 
 			fmt.Printf("Error creating placement response: %v\n", err)
 		}
 
 		return &domain.PlaceResponse{
-			Success: true,
-			SlotID:  chosenSlotID,
-			Comment: fmt.Sprintf("Item placed successfully in slot %s", chosenSlotID),
-			Score:   1.0,
+			Success:        true,
+			SlotID:         chosenSlotID,
+			Comment:        fmt.Sprintf("Item placed successfully in slot %s", chosenSlotID),
+			Score:          1.0,
+			BaseScore:      1.0,
+			DistanceToExit: slots[0].ExitDistance(),
 		}, nil
 	}
 
@@ -146,4 +189,12 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 		Comment: "No available slots found for placement",
 		Score:   0,
 	}, nil
-} 
\ No newline at end of file
+}
+
+// HealthCheck verifies that the service can reach its database
+func (s *PlacementService) HealthCheck(ctx context.Context) error {
+	if err := s.repo.Ping(ctx); err != nil {
+		return fmt.Errorf("database is unavailable: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+// Package embedded builds the Greedy Placement service on top of a database connection owned by another
+// process, such as the single-binary monolith
+package embedded
+
+import (
+	"database/sql"
+
+	"warehouse/pkg/placement"
+	"warehouse/services/greedy-placement/internal/handler"
+	"warehouse/services/greedy-placement/internal/repository"
+	"warehouse/services/greedy-placement/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Embedded is the Greedy Placement service running inside a host process
+type Embedded struct {
+	service *service.PlacementService
+	handler *handler.PlacementHandler
+}
+
+// New wires the service over the shared database connection
+func New(db *sql.DB) *Embedded {
+	placementService := service.NewPlacementService(repository.NewPostgresRepository(db))
+	return &Embedded{
+		service: placementService,
+		handler: handler.NewPlacementHandler(placementService),
+	}
+}
+
+// Service returns the algorithm for in-process calls
+func (e *Embedded) Service() placement.Service {
+	return e.service
+}
+
+// RegisterRoutes mounts the service HTTP routes on the host router
+func (e *Embedded) RegisterRoutes(router gin.IRouter) {
+	e.handler.RegisterRoutes(router)
+}
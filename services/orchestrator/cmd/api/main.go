@@ -1,11 +1,12 @@
 package main
 
 import (
+	"context"
 	"log"
 
+	"warehouse/services/orchestrator/internal/app"
 	"warehouse/services/orchestrator/internal/config"
-	"warehouse/services/orchestrator/internal/handler"
-	"warehouse/services/orchestrator/internal/service"
+	"warehouse/services/orchestrator/pkg/database"
 
 	"github.com/gin-gonic/gin"
 )
@@ -15,18 +16,30 @@ func main() {
 	cfg := config.NewConfig()
 
 
-	orchestratorService := service.NewOrchestratorService(cfg)
-
+	dbConfig := &database.Config{
+		Host:     cfg.DBHost,
+		Port:     cfg.DBPortInt,
+		User:     cfg.DBUser,
+		Password: cfg.DBPassword,
+		DBName:   cfg.DBName,
+		SSLMode:  "disable",
+	}
 
-	orchestratorHandler := handler.NewOrchestratorHandler(orchestratorService)
+	db, err := database.NewPostgresConnection(dbConfig)
+	if err != nil {
+		log.Fatalf("Ошибка подключения к базе данных: %v", err)
+	}
+	defer db.Close()
 
 
 	router := gin.Default()
-	orchestratorHandler.RegisterRoutes(router)
+	if err := app.Start(context.Background(), cfg, db, nil, router); err != nil {
+		log.Fatalf("Ошибка запуска оркестратора: %v", err)
+	}
 
 
 	log.Println("Orchestrator Service запущен на :8086")
 	if err := router.Run(":8086"); err != nil {
 		log.Fatalf("Ошибка запуска сервера: %v", err)
 	}
-} 
\ No newline at end of file
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"warehouse/pkg/placement/placementtest"
+	abc "warehouse/services/abc-placement/pkg/embedded"
+	fixed "warehouse/services/fixed-placement/pkg/embedded"
+	free "warehouse/services/free-placement/pkg/embedded"
+	genetic "warehouse/services/genetic-placement/pkg/embedded"
+	greedy "warehouse/services/greedy-placement/pkg/embedded"
+	"warehouse/services/orchestrator/internal/config"
+	"warehouse/services/orchestrator/pkg/database"
+	xyz "warehouse/services/xyz-placement/pkg/embedded"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routedService - сервис размещения, чьи HTTP-маршруты проверяются
+type routedService interface {
+	RegisterRoutes(router gin.IRouter)
+}
+
+// Проверка контракта: обработчик каждого сервиса размещения прогоняется через набор placementtest.
+// Набор только читает базу, поэтому его можно запускать на любой базе со схемой склада.
+// Код возврата 1 означает, что хотя бы один сервис нарушает контракт
+func main() {
+	cfg := config.NewConfig()
+
+	dbConfig := &database.Config{
+		Host:     cfg.DBHost,
+		Port:     cfg.DBPortInt,
+		User:     cfg.DBUser,
+		Password: cfg.DBPassword,
+		DBName:   cfg.DBName,
+		SSLMode:  "disable",
+	}
+
+	db, err := database.NewPostgresConnection(dbConfig)
+	if err != nil {
+		log.Fatalf("Ошибка подключения к базе данных: %v", err)
+	}
+	defer db.Close()
+
+	services := []struct {
+		id      string
+		path    string
+		service routedService
+	}{
+		{"abc", "/api/v1/abc-placement", abc.New(db)},
+		{"fixed", "/api/v1/fixed-placement", fixed.New(db)},
+		{"free", "/process-placement", free.New(db)},
+		{"genetic", "/api/v1/genetic-placement", genetic.New(db)},
+		{"greedy", "/api/v1/greedy-placement", greedy.New(db)},
+		{"xyz", "/api/v1/xyz-placement", xyz.New(db)},
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	failed := false
+	for _, s := range services {
+		router := gin.New()
+		s.service.RegisterRoutes(router)
+
+		if err := placementtest.Check(router, s.path); err != nil {
+			failed = true
+			log.Printf("FAIL %s:\n%v", s.id, err)
+			continue
+		}
+		log.Printf("ok   %s", s.id)
+	}
+
+	if failed {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"warehouse/pkg/placement"
+	abc "warehouse/services/abc-placement/pkg/embedded"
+	fixed "warehouse/services/fixed-placement/pkg/embedded"
+	free "warehouse/services/free-placement/pkg/embedded"
+	genetic "warehouse/services/genetic-placement/pkg/embedded"
+	greedy "warehouse/services/greedy-placement/pkg/embedded"
+	"warehouse/services/orchestrator/internal/app"
+	"warehouse/services/orchestrator/internal/config"
+	"warehouse/services/orchestrator/pkg/database"
+	xyz "warehouse/services/xyz-placement/pkg/embedded"
+
+	"github.com/gin-gonic/gin"
+)
+
+// embeddedService - алгоритм, встроенный в монолит
+type embeddedService interface {
+	Service() placement.Service
+	RegisterRoutes(router gin.IRouter)
+}
+
+// Монолит: оркестратор и все алгоритмы размещения в одном процессе, на одном порту
+// и с одним подключением к базе данных
+func main() {
+	cfg := config.NewMonolithConfig()
+
+	dbConfig := &database.Config{
+		Host:     cfg.DBHost,
+		Port:     cfg.DBPortInt,
+		User:     cfg.DBUser,
+		Password: cfg.DBPassword,
+		DBName:   cfg.DBName,
+		SSLMode:  "disable",
+	}
+
+	db, err := database.NewPostgresConnection(dbConfig)
+	if err != nil {
+		log.Fatalf("Ошибка подключения к базе данных: %v", err)
+	}
+	defer db.Close()
+
+	embedded := map[string]embeddedService{
+		"abc":     abc.New(db),
+		"fixed":   fixed.New(db),
+		"free":    free.New(db),
+		"genetic": genetic.New(db),
+		"greedy":  greedy.New(db),
+		"xyz":     xyz.New(db),
+	}
+
+	router := gin.Default()
+
+	// Маршруты сервисов доступны под /placement/<id>, например POST /placement/abc/api/v1/abc-placement
+	local := make(map[string]placement.Service, len(embedded))
+	for serviceID, service := range embedded {
+		local[serviceID] = service.Service()
+		service.RegisterRoutes(router.Group("/placement/" + serviceID))
+	}
+
+	if err := app.Start(context.Background(), cfg, db, local, router); err != nil {
+		log.Fatalf("Ошибка запуска оркестратора: %v", err)
+	}
+
+	for serviceID, serviceCfg := range cfg.Services {
+		log.Printf("Алгоритм %s: транспорт %s", serviceID, serviceCfg.Transport)
+	}
+
+	log.Println("Монолит запущен на :8086")
+	if err := router.Run(":8086"); err != nil {
+		log.Fatalf("Ошибка запуска сервера: %v", err)
+	}
+}
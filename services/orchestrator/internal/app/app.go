@@ -0,0 +1,75 @@
+// Package app собирает оркестратор: сервис, фоновые обработчики и HTTP-маршруты. Используется
+// отдельным оркестратором и монолитом, в который встроены все алгоритмы
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+
+	"warehouse/pkg/outbox"
+	"warehouse/pkg/placement"
+	"warehouse/services/orchestrator/internal/config"
+	"warehouse/services/orchestrator/internal/handler"
+	"warehouse/services/orchestrator/internal/repository"
+	"warehouse/services/orchestrator/internal/scoring"
+	"warehouse/services/orchestrator/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Start создает оркестратор поверх db, запускает проверку здоровья сервисов, обработчики заданий
+// и доставку событий и подключает маршруты оркестратора к router. local - встроенные алгоритмы
+func Start(ctx context.Context, cfg *config.Config, db *sql.DB, local map[string]placement.Service, router gin.IRouter) error {
+	policies, err := scoring.NewStore(cfg.ScoringPolicyPath)
+	if err != nil {
+		return fmt.Errorf("ошибка загрузки политики скоринга: %w", err)
+	}
+	log.Printf("Политика скоринга: версия %s", policies.Current().Version)
+
+	publisher, err := newEventPublisher(cfg)
+	if err != nil {
+		return err
+	}
+
+	repo := repository.NewPostgresRepository(db)
+	orchestratorService := service.NewOrchestratorService(cfg, policies, repo, local)
+	go orchestratorService.StartHealthChecks(ctx)
+	go orchestratorService.StartJobWorkers(ctx)
+
+	if publisher != nil {
+		relay := outbox.NewRelay(db, publisher, outbox.RelayConfig{
+			PollInterval: cfg.OutboxPollInterval,
+			MaxAttempts:  cfg.OutboxMaxAttempts,
+			RetryBackoff: time.Second,
+		})
+		go relay.Run(ctx)
+		log.Printf("Доставка событий размещения: %s", cfg.EventPublisher)
+	}
+
+	handler.NewOrchestratorHandler(orchestratorService).RegisterRoutes(router)
+	return nil
+}
+
+// newEventPublisher выбирает получателя событий размещения; nil - события остаются в outbox
+func newEventPublisher(cfg *config.Config) (outbox.Publisher, error) {
+	switch cfg.EventPublisher {
+	case "webhook":
+		if cfg.WebhookURL == "" {
+			return nil, fmt.Errorf("EVENT_PUBLISHER=webhook требует WEBHOOK_URL")
+		}
+		return outbox.NewWebhookPublisher(outbox.WebhookConfig{
+			URL:          cfg.WebhookURL,
+			Secret:       cfg.WebhookSecret,
+			Timeout:      5 * time.Second,
+			MaxRetries:   cfg.WebhookMaxRetries,
+			RetryBackoff: 500 * time.Millisecond,
+		}), nil
+	case "", "none":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("неизвестный EVENT_PUBLISHER: %s", cfg.EventPublisher)
+	}
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// Состояния автоматического выключателя
+const (
+	StateClosed   = "closed"
+	StateOpen     = "open"
+	StateHalfOpen = "half-open"
+)
+
+// ErrCircuitOpen возвращается, когда запрос к сервису не отправлен из-за разомкнутой цепи
+var ErrCircuitOpen = errors.New("цепь разомкнута, сервис временно исключен из опроса")
+
+// CircuitBreaker размыкает цепь после failureThreshold ошибок подряд и через openTimeout
+// пропускает один пробный запрос (half-open), по результату которого цепь замыкается или снова размыкается
+type CircuitBreaker struct {
+	mu               sync.Mutex
+	state            string
+	failures         int
+	openedAt         time.Time
+	probeInFlight    bool
+	failureThreshold int
+	openTimeout      time.Duration
+}
+
+// NewCircuitBreaker создает замкнутый выключатель
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = 1
+	}
+	return &CircuitBreaker{
+		state:            StateClosed,
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+	}
+}
+
+// Allow сообщает, можно ли отправить запрос; в состоянии half-open пропускает только один пробный запрос
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance()
+
+	switch b.state {
+	case StateClosed:
+		return true
+	case StateHalfOpen:
+		if b.probeInFlight {
+			return false
+		}
+		b.probeInFlight = true
+		return true
+	default:
+		return false
+	}
+}
+
+// RecordSuccess замыкает цепь и сбрасывает счетчик ошибок
+func (b *CircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = StateClosed
+	b.failures = 0
+	b.probeInFlight = false
+}
+
+// RecordFailure учитывает ошибку; неудачный пробный запрос сразу размыкает цепь
+func (b *CircuitBreaker) RecordFailure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	if b.state == StateHalfOpen || b.failures >= b.failureThreshold {
+		b.state = StateOpen
+		b.openedAt = time.Now()
+	}
+	b.probeInFlight = false
+}
+
+// RecordCanceled освобождает слот пробного запроса, если запрос был отменен вызывающей стороной
+// и не говорит ничего о состоянии сервиса
+func (b *CircuitBreaker) RecordCanceled() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probeInFlight = false
+}
+
+// RecordHealthy переводит разомкнутую цепь в half-open после успешной проверки /healthz,
+// не дожидаясь истечения openTimeout
+func (b *CircuitBreaker) RecordHealthy() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen {
+		b.state = StateHalfOpen
+		b.probeInFlight = false
+	}
+}
+
+// State возвращает текущее состояние выключателя
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.advance()
+	return b.state
+}
+
+// advance переводит разомкнутую цепь в half-open по истечении openTimeout
+func (b *CircuitBreaker) advance() {
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.openTimeout {
+		b.state = StateHalfOpen
+		b.probeInFlight = false
+	}
+}
@@ -0,0 +1,146 @@
+package client
+
+import (
+	"testing"
+	"time"
+)
+
+// Действия над выключателем в шагах теста
+const (
+	opAllow    = "allow"
+	opSuccess  = "success"
+	opFailure  = "failure"
+	opCanceled = "canceled"
+	opHealthy  = "healthy"
+)
+
+// breakerStep - действие и ожидаемое состояние после него; allowed проверяется только для opAllow
+type breakerStep struct {
+	op      string
+	allowed bool
+	state   string
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	tests := []struct {
+		name        string
+		threshold   int
+		openTimeout time.Duration
+		steps       []breakerStep
+	}{
+		{
+			name:        "размыкается после порога ошибок подряд",
+			threshold:   3,
+			openTimeout: time.Hour,
+			steps: []breakerStep{
+				{op: opFailure, state: StateClosed},
+				{op: opFailure, state: StateClosed},
+				{op: opAllow, allowed: true, state: StateClosed},
+				{op: opFailure, state: StateOpen},
+				{op: opAllow, allowed: false, state: StateOpen},
+			},
+		},
+		{
+			name:        "успех сбрасывает счетчик ошибок",
+			threshold:   2,
+			openTimeout: time.Hour,
+			steps: []breakerStep{
+				{op: opFailure, state: StateClosed},
+				{op: opSuccess, state: StateClosed},
+				{op: opFailure, state: StateClosed},
+				{op: opFailure, state: StateOpen},
+			},
+		},
+		{
+			name:        "нулевой порог размыкает после первой ошибки",
+			threshold:   0,
+			openTimeout: time.Hour,
+			steps: []breakerStep{
+				{op: opFailure, state: StateOpen},
+			},
+		},
+		{
+			name:        "после openTimeout пропускается один пробный запрос",
+			threshold:   1,
+			openTimeout: 0,
+			steps: []breakerStep{
+				{op: opFailure, state: StateHalfOpen},
+				{op: opAllow, allowed: true, state: StateHalfOpen},
+				{op: opAllow, allowed: false, state: StateHalfOpen},
+			},
+		},
+		{
+			name:        "удачный пробный запрос замыкает цепь",
+			threshold:   1,
+			openTimeout: 0,
+			steps: []breakerStep{
+				{op: opFailure, state: StateHalfOpen},
+				{op: opAllow, allowed: true, state: StateHalfOpen},
+				{op: opSuccess, state: StateClosed},
+				{op: opAllow, allowed: true, state: StateClosed},
+			},
+		},
+		{
+			name:        "неудачный пробный запрос снова размыкает цепь",
+			threshold:   5,
+			openTimeout: time.Hour,
+			steps: []breakerStep{
+				{op: opFailure, state: StateClosed},
+				{op: opFailure, state: StateClosed},
+				{op: opFailure, state: StateClosed},
+				{op: opFailure, state: StateClosed},
+				{op: opFailure, state: StateOpen},
+				{op: opHealthy, state: StateHalfOpen},
+				{op: opAllow, allowed: true, state: StateHalfOpen},
+				{op: opFailure, state: StateOpen},
+				{op: opAllow, allowed: false, state: StateOpen},
+			},
+		},
+		{
+			name:        "отмененный пробный запрос освобождает место для следующего",
+			threshold:   1,
+			openTimeout: time.Hour,
+			steps: []breakerStep{
+				{op: opFailure, state: StateOpen},
+				{op: opHealthy, state: StateHalfOpen},
+				{op: opAllow, allowed: true, state: StateHalfOpen},
+				{op: opCanceled, state: StateHalfOpen},
+				{op: opAllow, allowed: true, state: StateHalfOpen},
+			},
+		},
+		{
+			name:        "проверка /healthz не трогает замкнутую цепь",
+			threshold:   1,
+			openTimeout: time.Hour,
+			steps: []breakerStep{
+				{op: opHealthy, state: StateClosed},
+				{op: opAllow, allowed: true, state: StateClosed},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker := NewCircuitBreaker(tt.threshold, tt.openTimeout)
+			for i, step := range tt.steps {
+				switch step.op {
+				case opAllow:
+					if allowed := breaker.Allow(); allowed != step.allowed {
+						t.Fatalf("шаг %d (%s): Allow() = %v, ожидалось %v", i+1, step.op, allowed, step.allowed)
+					}
+				case opSuccess:
+					breaker.RecordSuccess()
+				case opFailure:
+					breaker.RecordFailure()
+				case opCanceled:
+					breaker.RecordCanceled()
+				case opHealthy:
+					breaker.RecordHealthy()
+				}
+				if state := breaker.State(); state != step.state {
+					t.Fatalf("шаг %d (%s): состояние %s, ожидалось %s", i+1, step.op, state, step.state)
+				}
+			}
+		})
+	}
+}
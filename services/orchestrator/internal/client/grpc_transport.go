@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"warehouse/pkg/placement"
+	"warehouse/pkg/placement/placementgrpc"
+	placementv1 "warehouse/proto/placement/v1"
+	"warehouse/services/orchestrator/internal/domain"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcTransport вызывает сервис размещения по контракту placement.v1. Дедлайн контекста
+// передается сервису вместе с запросом, поэтому сервис прекращает работу, когда оркестратор
+// перестает ждать ответа
+type grpcTransport struct {
+	client  placementv1.PlacementServiceClient
+	timeout time.Duration
+	dialErr error
+}
+
+func newGRPCTransport(addr string, timeout time.Duration) *grpcTransport {
+	// Соединение устанавливается лениво при первом вызове, недоступный сервис не мешает запуску оркестратора
+	conn, err := grpc.Dial(addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		return &grpcTransport{dialErr: fmt.Errorf("ошибка подключения к %s: %w", addr, err)}
+	}
+
+	return &grpcTransport{
+		client:  placementv1.NewPlacementServiceClient(conn),
+		timeout: timeout,
+	}
+}
+
+func (t *grpcTransport) send(ctx context.Context, req *placement.Request) (*domain.PlacementResponse, error) {
+	if t.dialErr != nil {
+		return nil, t.dialErr
+	}
+
+	ctx, cancel := t.callContext(ctx, req.Command)
+	defer cancel()
+
+	call := t.client.Analyze
+	switch req.Command {
+	case "place":
+		call = t.client.Place
+	case "reserve":
+		call = t.client.Reserve
+	case "commit":
+		call = t.client.Commit
+	case "release":
+		call = t.client.Release
+	}
+
+	resp, err := call(ctx, placementgrpc.RequestToProto(req))
+	if err != nil {
+		return nil, grpcCallError(err)
+	}
+	return contractResponse(placementgrpc.ResponseFromProto(resp)), nil
+}
+
+func (t *grpcTransport) candidates(ctx context.Context, req *placement.Request, limit int) ([]domain.PlacementResponse, error) {
+	if t.dialErr != nil {
+		return nil, t.dialErr
+	}
+
+	ctx, cancel := t.callContext(ctx, req.Command)
+	defer cancel()
+
+	stream, err := t.client.StreamCandidates(ctx, &placementv1.CandidatesRequest{
+		Request: placementgrpc.RequestToProto(req),
+		Limit:   int32(limit),
+	})
+	if err != nil {
+		return nil, grpcCallError(err)
+	}
+
+	var candidates []domain.PlacementResponse
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return candidates, nil
+		}
+		if err != nil {
+			return nil, grpcCallError(err)
+		}
+		candidates = append(candidates, *contractResponse(placementgrpc.ResponseFromProto(resp)))
+	}
+}
+
+// callContext ограничивает вызов таймаутом сервиса и передает ключ идемпотентности в метаданных
+// idempotency-key с суффиксом команды, как это делает HTTP-транспорт в заголовке Idempotency-Key
+func (t *grpcTransport) callContext(ctx context.Context, command string) (context.Context, context.CancelFunc) {
+	if key := IdempotencyKey(ctx); key != "" && command != "analyze" {
+		ctx = metadata.AppendToOutgoingContext(ctx, placementgrpc.IdempotencyKeyMetadata, key+":"+command)
+	}
+	if t.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, t.timeout)
+}
+
+// grpcCallError переводит статус gRPC в ошибку клиента. Ошибки в самом запросе, как и ответы 4xx
+// по HTTP, помечаются errClientSide и не размыкают цепь
+func grpcCallError(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return fmt.Errorf("ошибка отправки запроса: %w", err)
+	}
+
+	switch st.Code() {
+	case codes.InvalidArgument, codes.FailedPrecondition, codes.Aborted, codes.NotFound:
+		return fmt.Errorf("%w: %s: %s", errClientSide, st.Code(), st.Message())
+	case codes.Canceled:
+		return fmt.Errorf("запрос отменен: %w", context.Canceled)
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("истек срок ожидания ответа: %w", context.DeadlineExceeded)
+	default:
+		return fmt.Errorf("ошибка сервера: %s: %s", st.Code(), st.Message())
+	}
+}
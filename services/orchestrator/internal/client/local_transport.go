@@ -0,0 +1,94 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"warehouse/pkg/placement"
+	"warehouse/services/orchestrator/internal/config"
+	"warehouse/services/orchestrator/internal/domain"
+)
+
+// NewLocalPlacementClient создает клиента, который вызывает алгоритм в процессе оркестратора.
+// Выключатель, таймаут и ключи идемпотентности работают так же, как для удаленного сервиса
+func NewLocalPlacementClient(cfg config.ServiceConfig, service placement.Service) *PlacementClient {
+	return &PlacementClient{
+		breaker:   NewCircuitBreaker(cfg.FailureThreshold, cfg.OpenTimeout),
+		transport: &localTransport{service: service, timeout: cfg.Timeout},
+	}
+}
+
+// localTransport передает запрос алгоритму напрямую, без сериализации
+type localTransport struct {
+	service placement.Service
+	timeout time.Duration
+}
+
+func (t *localTransport) send(ctx context.Context, req *placement.Request) (*domain.PlacementResponse, error) {
+	ctx, cancel := t.callContext(ctx, req.Command)
+	defer cancel()
+
+	call := t.service.Analyze
+	switch req.Command {
+	case "place":
+		call = t.service.Place
+	case "reserve":
+		call = t.service.Reserve
+	case "commit":
+		call = t.service.Commit
+	case "release":
+		call = t.service.Release
+	}
+
+	resp, err := call(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка алгоритма: %w", err)
+	}
+	return contractResponse(resp), nil
+}
+
+func (t *localTransport) candidates(ctx context.Context, req *placement.Request, limit int) ([]domain.PlacementResponse, error) {
+	ctx, cancel := t.callContext(ctx, req.Command)
+	defer cancel()
+
+	responses, err := t.service.Candidates(ctx, req, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка алгоритма: %w", err)
+	}
+
+	candidates := make([]domain.PlacementResponse, 0, len(responses))
+	for i := range responses {
+		candidates = append(candidates, *contractResponse(&responses[i]))
+	}
+	return candidates, nil
+}
+
+func (t *localTransport) health(ctx context.Context) error {
+	return t.service.HealthCheck(ctx)
+}
+
+// callContext ограничивает вызов таймаутом сервиса и передает ключ идемпотентности с суффиксом команды
+func (t *localTransport) callContext(ctx context.Context, command string) (context.Context, context.CancelFunc) {
+	if key := IdempotencyKey(ctx); key != "" && command != "analyze" {
+		ctx = placement.WithIdempotencyKey(ctx, key+":"+command)
+	}
+	if t.timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, t.timeout)
+}
+
+// contractResponse преобразует ответ по контракту placement в ответ оркестратору
+func contractResponse(resp *placement.Response) *domain.PlacementResponse {
+	return &domain.PlacementResponse{
+		Success:          resp.Success,
+		SlotID:           resp.SlotID,
+		Comment:          resp.Comment,
+		Score:            resp.Score,
+		BaseScore:        resp.BaseScore,
+		DistanceToExit:   resp.DistanceToExit,
+		ReservationToken: resp.ReservationToken,
+		ReservedUntil:    resp.ReservedUntil,
+	}
+}
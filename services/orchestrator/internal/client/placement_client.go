@@ -4,88 +4,212 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"time"
 
+	"warehouse/pkg/placement"
+	"warehouse/services/orchestrator/internal/config"
 	"warehouse/services/orchestrator/internal/domain"
 )
 
 type PlacementClient struct {
-	client  *http.Client
-	baseURL string
+	client    *http.Client
+	healthURL string
+	breaker   *CircuitBreaker
+	transport placementTransport
+}
+
+// placementTransport доставляет запрос сервису размещения по выбранному протоколу
+type placementTransport interface {
+	send(ctx context.Context, req *placement.Request) (*domain.PlacementResponse, error)
+	candidates(ctx context.Context, req *placement.Request, limit int) ([]domain.PlacementResponse, error)
 }
 
+// ErrStreamingUnsupported означает, что транспорт сервиса не поддерживает потоковую выдачу кандидатов
+var ErrStreamingUnsupported = errors.New("потоковая выдача кандидатов доступна только по gRPC")
+
+
+func NewPlacementClient(cfg config.ServiceConfig) *PlacementClient {
+	httpClient := &http.Client{
+		Timeout: cfg.Timeout,
+	}
+
+	var transport placementTransport = &httpTransport{client: httpClient, baseURL: cfg.URL}
+	if cfg.Transport == config.TransportGRPC {
+		transport = newGRPCTransport(cfg.GRPCAddr, cfg.Timeout)
+	}
 
-func NewPlacementClient(baseURL string) *PlacementClient {
 	return &PlacementClient{
-		client: &http.Client{
-			Timeout: 5 * time.Second,
-		},
-		baseURL: baseURL,
+		client:    httpClient,
+		healthURL: cfg.HealthURL,
+		breaker:   NewCircuitBreaker(cfg.FailureThreshold, cfg.OpenTimeout),
+		transport: transport,
 	}
 }
 
+// CircuitState возвращает состояние автоматического выключателя сервиса
+func (c *PlacementClient) CircuitState() string {
+	return c.breaker.State()
+}
+
+// CheckHealth опрашивает /healthz сервиса (встроенный алгоритм проверяется напрямую); успешная проверка переводит разомкнутую цепь в half-open,
+// неуспешная учитывается как ошибка
+func (c *PlacementClient) CheckHealth(ctx context.Context) error {
+	if local, ok := c.transport.(*localTransport); ok {
+		if err := local.health(ctx); err != nil {
+			if ctx.Err() == nil {
+				c.breaker.RecordFailure()
+			}
+			return err
+		}
+		c.breaker.RecordHealthy()
+		return nil
+	}
+	if c.healthURL == "" {
+		return nil
+	}
+
+	request, err := http.NewRequestWithContext(ctx, "GET", c.healthURL, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+
+	response, err := c.client.Do(request)
+	if err != nil {
+		if ctx.Err() == nil {
+			c.breaker.RecordFailure()
+		}
+		return fmt.Errorf("ошибка отправки запроса: %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		c.breaker.RecordFailure()
+		return fmt.Errorf("сервис нездоров: %d", response.StatusCode)
+	}
+
+	c.breaker.RecordHealthy()
+	return nil
+}
+
+
+// newServiceRequest собирает запрос к сервису размещения по общему контракту placement
+func newServiceRequest(req *domain.PlacementRequest, command string) *placement.Request {
+	request := placement.NewRequest(command)
+	request.WarehouseID = req.WarehouseID
+	request.DockID = req.DockID
+	request.ItemID = req.ItemID
+	request.BatchID = req.BatchID
+	request.Quantity = req.Quantity
+
+	request.Weight = req.Weight
+	request.Volume = req.Volume
+	request.TurnoverRate = req.TurnoverRate
+	request.DemandRate = req.DemandRate
+	request.Seasonality = req.Seasonality
+	request.ABCClass = req.ABCClass
+	request.XYZClass = req.XYZClass
+	request.IsHeavy = req.IsHeavy
+	request.IsFragile = req.IsFragile
+	request.IsHazardous = req.IsHazardous
+	request.StorageTemp = req.StorageTemp
+	request.StorageHumidity = req.StorageHumidity
+
+	request.WarehouseLoad = req.WarehouseLoad
+	request.HasFixedSlot = req.HasFixedSlot
+	request.FastAccessZone = req.FastAccessZone
+	return request
+}
+
+// ValidateRequest проверяет, что запрос к оркестратору можно передать сервисам по контракту placement
+func ValidateRequest(req *domain.PlacementRequest) error {
+	return newServiceRequest(req, placement.CommandAnalyze).Validate()
+}
 
 func (c *PlacementClient) AnalyzePlacement(ctx context.Context, req *domain.PlacementRequest) (*domain.PlacementResponse, error) {
+	startTime := time.Now()
+	resp, err := c.sendRequest(ctx, newServiceRequest(req, placement.CommandAnalyze))
+	if err != nil {
+		return nil, err
+	}
+
+	annotateResponse(resp, req, startTime)
+	return resp, nil
+}
 
-	serviceReq := struct {
-		ItemID   string  `json:"item_id"`
-		BatchID  string  `json:"batch_id"`
-		Quantity int     `json:"quantity"`
-		Command  string  `json:"command"`
+// ReservePlacement подбирает ячейку и удерживает ее за запросом на ttl; токен резерва возвращается в ответе
+func (c *PlacementClient) ReservePlacement(ctx context.Context, req *domain.PlacementRequest, ttl time.Duration) (*domain.PlacementResponse, error) {
+	return c.ReserveSlot(ctx, req, "", ttl)
+}
 
-	
-		Weight        float64 `json:"weight"`
-		Volume        float64 `json:"volume"`
-		TurnoverRate  float64 `json:"turnover_rate"`
-		DemandRate    float64 `json:"demand_rate"`
-		Seasonality   float64 `json:"seasonality"`
-		ABCClass      string  `json:"abc_class"`
-		XYZClass      string  `json:"xyz_class"`
-		IsHeavy       bool    `json:"is_heavy"`
-		IsFragile     bool    `json:"is_fragile"`
-		IsHazardous   bool    `json:"is_hazardous"`
-		StorageTemp   float64 `json:"storage_temp"`
-		StorageHumidity float64 `json:"storage_humidity"`
-
-
-		WarehouseLoad float64 `json:"warehouse_load"`
-		HasFixedSlot  bool    `json:"has_fixed_slot"`
-		FastAccessZone bool   `json:"fast_access_zone"`
-	}{
-		ItemID:   req.ItemID,
-		BatchID:  req.BatchID,
-		Quantity: req.Quantity,
-		Command:  "analyze",
-
-
-		Weight:         req.Weight,
-		Volume:         req.Volume,
-		TurnoverRate:   req.TurnoverRate,
-		DemandRate:     req.DemandRate,
-		Seasonality:    req.Seasonality,
-		ABCClass:       req.ABCClass,
-		XYZClass:       req.XYZClass,
-		IsHeavy:        req.IsHeavy,
-		IsFragile:      req.IsFragile,
-		IsHazardous:    req.IsHazardous,
-		StorageTemp:    req.StorageTemp,
-		StorageHumidity: req.StorageHumidity,
-
-
-		WarehouseLoad:  req.WarehouseLoad,
-		HasFixedSlot:   req.HasFixedSlot,
-		FastAccessZone: req.FastAccessZone,
+// ReserveSlot удерживает за запросом ячейку slotID на ttl, если алгоритм считает ее подходящей;
+// пустой slotID - лучшая ячейка алгоритма, как в ReservePlacement
+func (c *PlacementClient) ReserveSlot(ctx context.Context, req *domain.PlacementRequest, slotID string, ttl time.Duration) (*domain.PlacementResponse, error) {
+	serviceReq := newServiceRequest(req, placement.CommandReserve)
+	serviceReq.SlotID = slotID
+	serviceReq.ReservationTTLSeconds = int(ttl.Seconds())
+
+	startTime := time.Now()
+	resp, err := c.sendRequest(ctx, serviceReq)
+	if err != nil {
+		return nil, err
+	}
+
+	annotateResponse(resp, req, startTime)
+	return resp, nil
+}
+
+// CommitReservation размещает товар ровно в ячейку, удерживаемую токеном резерва
+func (c *PlacementClient) CommitReservation(ctx context.Context, req *domain.PlacementRequest, token string) (*domain.PlacementResponse, error) {
+	serviceReq := newServiceRequest(req, placement.CommandCommit)
+	serviceReq.ReservationToken = token
+
+	return c.sendRequest(ctx, serviceReq)
+}
+
+// ReleaseReservation снимает резерв с ячейки
+func (c *PlacementClient) ReleaseReservation(ctx context.Context, req *domain.PlacementRequest, token string) (*domain.PlacementResponse, error) {
+	serviceReq := newServiceRequest(req, placement.CommandRelease)
+	serviceReq.ReservationToken = token
+
+	return c.sendRequest(ctx, serviceReq)
+}
+
+
+func (c *PlacementClient) PlaceItem(ctx context.Context, req *domain.PlacementRequest) (*domain.PlacementResponse, error) {
+	return c.sendRequest(ctx, newServiceRequest(req, placement.CommandPlace))
+}
+
+// StreamCandidates получает от сервиса до limit подходящих ячеек, начиная с лучшей; limit = 0 - без ограничения
+func (c *PlacementClient) StreamCandidates(ctx context.Context, req *domain.PlacementRequest, limit int) ([]domain.PlacementResponse, error) {
+	request := newServiceRequest(req, placement.CommandAnalyze)
+	if err := request.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", errClientSide, err.Error())
+	}
+	if !c.breaker.Allow() {
+		return nil, ErrCircuitOpen
 	}
 
 	startTime := time.Now()
-	resp, err := c.sendRequest(ctx, "/api/v1/placement", serviceReq)
+	candidates, err := c.transport.candidates(ctx, request, limit)
+	c.recordResult(ctx, err)
 	if err != nil {
 		return nil, err
 	}
 
+	for i := range candidates {
+		annotateResponse(&candidates[i], req, startTime)
+	}
+	return candidates, nil
+}
 
+// annotateResponse дополняет ответ сервиса временем ответа и признаками для скоринга, которые
+// следуют из запроса. Признаки, зависящие от предложенной ячейки (no_placement_history,
+// xyz_compliant), оркестратор рассчитывает по данным склада после опроса
+func annotateResponse(resp *domain.PlacementResponse, req *domain.PlacementRequest, startTime time.Time) {
 	resp.ResponseTimeMs = time.Since(startTime).Milliseconds()
 
 
@@ -93,98 +217,107 @@ func (c *PlacementClient) AnalyzePlacement(ctx context.Context, req *domain.Plac
 	resp.HighWarehouseLoad = req.WarehouseLoad > 0.8
 	resp.HighTurnover = req.TurnoverRate > 0.8
 	resp.HeavyItem = req.IsHeavy
-	resp.NoPlacementHistory = false 
 	resp.FastAccessZone = req.FastAccessZone
-	resp.XYZCompliant = req.XYZClass == "X" 
+}
 
-	return resp, nil
+
+type idempotencyKeyContextKey struct{}
+
+// WithIdempotencyKey сохраняет в контексте ключ идемпотентности вызова оркестратора; клиент передает его
+// сервисам в заголовке Idempotency-Key с суффиксом команды, так как reserve, commit и release
+// одного вызова - разные запросы
+func WithIdempotencyKey(ctx context.Context, key string) context.Context {
+	if key == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, idempotencyKeyContextKey{}, key)
 }
 
+// IdempotencyKey возвращает ключ идемпотентности из контекста
+func IdempotencyKey(ctx context.Context) string {
+	key, _ := ctx.Value(idempotencyKeyContextKey{}).(string)
+	return key
+}
 
-func (c *PlacementClient) PlaceItem(ctx context.Context, req *domain.PlacementRequest) (*domain.PlacementResponse, error) {
+func (c *PlacementClient) sendRequest(ctx context.Context, req *placement.Request) (*domain.PlacementResponse, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", errClientSide, err.Error())
+	}
+	if !c.breaker.Allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	resp, err := c.transport.send(ctx, req)
+	c.recordResult(ctx, err)
+
+	return resp, err
+}
+
+// recordResult учитывает исход запроса в выключателе
+func (c *PlacementClient) recordResult(ctx context.Context, err error) {
+	switch {
+	case err == nil:
+		c.breaker.RecordSuccess()
+	case ctx.Err() != nil:
+		c.breaker.RecordCanceled()
+	case errors.Is(err, errClientSide), errors.Is(err, ErrStreamingUnsupported):
+		c.breaker.RecordSuccess()
+	default:
+		c.breaker.RecordFailure()
+	}
+}
+
+// errClientSide помечает ответы 4xx: сервис жив, ошибка в самом запросе, цепь не размыкается
+var errClientSide = errors.New("ошибка в запросе")
+
+// httpTransport отправляет запрос JSON-телом на HTTP-маршрут сервиса
+type httpTransport struct {
+	client  *http.Client
+	baseURL string
+}
 
-	serviceReq := struct {
-		ItemID   string  `json:"item_id"`
-		BatchID  string  `json:"batch_id"`
-		Quantity int     `json:"quantity"`
-		Command  string  `json:"command"`
-
-
-		Weight        float64 `json:"weight"`
-		Volume        float64 `json:"volume"`
-		TurnoverRate  float64 `json:"turnover_rate"`
-		DemandRate    float64 `json:"demand_rate"`
-		Seasonality   float64 `json:"seasonality"`
-		ABCClass      string  `json:"abc_class"`
-		XYZClass      string  `json:"xyz_class"`
-		IsHeavy       bool    `json:"is_heavy"`
-		IsFragile     bool    `json:"is_fragile"`
-		IsHazardous   bool    `json:"is_hazardous"`
-		StorageTemp   float64 `json:"storage_temp"`
-		StorageHumidity float64 `json:"storage_humidity"`
-
-		
-		WarehouseLoad float64 `json:"warehouse_load"`
-		HasFixedSlot  bool    `json:"has_fixed_slot"`
-		FastAccessZone bool   `json:"fast_access_zone"`
-	}{
-		ItemID:   req.ItemID,
-		BatchID:  req.BatchID,
-		Quantity: req.Quantity,
-		Command:  "place",
-
-
-		Weight:         req.Weight,
-		Volume:         req.Volume,
-		TurnoverRate:   req.TurnoverRate,
-		DemandRate:     req.DemandRate,
-		Seasonality:    req.Seasonality,
-		ABCClass:       req.ABCClass,
-		XYZClass:       req.XYZClass,
-		IsHeavy:        req.IsHeavy,
-		IsFragile:      req.IsFragile,
-		IsHazardous:    req.IsHazardous,
-		StorageTemp:    req.StorageTemp,
-		StorageHumidity: req.StorageHumidity,
-
-
-		WarehouseLoad:  req.WarehouseLoad,
-		HasFixedSlot:   req.HasFixedSlot,
-		FastAccessZone: req.FastAccessZone,
-	}
-
-	return c.sendRequest(ctx, "/api/v1/placement", serviceReq)
-}
-
-
-func (c *PlacementClient) sendRequest(ctx context.Context, path string, req interface{}) (*domain.PlacementResponse, error) {
+func (t *httpTransport) candidates(ctx context.Context, req *placement.Request, limit int) ([]domain.PlacementResponse, error) {
+	return nil, ErrStreamingUnsupported
+}
+
+func (t *httpTransport) send(ctx context.Context, req *placement.Request) (*domain.PlacementResponse, error) {
 	jsonData, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка сериализации запроса: %w", err)
 	}
 
 	
-	request, err := http.NewRequestWithContext(ctx, "POST", c.baseURL, bytes.NewBuffer(jsonData))
+	request, err := http.NewRequestWithContext(ctx, "POST", t.baseURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
 	}
 
 	request.Header.Set("Content-Type", "application/json")
+	if key := IdempotencyKey(ctx); key != "" && req.Command != "analyze" {
+		request.Header.Set("Idempotency-Key", key+":"+req.Command)
+	}
 
-	response, err := c.client.Do(request)
+	response, err := t.client.Do(request)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка отправки запроса: %w", err)
 	}
 	defer response.Body.Close()
 
+	if response.StatusCode >= 400 && response.StatusCode < 500 {
+		return nil, fmt.Errorf("%w: %d", errClientSide, response.StatusCode)
+	}
 	if response.StatusCode != http.StatusOK {
 		return nil, fmt.Errorf("ошибка сервера: %d", response.StatusCode)
 	}
 
-	var resp domain.PlacementResponse
-	if err := json.NewDecoder(response.Body).Decode(&resp); err != nil {
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения ответа: %w", err)
+	}
+	resp, err := placement.DecodeResponse(body)
+	if err != nil {
 		return nil, fmt.Errorf("ошибка десериализации ответа: %w", err)
 	}
 
-	return &resp, nil
+	return contractResponse(resp), nil
 } 
\ No newline at end of file
@@ -1,44 +1,226 @@
 package config
 
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type ServiceConfig struct {
-	Name string
-	URL  string
+	Name      string
+	URL       string
+	HealthURL string
+
+	// Transport - протокол запросов к сервису: http, grpc или local (алгоритм встроен в процесс);
+	// проверка здоровья удаленного сервиса всегда идет по HTTP
+	Transport string
+	// GRPCAddr - адрес gRPC-сервера сервиса, используется при Transport = grpc
+	GRPCAddr string
+
+	// Timeout - таймаут HTTP-запроса к сервису
+	Timeout time.Duration
+	// FailureThreshold - число ошибок подряд, после которого цепь размыкается
+	FailureThreshold int
+	// OpenTimeout - время, через которое разомкнутая цепь пропускает пробный запрос
+	OpenTimeout time.Duration
 }
 
+// Транспорты запросов к сервисам размещения
+const (
+	TransportHTTP  = "http"
+	TransportGRPC  = "grpc"
+	TransportLocal = "local"
+)
+
 
 type Config struct {
 	Services map[string]ServiceConfig
+
+	DBHost     string
+	DBPort     string
+	DBPortInt  int
+	DBUser     string
+	DBPassword string
+	DBName     string
+
+	// ScoringPolicyPath - путь к JSON-файлу политики скоринга; пустое значение - политика по умолчанию
+	ScoringPolicyPath string
+
+	// ReservationTTL - время, на которое сервисы удерживают ячейку между reserve и commit
+	ReservationTTL time.Duration
+
+	// HealthCheckInterval - период опроса /healthz сервисов размещения
+	HealthCheckInterval time.Duration
+
+	// MaxPlacementAttempts - сколько кандидатов по рейтингу пробовать, если размещение не удалось
+	MaxPlacementAttempts int
+
+	// ConflictPolicy - что делать, если параметры запроса расходятся со справочником товаров: reject или warn
+	ConflictPolicy string
+
+	// ShadowServices - сервисы в теневом режиме: опрашиваются только командой analyze и не участвуют
+	// в выборе ячейки, их предложения сохраняются для сравнения с реальным решением
+	ShadowServices map[string]bool
+
+	// JobWorkers - число обработчиков асинхронных заданий
+	JobWorkers int
+	// JobPollInterval - как часто свободный обработчик проверяет очередь заданий в базе
+	JobPollInterval time.Duration
+	// InstanceID - имя экземпляра оркестратора, за которым закрепляются взятые задания
+	InstanceID string
+	// JobLeaseTTL - аренда задания: пока задание выполняется, экземпляр продлевает ее; задание
+	// с истекшей арендой считается прерванным и возвращается в очередь
+	JobLeaseTTL time.Duration
+	// JobMaxAttempts - после стольких прерванных выполнений задание переводится в failed
+	JobMaxAttempts int
+
+	// EventPublisher - куда доставлять события размещения из outbox: webhook или none
+	EventPublisher string
+	// WebhookURL и WebhookSecret - адрес получателя событий и ключ подписи HMAC-SHA256
+	WebhookURL    string
+	WebhookSecret string
+	// WebhookMaxRetries - сколько раз повторить запрос к вебхуку в рамках одной попытки доставки
+	WebhookMaxRetries int
+	// OutboxPollInterval - как часто проверять outbox, когда недоставленных событий нет
+	OutboxPollInterval time.Duration
+	// OutboxMaxAttempts - после стольких неудачных попыток событие помечается как недоставленное
+	OutboxMaxAttempts int
 }
 
 
 func NewConfig() *Config {
-	return &Config{
+	return newConfig(TransportHTTP)
+}
+
+// NewMonolithConfig - конфигурация монолита: по умолчанию все алгоритмы вызываются в процессе,
+// PLACEMENT_TRANSPORT или <SERVICE>_TRANSPORT переключают отдельные алгоритмы на удаленный сервис
+func NewMonolithConfig() *Config {
+	return newConfig(TransportLocal)
+}
+
+func newConfig(defaultTransport string) *Config {
+	cfg := &Config{
 		Services: map[string]ServiceConfig{
 			"abc": {
-				Name: "ABC Placement",
-				URL:  "http://localhost:8082/api/v1/abc-placement",
+				Name:      "ABC Placement",
+				URL:       "http://localhost:8082/api/v1/abc-placement",
+				HealthURL: "http://localhost:8082/healthz",
+				GRPCAddr:  "localhost:9082",
 			},
 			"fixed": {
-				Name: "Fixed Placement",
-				URL:  "http://localhost:8080/api/v1/fixed-placement",
+				Name:      "Fixed Placement",
+				URL:       "http://localhost:8080/api/v1/fixed-placement",
+				HealthURL: "http://localhost:8080/healthz",
+				GRPCAddr:  "localhost:9080",
 			},
 			"free": {
-				Name: "Free Placement",
-				URL:  "http://localhost:8081/process-placement",
+				Name:      "Free Placement",
+				URL:       "http://localhost:8081/process-placement",
+				HealthURL: "http://localhost:8081/healthz",
+				GRPCAddr:  "localhost:9081",
 			},
 			"genetic": {
-				Name: "Genetic Placement",
-				URL:  "http://localhost:8085/api/v1/genetic-placement",
+				Name:      "Genetic Placement",
+				URL:       "http://localhost:8085/api/v1/genetic-placement",
+				HealthURL: "http://localhost:8085/healthz",
+				GRPCAddr:  "localhost:9085",
 			},
 			"greedy": {
-				Name: "Greedy Placement",
-				URL:  "http://localhost:8084/api/v1/greedy-placement",
+				Name:      "Greedy Placement",
+				URL:       "http://localhost:8084/api/v1/greedy-placement",
+				HealthURL: "http://localhost:8084/healthz",
+				GRPCAddr:  "localhost:9084",
 			},
 			"xyz": {
-				Name: "XYZ Placement",
-				URL:  "http://localhost:8083/api/v1/xyz-placement",
+				Name:      "XYZ Placement",
+				URL:       "http://localhost:8083/api/v1/xyz-placement",
+				HealthURL: "http://localhost:8083/healthz",
+				GRPCAddr:  "localhost:9083",
 			},
 		},
+		DBHost:               getEnv("DB_HOST", "localhost"),
+		DBPort:               getEnv("DB_PORT", "5432"),
+		DBUser:               getEnv("DB_USER", "postgres"),
+		DBPassword:           getEnv("DB_PASSWORD", "admin"),
+		DBName:               getEnv("DB_NAME", "postgres"),
+		ScoringPolicyPath:    getEnv("SCORING_POLICY_PATH", ""),
+		ReservationTTL:       getEnvSeconds("RESERVATION_TTL_SECONDS", 30),
+		HealthCheckInterval:  getEnvSeconds("HEALTH_CHECK_INTERVAL_SECONDS", 10),
+		MaxPlacementAttempts: getEnvInt("MAX_PLACEMENT_ATTEMPTS", 3),
+		ConflictPolicy:       getEnv("ENRICHMENT_CONFLICT_POLICY", "warn"),
+		JobWorkers:           getEnvInt("JOB_WORKERS", 4),
+		JobPollInterval:      getEnvMillis("JOB_POLL_INTERVAL_MS", time.Second),
+		InstanceID:           getEnv("INSTANCE_ID", defaultInstanceID()),
+		JobLeaseTTL:          getEnvSeconds("JOB_LEASE_SECONDS", 60),
+		JobMaxAttempts:       getEnvInt("JOB_MAX_ATTEMPTS", 3),
+		EventPublisher:       getEnv("EVENT_PUBLISHER", "none"),
+		WebhookURL:           getEnv("WEBHOOK_URL", ""),
+		WebhookSecret:        getEnv("WEBHOOK_SECRET", ""),
+		WebhookMaxRetries:    getEnvInt("WEBHOOK_MAX_RETRIES", 3),
+		OutboxPollInterval:   getEnvMillis("OUTBOX_POLL_INTERVAL_MS", time.Second),
+		OutboxMaxAttempts:    getEnvInt("OUTBOX_MAX_ATTEMPTS", 20),
+	}
+
+	cfg.DBPortInt, _ = strconv.Atoi(cfg.DBPort)
+
+	cfg.ShadowServices = make(map[string]bool)
+	for _, serviceID := range strings.Split(getEnv("SHADOW_SERVICES", ""), ",") {
+		serviceID = strings.TrimSpace(serviceID)
+		if _, ok := cfg.Services[serviceID]; ok {
+			cfg.ShadowServices[serviceID] = true
+		}
+	}
+
+	// Параметры выключателя и транспорт задаются общими переменными окружения и могут быть
+	// переопределены для отдельного сервиса, например GENETIC_BREAKER_FAILURE_THRESHOLD или XYZ_TRANSPORT
+	for serviceID, serviceCfg := range cfg.Services {
+		prefix := strings.ToUpper(serviceID) + "_"
+		serviceCfg.Timeout = getEnvMillis(prefix+"TIMEOUT_MS", getEnvMillis("SERVICE_TIMEOUT_MS", 5*time.Second))
+		serviceCfg.FailureThreshold = getEnvInt(prefix+"BREAKER_FAILURE_THRESHOLD", getEnvInt("BREAKER_FAILURE_THRESHOLD", 3))
+		serviceCfg.OpenTimeout = getEnvSeconds(prefix+"BREAKER_OPEN_SECONDS", getEnvInt("BREAKER_OPEN_SECONDS", 15))
+		serviceCfg.Transport = strings.ToLower(getEnv(prefix+"TRANSPORT", getEnv("PLACEMENT_TRANSPORT", defaultTransport)))
+		serviceCfg.GRPCAddr = getEnv(prefix+"GRPC_ADDR", serviceCfg.GRPCAddr)
+		cfg.Services[serviceID] = serviceCfg
+	}
+
+	return cfg
+}
+
+// defaultInstanceID - имя хоста и PID процесса: так различаются и экземпляры на разных хостах,
+// и несколько оркестраторов на одном
+func defaultInstanceID() string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "orchestrator"
+	}
+	return fmt.Sprintf("%s-%d", hostname, os.Getpid())
+}
+
+func getEnv(key, defaultValue string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return defaultValue
+} 
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil || value <= 0 {
+		return defaultValue
 	}
-} 
\ No newline at end of file
+	return value
+}
+
+func getEnvSeconds(key string, defaultValue int) time.Duration {
+	return time.Duration(getEnvInt(key, defaultValue)) * time.Second
+}
+
+func getEnvMillis(key string, defaultValue time.Duration) time.Duration {
+	millis := getEnvInt(key, 0)
+	if millis == 0 {
+		return defaultValue
+	}
+	return time.Duration(millis) * time.Millisecond
+}
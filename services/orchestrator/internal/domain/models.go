@@ -1,11 +1,20 @@
 package domain
 
+import (
+	"encoding/json"
+	"time"
+
+	"warehouse/pkg/idempotency"
+)
+
 // PlacementRequest представляет запрос на размещение товара
 type PlacementRequest struct {
 	// Основные параметры
-	ItemID   string `json:"item_id"`
-	BatchID  string `json:"batch_id"`
-	Quantity int    `json:"quantity"`
+	WarehouseID string `json:"warehouse_id,omitempty"` // склад размещения; по умолчанию основной
+	DockID      string `json:"dock_id,omitempty"`      // ворота, от которых считается путь; по умолчанию ближайшие
+	ItemID      string `json:"item_id"`
+	BatchID     string `json:"batch_id"`
+	Quantity    int    `json:"quantity"`
 
 	// Параметры товара
 	Weight        float64 `json:"weight"`      
@@ -25,6 +34,37 @@ type PlacementRequest struct {
 	WarehouseLoad float64 `json:"warehouse_load"` 
 	HasFixedSlot  bool    `json:"has_fixed_slot"` 
 	FastAccessZone bool   `json:"fast_access_zone"` 
+
+	// Параметры опроса сервисов
+	DeadlineMs int           `json:"deadline_ms,omitempty"` // общий срок ожидания ответов, мс
+	Quorum     *QuorumPolicy `json:"quorum,omitempty"`      // досрочное завершение опроса
+
+	MaxAttempts int `json:"max_attempts,omitempty"` // число кандидатов, которых пробовать при неудачном размещении
+}
+
+// QuorumPolicy задает условие досрочного завершения опроса: ответили все обязательные
+// сервисы и общее число ответивших не меньше MinResponses
+type QuorumPolicy struct {
+	RequiredServices []string `json:"required_services"`
+	MinResponses     int      `json:"min_responses"`
+}
+
+// IsMet проверяет, выполнено ли условие кворума для полученных результатов;
+// пропущенные из-за разомкнутой цепи сервисы ответившими не считаются
+func (q *QuorumPolicy) IsMet(results []ServiceResult) bool {
+	responded := make(map[string]bool)
+	for _, result := range results {
+		if !result.Skipped && !result.TimedOut {
+			responded[result.ServiceID] = true
+		}
+	}
+
+	for _, serviceID := range q.RequiredServices {
+		if !responded[serviceID] {
+			return false
+		}
+	}
+	return len(responded) >= q.MinResponses
 }
 
 
@@ -37,7 +77,7 @@ type PlacementResponse struct {
 
 	BaseScore          float64 `json:"base_score"`           // S0 - базовая оценка (0.90, 0.75, 0.60, 0.50, 0.00)
 	ResponseTimeMs     int64   `json:"response_time_ms"`     // для расчета Δt
-	DistanceToExit     float64 `json:"distance_to_exit"`     // для расчета Δd
+	DistanceToExit     *float64 `json:"distance_to_exit,omitempty"` // для расчета Δd; nil - сервис расстояние не передал
 	HasFixedSlot      bool    `json:"has_fixed_slot"`       // +0.07
 	HighWarehouseLoad bool    `json:"high_warehouse_load"`  // +0.05
 	HighTurnover      bool    `json:"high_turnover"`        // +0.05
@@ -45,19 +85,626 @@ type PlacementResponse struct {
 	NoPlacementHistory bool   `json:"no_placement_history"` // +0.02
 	FastAccessZone    bool    `json:"fast_access_zone"`     // +0.03
 	XYZCompliant      bool    `json:"xyz_compliant"`        // +0.02
+
+	ReservationToken string     `json:"reservation_token,omitempty"` // токен резерва ячейки (команда reserve)
+	ReservedUntil    *time.Time `json:"reserved_until,omitempty"`
 }
 
 
 type OrchestratorResponse struct {
+	DecisionID  int64            `json:"decision_id,omitempty"` // запись решения в журнале оркестратора
 	Success     bool             `json:"success"`
 	SlotID      string          `json:"slot_id"`
 	Comment     string          `json:"comment"`
 	Score       float64         `json:"score"`
 	Algorithm   string          `json:"algorithm"`
+	PolicyVersion string        `json:"policy_version"`
+	QuorumReached bool          `json:"quorum_reached,omitempty"` // опрос завершен досрочно по кворуму
+	Attempts    []PlacementAttempt `json:"attempts,omitempty"`      // попытки размещения по кандидатам в порядке рейтинга
+	Enrichment  *EnrichmentReport `json:"enrichment,omitempty"`     // какие параметры запроса взяты из справочника
+	SignalSources map[string]string `json:"signal_sources,omitempty"` // откуда взят каждый признак скоринга: data, request или default
 	AllResults  []ServiceResult `json:"all_results"`
+	ShadowResults []ServiceResult `json:"shadow_results,omitempty"` // предложения теневых сервисов, в выборе не участвуют
+}
+
+// PlacementAttempt описывает одну попытку разместить товар в ячейке, зарезервированной кандидатом
+type PlacementAttempt struct {
+	Attempt     int    `json:"attempt"`
+	ServiceID   string `json:"service_id"`
+	ServiceName string `json:"service_name"`
+	SlotID      string `json:"slot_id,omitempty"`
+	Success     bool   `json:"success"`
+	Comment     string `json:"comment"`
 }
 
 type ServiceResult struct {
+	ServiceID   string          `json:"service_id"`
 	ServiceName string          `json:"service_name"`
 	Response    PlacementResponse `json:"response"`
-} 
\ No newline at end of file
+	FinalScore  float64         `json:"final_score"` // итоговая оценка с учетом Δt, Δd и бонусов
+	Rank        int             `json:"rank"`        // место в рейтинге, 1 - лучший
+	Breakdown   *ScoreBreakdown `json:"breakdown,omitempty"`
+
+	CircuitState string `json:"circuit_state,omitempty"` // состояние выключателя сервиса: closed, open, half-open
+	Skipped      bool   `json:"skipped,omitempty"`       // сервис не опрашивался из-за разомкнутой цепи
+	TimedOut     bool   `json:"timed_out,omitempty"`     // ответ не дождались: срок истек или кворум уже набран
+
+	LatencyMs int64  `json:"latency_ms"`      // время вызова сервиса, включая ошибки
+	Error     string `json:"error,omitempty"` // ошибка вызова сервиса
+}
+
+// ServiceHealth описывает доступность сервиса размещения
+type ServiceHealth struct {
+	ServiceID    string `json:"service_id"`
+	ServiceName  string `json:"service_name"`
+	Healthy      bool   `json:"healthy"`
+	CircuitState string `json:"circuit_state"`
+	Error        string `json:"error,omitempty"`
+}
+
+// CandidatesResponse - подходящие ячейки одного сервиса, начиная с лучшей
+type CandidatesResponse struct {
+	ServiceID   string              `json:"service_id"`
+	ServiceName string              `json:"service_name"`
+	Candidates  []PlacementResponse `json:"candidates"`
+	Enrichment  *EnrichmentReport   `json:"enrichment,omitempty"`
+}
+
+// ScoreBreakdown раскладывает итоговую оценку на слагаемые политики скоринга
+type ScoreBreakdown struct {
+	PolicyVersion string             `json:"policy_version"`
+	BaseScore     float64            `json:"base_score"`    // S0
+	ResponseTime  float64            `json:"response_time"` // Δt
+	Distance      float64            `json:"distance"`      // Δd
+	Bonuses       map[string]float64 `json:"bonuses"`
+	Total         float64            `json:"total"`
+}
+
+// Режимы пакетного размещения
+const (
+	BatchModeBestEffort   = "best_effort"    // размещается все, что удалось
+	BatchModeAllOrNothing = "all_or_nothing" // размещение начинается, только если ячейки найдены для всех строк
+)
+
+// BatchPlacementRequest - запрос на размещение нескольких партий
+type BatchPlacementRequest struct {
+	Mode  string             `json:"mode"`
+	Items []PlacementRequest `json:"items" binding:"required"`
+}
+
+// BatchLineResult - результат размещения одной строки пакета
+type BatchLineResult struct {
+	Line      int                `json:"line"`
+	ItemID    string             `json:"item_id"`
+	BatchID   string             `json:"batch_id"`
+	Success   bool               `json:"success"`
+	SlotID    string             `json:"slot_id,omitempty"`
+	Algorithm string             `json:"algorithm,omitempty"`
+	Score     float64            `json:"score"`
+	Comment   string             `json:"comment"`
+	Attempts  []PlacementAttempt `json:"attempts,omitempty"`
+
+	Enrichment *EnrichmentReport `json:"enrichment,omitempty"`
+	DecisionID int64             `json:"decision_id,omitempty"`
+}
+
+// BatchSummary - сводка пакетного размещения
+type BatchSummary struct {
+	Total  int `json:"total"`
+	Placed int `json:"placed"`
+	Failed int `json:"failed"`
+}
+
+// BatchPlacementResponse - ответ на пакетное размещение
+type BatchPlacementResponse struct {
+	Success bool              `json:"success"`
+	Mode    string            `json:"mode"`
+	Summary BatchSummary      `json:"summary"`
+	Lines   []BatchLineResult `json:"lines"`
+}
+
+// IdempotencyRecord - сохраненный отпечаток запроса и ответ на него, см. пакет idempotency
+type IdempotencyRecord = idempotency.Record
+
+// Item - мастер-данные товара из таблицы items
+type Item struct {
+	ItemID            string
+	Name              string
+	ItemType          string
+	Weight            float64
+	Length            float64
+	Width             float64
+	Height            float64
+	StorageConditions string
+	Turnover          float64 // для ABC анализа
+	Mr                float64 // коэффициент вариации спроса для XYZ анализа
+	IsHeavy           bool
+	IsFragile         bool
+	IsHazardous       bool
+	StorageTemp       *float64
+	StorageHumidity   *float64
+}
+
+// Batch - партия товара из таблицы batches
+type Batch struct {
+	BatchID  string
+	ItemID   string
+	Quantity int
+}
+
+// Политики обработки расхождений между запросом и справочником
+const (
+	ConflictPolicyReject = "reject" // запрос отклоняется
+	ConflictPolicyWarn   = "warn"   // используется значение из справочника, расхождение попадает в отчет
+)
+
+// FieldConflict - расхождение значения из запроса со значением из справочника
+type FieldConflict struct {
+	Field    string      `json:"field"`
+	Supplied interface{} `json:"supplied"`
+	Stored   interface{} `json:"stored"`
+}
+
+// EnrichmentReport описывает, как запрос был дополнен мастер-данными
+type EnrichmentReport struct {
+	ConflictPolicy string          `json:"conflict_policy"`
+	Filled         []string        `json:"filled,omitempty"`    // параметры, не переданные в запросе и взятые из справочника
+	Computed       []string        `json:"computed,omitempty"`  // параметры, рассчитанные по данным склада вместо значений клиента
+	Conflicts      []FieldConflict `json:"conflicts,omitempty"` // расхождения с запросом; в режиме warn побеждает справочник
+}
+
+// Источники признаков скоринга
+const (
+	SignalSourceData    = "data"    // рассчитан по данным склада или справочника
+	SignalSourceRequest = "request" // передан клиентом в запросе
+	SignalSourceDefault = "default" // не рассчитан: данных склада нет, признак выключен
+)
+
+// SlotSignals - данные склада о предложенной ячейке, нужные для признаков скоринга
+type SlotSignals struct {
+	ZoneType   string
+	HasHistory bool // товар уже размещался в этой ячейке по placement_logs
+}
+
+// ShadowComparison - предложение теневого сервиса рядом с реальным решением оркестратора
+type ShadowComparison struct {
+	ItemID        string
+	BatchID       string
+	ShadowService string
+	ShadowSuccess bool
+	ShadowSlotID  string
+	ShadowScore   float64
+
+	DecisionSuccess   bool
+	DecisionAlgorithm string
+	DecisionSlotID    string
+	DecisionScore     float64
+}
+
+// ShadowReport - сводка сравнения теневых сервисов с реальными решениями
+type ShadowReport struct {
+	From       *time.Time              `json:"from,omitempty"`
+	To         *time.Time              `json:"to,omitempty"`
+	Algorithms []ShadowAlgorithmReport `json:"algorithms"`
+}
+
+// ShadowAlgorithmReport - показатели одного теневого сервиса
+type ShadowAlgorithmReport struct {
+	ServiceID        string   `json:"service_id"`
+	ServiceName      string   `json:"service_name"`
+	Comparisons      int      `json:"comparisons"`        // число сравнений с реальными решениями
+	Suggested        int      `json:"suggested"`          // теневой сервис предложил ячейку
+	Agreements       int      `json:"agreements"`         // предложенная ячейка совпала с выбранной
+	AgreementRate    float64  `json:"agreement_rate"`     // доля совпадений среди успешных реальных решений
+	AvgScoreDelta    *float64 `json:"avg_score_delta"`    // средняя разница оценок: теневой минус реальный
+	AvgDistanceDelta *float64 `json:"avg_distance_delta"` // средняя разница расстояний до выхода: теневой минус реальный
+}
+
+// Операции оркестратора, решения по которым попадают в журнал
+const (
+	DecisionOperationAnalyze   = "analyze"
+	DecisionOperationPlace     = "place"
+	DecisionOperationBatchLine = "place_batch"
+)
+
+// DecisionRecord - запись журнала решений оркестратора: запрос после дополнения мастер-данными
+// и полный ответ со всеми ответами сервисов, разложением оценок и победителем
+type DecisionRecord struct {
+	DecisionID    int64           `json:"decision_id"`
+	Operation     string          `json:"operation"`
+	ItemID        string          `json:"item_id"`
+	BatchID       string          `json:"batch_id"`
+	SlotID        string          `json:"slot_id,omitempty"`
+	Success       bool            `json:"success"`
+	Algorithm     string          `json:"algorithm,omitempty"`
+	Score         float64         `json:"score"`
+	PolicyVersion string          `json:"policy_version"`
+	Request       json.RawMessage `json:"request"`
+	Response      json.RawMessage `json:"response"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+// DecisionFilter - условия поиска в журнале решений; пустые поля не ограничивают выборку
+type DecisionFilter struct {
+	ItemID  string
+	BatchID string
+	SlotID  string
+	From    *time.Time
+	To      *time.Time
+	Limit   int
+}
+
+// Состояния асинхронного задания
+const (
+	JobStateQueued  = "queued"
+	JobStateRunning = "running"
+	JobStateDone    = "done"
+	JobStateFailed  = "failed"
+)
+
+// Операции, которые можно выполнить асинхронным заданием
+const (
+	JobOperationAnalyze    = "analyze"
+	JobOperationPlace      = "place"
+	JobOperationPlaceBatch = "place_batch"
+)
+
+// Job - асинхронное задание оркестратора. Response заполняется, когда задание выполнено (done):
+// для analyze и place это OrchestratorResponse, для place_batch - BatchPlacementResponse
+type Job struct {
+	JobID      int64           `json:"job_id"`
+	Operation  string          `json:"operation"`
+	State      string          `json:"state"`
+	Request    json.RawMessage `json:"request"`
+	Response   json.RawMessage `json:"response,omitempty"`
+	Error      string          `json:"error,omitempty"`
+	Attempts   int             `json:"attempts"`
+	// LockedBy и LeaseUntil - экземпляр оркестратора, выполняющий задание, и срок его аренды
+	LockedBy   string          `json:"locked_by,omitempty"`
+	LeaseUntil *time.Time      `json:"lease_until,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+// JobSubmission - ответ на постановку задания в очередь
+type JobSubmission struct {
+	JobID     int64  `json:"job_id"`
+	Operation string `json:"operation"`
+	State     string `json:"state"`
+	StatusURL string `json:"status_url"`
+}
+
+// PickRequest - отбор товара со склада. Без batch_id отбирается любая партия товара, без slot_id
+// ячейки выбираются по порядку размещения: сначала те, куда товар попал раньше
+type PickRequest struct {
+	// WarehouseID - склад отбора; по умолчанию основной
+	WarehouseID string `json:"warehouse_id,omitempty"`
+	ItemID      string `json:"item_id" binding:"required"`
+	BatchID     string `json:"batch_id,omitempty"`
+	SlotID      string `json:"slot_id,omitempty"`
+	Quantity    int    `json:"quantity" binding:"required"`
+	// AllowExpired разрешает отбирать просроченные партии; по умолчанию они не отбираются
+	AllowExpired bool `json:"allow_expired,omitempty"`
+}
+
+// SlotPick - отбор из одной ячейки
+type SlotPick struct {
+	SlotID    string     `json:"slot_id"`
+	BatchID   string     `json:"batch_id,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Quantity  int        `json:"quantity"`
+	// Remaining - остаток партии в ячейке после отбора
+	Remaining int `json:"remaining"`
+	// SlotReleased - в ячейке не осталось товара, и она освобождена
+	SlotReleased bool `json:"slot_released"`
+}
+
+// PickResult - результат отбора. Отбор выполняется целиком или не выполняется вовсе:
+// если товара меньше, чем запрошено, ничего не списывается, а Available показывает доступный остаток
+type PickResult struct {
+	Line      int        `json:"line,omitempty"`
+	ItemID    string     `json:"item_id"`
+	BatchID   string     `json:"batch_id,omitempty"`
+	SlotID    string     `json:"slot_id,omitempty"`
+	Requested int        `json:"requested"`
+	Available int        `json:"available"`
+	Success   bool       `json:"success"`
+	Picks     []SlotPick `json:"picks,omitempty"`
+	Comment   string     `json:"comment"`
+}
+
+// PickListRequest - лист отбора из нескольких строк; режимы те же, что у пакетного размещения
+type PickListRequest struct {
+	Mode  string        `json:"mode"`
+	Lines []PickRequest `json:"lines" binding:"required"`
+}
+
+// PickListSummary - сводка листа отбора
+type PickListSummary struct {
+	Total  int `json:"total"`
+	Picked int `json:"picked"`
+	Failed int `json:"failed"`
+}
+
+// PickListResponse - ответ на лист отбора
+type PickListResponse struct {
+	Success bool            `json:"success"`
+	Mode    string          `json:"mode"`
+	Summary PickListSummary `json:"summary"`
+	Lines   []PickResult    `json:"lines"`
+}
+
+// StockEntry - остаток партии товара в ячейке по slot_stock
+type StockEntry struct {
+	WarehouseID string     `json:"warehouse_id"`
+	SlotID      string     `json:"slot_id"`
+	ItemID      string     `json:"item_id"`
+	BatchID     string     `json:"batch_id,omitempty"`
+	LotNumber   string     `json:"lot_number,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	Quantity    int        `json:"quantity"`
+	PlacedAt    time.Time  `json:"placed_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// StockFilter - условия выборки остатков; пустые поля не ограничивают выборку
+type StockFilter struct {
+	WarehouseID string
+	SlotID      string
+	ItemID      string
+	BatchID     string
+}
+
+// StockResponse - остатки и их общее количество
+type StockResponse struct {
+	Total   int          `json:"total"`
+	Entries []StockEntry `json:"entries"`
+}
+
+// Виды расхождений остатков с заполненностью ячеек
+const (
+	// StockProblemOccupiedEmpty - ячейка занята, но остатков в ней нет
+	StockProblemOccupiedEmpty = "occupied_without_stock"
+	// StockProblemFreeWithStock - ячейка свободна, но в ней числятся остатки
+	StockProblemFreeWithStock = "stock_in_free_slot"
+	// StockProblemUnitsMismatch - used_units ячейки не равно сумме остатков
+	StockProblemUnitsMismatch = "units_mismatch"
+	// StockProblemLoadMismatch - used_weight или used_volume не совпадает с весом или объемом остатков
+	StockProblemLoadMismatch = "load_mismatch"
+)
+
+// StockDiscrepancy - расхождение остатков ячейки с ее заполненностью
+type StockDiscrepancy struct {
+	WarehouseID string   `json:"warehouse_id"`
+	SlotID      string   `json:"slot_id"`
+	IsOccupied  bool     `json:"is_occupied"`
+	UsedUnits   int      `json:"used_units"`
+	StockUnits  int      `json:"stock_units"`
+	UsedWeight  float64  `json:"used_weight"`
+	StockWeight float64  `json:"stock_weight"`
+	UsedVolume  float64  `json:"used_volume"`
+	StockVolume float64  `json:"stock_volume"`
+	Problems    []string `json:"problems"`
+}
+
+// StockConsistencyReport - результат сверки остатков с заполненностью ячеек
+type StockConsistencyReport struct {
+	// WarehouseID - проверенный склад; пустое значение - все склады
+	WarehouseID   string             `json:"warehouse_id,omitempty"`
+	Consistent    bool               `json:"consistent"`
+	SlotsChecked  int                `json:"slots_checked"`
+	Discrepancies []StockDiscrepancy `json:"discrepancies"`
+}
+
+// Состояния срока годности в отчете
+const (
+	ExpiryStatusExpired    = "expired"
+	ExpiryStatusNearExpiry = "near_expiry"
+)
+
+// ExpiryEntry - остаток просроченной или скоро истекающей партии
+type ExpiryEntry struct {
+	StockEntry
+	// DaysLeft - полных дней до истечения срока; отрицательное значение - дней после истечения
+	DaysLeft int    `json:"days_left"`
+	Status   string `json:"status"`
+}
+
+// ExpiryReport - остатки партий, срок годности которых истек или истекает в ближайшие WithinDays дней
+type ExpiryReport struct {
+	// WarehouseID - склад отчета; пустое значение - все склады
+	WarehouseID     string        `json:"warehouse_id,omitempty"`
+	WithinDays      int           `json:"within_days"`
+	ExpiredUnits    int           `json:"expired_units"`
+	NearExpiryUnits int           `json:"near_expiry_units"`
+	Entries         []ExpiryEntry `json:"entries"`
+}
+
+// Стратегии перераскладки: по какой классификации выбирается зона товара
+const (
+	ReslotStrategyABC = "abc"
+	ReslotStrategyXYZ = "xyz"
+)
+
+// ReslotPlanRequest - параметры плана перераскладки
+type ReslotPlanRequest struct {
+	// WarehouseID - склад, остатки которого перекладываются; по умолчанию основной
+	WarehouseID string `json:"warehouse_id"`
+	// Strategy - abc (по умолчанию) или xyz
+	Strategy string `json:"strategy"`
+	// MaxMoves - бюджет перемещений: сколько перемещений может быть в плане
+	MaxMoves int `json:"max_moves"`
+}
+
+// ReslotStock - остаток в ячейке с ее зоной и удаленностью и характеристиками товара для планировщика
+type ReslotStock struct {
+	StockEntry
+	ZoneType         string
+	DistanceFromExit int
+	Turnover         float64
+	Mr               float64
+}
+
+// Направления перемещения в плане перераскладки
+const (
+	// ReslotPromote - перенос ближе к выходу
+	ReslotPromote = "promote"
+	// ReslotDemote - перенос дальше от выхода, освобождающий ближнюю ячейку
+	ReslotDemote = "demote"
+)
+
+// ReslotMove - предлагаемое перемещение остатка в зону, которую товару выбрала бы классификация сегодня
+type ReslotMove struct {
+	ItemID       string `json:"item_id"`
+	BatchID      string `json:"batch_id,omitempty"`
+	Quantity     int    `json:"quantity"`
+	Class        string `json:"class"`
+	Direction    string `json:"direction"`
+	FromSlotID   string `json:"from_slot_id"`
+	FromZone     string `json:"from_zone"`
+	FromDistance float64 `json:"from_distance"`
+	ToSlotID     string `json:"to_slot_id"`
+	ToZone       string `json:"to_zone"`
+	ToDistance   float64 `json:"to_distance"`
+	// DistanceGain - на сколько короче путь к товару за один отбор; у переноса дальше от выхода отрицателен.
+	// Расстояния считаются по топологии склада от ближайших ворот, если она задана, иначе по distance_from_exit
+	DistanceGain float64 `json:"distance_gain"`
+	// ExpectedGain - DistanceGain, взвешенный оборачиваемостью товара; по нему ранжируются перемещения
+	ExpectedGain float64 `json:"expected_gain"`
+	Reason       string  `json:"reason"`
+}
+
+// ReslotPlan - ранжированный план перераскладки
+type ReslotPlan struct {
+	WarehouseID string `json:"warehouse_id"`
+	Strategy    string `json:"strategy"`
+	MaxMoves    int    `json:"max_moves"`
+	// Misplaced - сколько остатков лежит не в той зоне, которую выбрала бы классификация
+	Misplaced         int          `json:"misplaced"`
+	TotalExpectedGain float64      `json:"total_expected_gain"`
+	Moves             []ReslotMove `json:"moves"`
+}
+
+// MoveRequest - перемещение остатка партии из одной ячейки в другую
+type MoveRequest struct {
+	// WarehouseID - склад обеих ячеек; по умолчанию основной
+	WarehouseID string `json:"warehouse_id,omitempty"`
+	ItemID      string `json:"item_id" binding:"required"`
+	BatchID     string `json:"batch_id,omitempty"`
+	FromSlotID  string `json:"from_slot_id" binding:"required"`
+	ToSlotID    string `json:"to_slot_id" binding:"required"`
+	// Quantity - перемещаемое количество; 0 - весь остаток партии в ячейке
+	Quantity int `json:"quantity"`
+}
+
+// MoveResult - результат перемещения. Перемещение выполняется целиком или не выполняется вовсе
+type MoveResult struct {
+	Success     bool   `json:"success"`
+	WarehouseID string `json:"warehouse_id"`
+	ItemID      string `json:"item_id"`
+	BatchID     string `json:"batch_id,omitempty"`
+	FromSlotID  string `json:"from_slot_id"`
+	ToSlotID    string `json:"to_slot_id"`
+	Quantity    int    `json:"quantity"`
+	// Remaining - остаток партии в исходной ячейке после перемещения
+	Remaining int `json:"remaining"`
+	// FromSlotReleased - исходная ячейка опустела и освобождена
+	FromSlotReleased bool   `json:"from_slot_released"`
+	Comment          string `json:"comment"`
+}
+
+// Warehouse - склад со сводкой по его ячейкам
+type Warehouse struct {
+	WarehouseID   string `json:"warehouse_id"`
+	Name          string `json:"name"`
+	Location      string `json:"location,omitempty"`
+	Slots         int    `json:"slots"`
+	OccupiedSlots int    `json:"occupied_slots"`
+	// Load - заполненность ячеек склада по объему от 0 до 1
+	Load      float64   `json:"load"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Действия над ячейкой в справочнике и журнале slot_audit
+const (
+	SlotActionCreated   = "created"
+	SlotActionUpdated   = "updated"
+	SlotActionUnchanged = "unchanged"
+	SlotActionRetired   = "retired"
+)
+
+// Режимы сохранения ячеек
+const (
+	// SlotSaveCreate - только создание новых ячеек
+	SlotSaveCreate = "create"
+	// SlotSaveUpdate - только изменение существующих
+	SlotSaveUpdate = "update"
+	// SlotSaveUpsert - создание или изменение
+	SlotSaveUpsert = "upsert"
+)
+
+// Slot - ячейка справочника. Заполненность и резерв только читаются: их меняют размещение, отбор
+// и перемещения, а не справочник
+type Slot struct {
+	SlotID              string  `json:"slot_id"`
+	WarehouseID         string  `json:"warehouse_id"`
+	LocationDescription string  `json:"location_description"`
+	MaxWeight           float64 `json:"max_weight"`
+	MaxLength           float64 `json:"max_length"`
+	MaxWidth            float64 `json:"max_width"`
+	MaxHeight           float64 `json:"max_height"`
+	// MaxUnits - предел числа единиц товара; nil - без ограничения
+	MaxUnits          *int   `json:"max_units"`
+	StorageConditions string `json:"storage_conditions"`
+	ZoneType          string `json:"zone_type"`
+	Level             int    `json:"level"`
+	DistanceFromExit  int    `json:"distance_from_exit"`
+	MixingPolicy      string `json:"mixing_policy"`
+	Status            string `json:"status"`
+
+	IsOccupied    bool       `json:"is_occupied"`
+	UsedWeight    float64    `json:"used_weight"`
+	UsedVolume    float64    `json:"used_volume"`
+	UsedUnits     int        `json:"used_units"`
+	ReservedUntil *time.Time `json:"reserved_until,omitempty"`
+}
+
+// SlotFilter - условия поиска ячеек; пустые поля не ограничивают выборку, а выведенные ячейки
+// возвращаются, только если Status = retired
+type SlotFilter struct {
+	WarehouseID string
+	ZoneType    string
+	Status      string
+}
+
+// SlotBatchRequest - пакет ячеек для создания или изменения одной транзакцией
+type SlotBatchRequest struct {
+	Slots []Slot `json:"slots"`
+}
+
+// SlotChange - результат сохранения или вывода ячейки
+type SlotChange struct {
+	SlotID string `json:"slot_id"`
+	Action string `json:"action"`
+	Slot   *Slot  `json:"slot"`
+}
+
+// SlotBatchResponse - результат пакетного сохранения ячеек
+type SlotBatchResponse struct {
+	Created   int          `json:"created"`
+	Updated   int          `json:"updated"`
+	Unchanged int          `json:"unchanged"`
+	Slots     []SlotChange `json:"slots"`
+}
+
+// SlotAuditRecord - запись журнала изменений ячейки; Before пуст у создания
+type SlotAuditRecord struct {
+	AuditID   int64           `json:"audit_id"`
+	SlotID    string          `json:"slot_id"`
+	Action    string          `json:"action"`
+	ChangedBy string          `json:"changed_by"`
+	ChangedAt time.Time       `json:"changed_at"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after"`
+}
@@ -0,0 +1,72 @@
+package domain
+
+import "testing"
+
+func TestQuorumPolicyIsMet(t *testing.T) {
+	responded := func(serviceID string) ServiceResult { return ServiceResult{ServiceID: serviceID} }
+	timedOut := func(serviceID string) ServiceResult { return ServiceResult{ServiceID: serviceID, TimedOut: true} }
+	skipped := func(serviceID string) ServiceResult { return ServiceResult{ServiceID: serviceID, Skipped: true} }
+
+	tests := []struct {
+		name    string
+		policy  QuorumPolicy
+		results []ServiceResult
+		want    bool
+	}{
+		{
+			name:    "достаточно ответов без обязательных сервисов",
+			policy:  QuorumPolicy{MinResponses: 2},
+			results: []ServiceResult{responded("abc"), responded("xyz")},
+			want:    true,
+		},
+		{
+			name:    "ответов меньше min_responses",
+			policy:  QuorumPolicy{MinResponses: 3},
+			results: []ServiceResult{responded("abc"), responded("xyz")},
+			want:    false,
+		},
+		{
+			name:    "обязательный сервис еще не ответил",
+			policy:  QuorumPolicy{RequiredServices: []string{"fixed"}, MinResponses: 1},
+			results: []ServiceResult{responded("abc"), responded("xyz")},
+			want:    false,
+		},
+		{
+			name:    "обязательный сервис ответил",
+			policy:  QuorumPolicy{RequiredServices: []string{"fixed"}, MinResponses: 2},
+			results: []ServiceResult{responded("abc"), responded("fixed")},
+			want:    true,
+		},
+		{
+			name:    "пропущенный сервис не считается ответившим",
+			policy:  QuorumPolicy{RequiredServices: []string{"fixed"}, MinResponses: 1},
+			results: []ServiceResult{skipped("fixed"), responded("abc")},
+			want:    false,
+		},
+		{
+			name:    "не дождавшийся сервис не считается ответившим",
+			policy:  QuorumPolicy{MinResponses: 2},
+			results: []ServiceResult{responded("abc"), timedOut("xyz")},
+			want:    false,
+		},
+		{
+			name:    "ошибка сервиса считается ответом",
+			policy:  QuorumPolicy{RequiredServices: []string{"abc"}, MinResponses: 1},
+			results: []ServiceResult{{ServiceID: "abc", Error: "ошибка алгоритма"}},
+			want:    true,
+		},
+		{
+			name:   "пустая политика выполнена сразу",
+			policy: QuorumPolicy{},
+			want:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.IsMet(tt.results); got != tt.want {
+				t.Errorf("IsMet() = %v, ожидалось %v", got, tt.want)
+			}
+		})
+	}
+}
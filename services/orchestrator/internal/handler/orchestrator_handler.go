@@ -1,7 +1,12 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"time"
 
 	"warehouse/services/orchestrator/internal/domain"
 	"warehouse/services/orchestrator/internal/service"
@@ -20,37 +25,547 @@ func NewOrchestratorHandler(service *service.OrchestratorService) *OrchestratorH
 }
 
 
-func (h *OrchestratorHandler) RegisterRoutes(router *gin.Engine) {
+func (h *OrchestratorHandler) RegisterRoutes(router gin.IRouter) {
+	router.POST("/analyze", h.AnalyzePlacement)
 	router.POST("/place", h.PlaceItem)
+	router.POST("/place/batch", h.PlaceBatch)
+	router.POST("/analyze/async", h.submitJob(domain.JobOperationAnalyze, func() interface{} { return &domain.PlacementRequest{} }))
+	router.POST("/place/async", h.submitJob(domain.JobOperationPlace, func() interface{} { return &domain.PlacementRequest{} }))
+	router.POST("/place/batch/async", h.submitJob(domain.JobOperationPlaceBatch, func() interface{} { return &domain.BatchPlacementRequest{} }))
+	router.GET("/jobs/:id", h.GetJob)
+	router.GET("/warehouses", h.ListWarehouses)
+	router.GET("/slots", h.ListSlots)
+	router.POST("/slots", h.CreateSlot)
+	router.POST("/slots/batch", h.SaveSlots)
+	router.GET("/slots/:id", h.GetSlot)
+	router.PUT("/slots/:id", h.UpdateSlot)
+	router.DELETE("/slots/:id", h.RetireSlot)
+	router.GET("/slots/:id/audit", h.GetSlotAudit)
+	router.POST("/picks", h.Pick)
+	router.POST("/picks/batch", h.PickList)
+	router.GET("/stock/slots/:id", h.getStock(func(filter *domain.StockFilter, id string) { filter.SlotID = id }))
+	router.GET("/stock/items/:id", h.getStock(func(filter *domain.StockFilter, id string) { filter.ItemID = id }))
+	router.GET("/stock/batches/:id", h.getStock(func(filter *domain.StockFilter, id string) { filter.BatchID = id }))
+	router.GET("/stock/consistency", h.CheckStockConsistency)
+	router.GET("/stock/expiry", h.GetExpiryReport)
+	router.POST("/reslotting/plan", h.PlanReslotting)
+	router.POST("/reslotting/moves", h.MoveStock)
+	router.GET("/services/health", h.GetServicesHealth)
+	router.POST("/services/:id/candidates", h.GetCandidates)
+	router.GET("/scoring-policy", h.GetScoringPolicy)
+	router.POST("/scoring-policy/reload", h.ReloadScoringPolicy)
+	router.GET("/shadow/report", h.GetShadowReport)
+	router.GET("/decisions", h.ListDecisions)
+	router.GET("/decisions/:id", h.GetDecision)
 }
 
+// submitJob возвращает обработчик, который ставит операцию в очередь заданий и сразу отвечает 202
+// с идентификатором задания. Повтор с тем же Idempotency-Key возвращает то же задание
+func (h *OrchestratorHandler) submitJob(operation string, newRequest func() interface{}) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		req := newRequest()
+		if err := c.ShouldBindJSON(req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 
-func (h *OrchestratorHandler) PlaceItem(c *gin.Context) {
+		response, err := h.service.ExecuteIdempotent(c.Request.Context(), c.GetHeader("Idempotency-Key"), operation+"_async", req, func(ctx context.Context) (interface{}, error) {
+			return h.service.SubmitJob(ctx, operation, req)
+		})
+		if h.writeError(c, err, "Ошибка постановки задания в очередь: ") {
+			return
+		}
+
+		c.Data(http.StatusAccepted, "application/json; charset=utf-8", response)
+	}
+}
+
+// GetJob возвращает состояние асинхронного задания и ответ оркестратора, когда задание выполнено
+func (h *OrchestratorHandler) GetJob(c *gin.Context) {
+	jobID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор задания"})
+		return
+	}
+
+	job, err := h.service.Job(c.Request.Context(), jobID)
+	if errors.Is(err, service.ErrJobNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка загрузки задания: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, job)
+}
+
+// ListDecisions ищет решения оркестратора по item_id, batch_id, slot_id и периоду from/to (RFC 3339)
+func (h *OrchestratorHandler) ListDecisions(c *gin.Context) {
+	filter := domain.DecisionFilter{
+		ItemID:  c.Query("item_id"),
+		BatchID: c.Query("batch_id"),
+		SlotID:  c.Query("slot_id"),
+	}
+
+	var err error
+	if filter.From, err = parseTimeQuery(c, "from"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if filter.To, err = parseTimeQuery(c, "to"); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if limit := c.Query("limit"); limit != "" {
+		if filter.Limit, err = strconv.Atoi(limit); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный параметр limit"})
+			return
+		}
+	}
+
+	decisions, err := h.service.Decisions(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка поиска решений: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, decisions)
+}
+
+// GetDecision возвращает решение оркестратора со всеми ответами сервисов
+func (h *OrchestratorHandler) GetDecision(c *gin.Context) {
+	decisionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный идентификатор решения"})
+		return
+	}
+
+	decision, err := h.service.Decision(c.Request.Context(), decisionID)
+	if errors.Is(err, service.ErrDecisionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка загрузки решения: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, decision)
+}
+
+// GetShadowReport возвращает сравнение теневых сервисов с реальными решениями;
+// период задается необязательными параметрами from и to в формате RFC 3339
+func (h *OrchestratorHandler) GetShadowReport(c *gin.Context) {
+	from, err := parseTimeQuery(c, "from")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	to, err := parseTimeQuery(c, "to")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := h.service.ShadowReport(c.Request.Context(), from, to)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка построения отчета теневого режима: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// parseTimeQuery разбирает необязательный параметр запроса со временем в формате RFC 3339
+func parseTimeQuery(c *gin.Context, name string) (*time.Time, error) {
+	value := c.Query(name)
+	if value == "" {
+		return nil, nil
+	}
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, fmt.Errorf("некорректный параметр %s: %w", name, err)
+	}
+	return &parsed, nil
+}
+
+// GetServicesHealth опрашивает /healthz сервисов размещения и возвращает состояние их выключателей
+func (h *OrchestratorHandler) GetServicesHealth(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.ServicesHealth(c.Request.Context()))
+}
+
+// GetCandidates возвращает подходящие ячейки одного сервиса, начиная с лучшей;
+// необязательный параметр limit ограничивает их число
+func (h *OrchestratorHandler) GetCandidates(c *gin.Context) {
 	var req domain.PlacementRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	limit := 0
+	if value := c.Query("limit"); value != "" {
+		var err error
+		if limit, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный параметр limit"})
+			return
+		}
+	}
 
-	analysis, err := h.service.AnalyzePlacement(c.Request.Context(), &req)
+	candidates, err := h.service.Candidates(c.Request.Context(), c.Param("id"), &req, limit)
+	if h.writeError(c, err, "Ошибка при подборе кандидатов: ") {
+		return
+	}
+
+	c.JSON(http.StatusOK, candidates)
+}
+
+// GetScoringPolicy возвращает действующую политику скоринга
+func (h *OrchestratorHandler) GetScoringPolicy(c *gin.Context) {
+	c.JSON(http.StatusOK, h.service.ScoringPolicy())
+}
+
+// ReloadScoringPolicy перечитывает политику скоринга из файла
+func (h *OrchestratorHandler) ReloadScoringPolicy(c *gin.Context) {
+	policy, err := h.service.ReloadScoringPolicy()
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при анализе размещения: " + err.Error()})
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Ошибка загрузки политики скоринга: " + err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+// AnalyzePlacement подбирает ячейку без размещения: опрашивает все сервисы командой analyze
+// и возвращает победителя вместе с ранжированным списком всех ответов
+func (h *OrchestratorHandler) AnalyzePlacement(c *gin.Context) {
+	var req domain.PlacementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	analysis, err := h.service.AnalyzePlacement(c.Request.Context(), &req)
+	if h.writeError(c, err, "Ошибка при анализе размещения: ") {
+		return
+	}
+
+	c.JSON(http.StatusOK, analysis)
+}
+
+
+func (h *OrchestratorHandler) PlaceItem(c *gin.Context) {
+	var req domain.PlacementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+
+	response, err := h.service.ExecuteIdempotent(c.Request.Context(), c.GetHeader("Idempotency-Key"), "place", &req, func(ctx context.Context) (interface{}, error) {
+		return h.service.PlaceItem(ctx, &req)
+	})
+	if h.writeError(c, err, "Ошибка при размещении: ") {
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", response)
+}
+
+// PlaceBatch размещает несколько партий без конфликтов по ячейкам
+func (h *OrchestratorHandler) PlaceBatch(c *gin.Context) {
+	var req domain.BatchPlacementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.service.ExecuteIdempotent(c.Request.Context(), c.GetHeader("Idempotency-Key"), "place_batch", &req, func(ctx context.Context) (interface{}, error) {
+		return h.service.PlaceBatch(ctx, &req)
+	})
+	if h.writeError(c, err, "Ошибка при пакетном размещении: ") {
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", response)
+}
+
+// Pick отбирает товар со склада и освобождает опустевшие ячейки
+func (h *OrchestratorHandler) Pick(c *gin.Context) {
+	var req domain.PickRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	
-	if !analysis.Success {
-		c.JSON(http.StatusOK, analysis)
+	response, err := h.service.ExecuteIdempotent(c.Request.Context(), c.GetHeader("Idempotency-Key"), "pick", &req, func(ctx context.Context) (interface{}, error) {
+		return h.service.Pick(ctx, &req)
+	})
+	if h.writeError(c, err, "Ошибка при отборе: ") {
 		return
 	}
 
+	c.Data(http.StatusOK, "application/json; charset=utf-8", response)
+}
 
-	response, err := h.service.PlaceItem(c.Request.Context(), &req)
+// ListWarehouses возвращает склады со сводкой по их ячейкам
+func (h *OrchestratorHandler) ListWarehouses(c *gin.Context) {
+	warehouses, err := h.service.Warehouses(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка при размещении: " + err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Ошибка чтения складов: " + err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, warehouses)
+}
+
+// changedByHeader - заголовок с пользователем, который меняет справочник ячеек
+const changedByHeader = "X-User-ID"
+
+// maxChangedByLength - предел длины автора изменения, как у slot_audit.changed_by
+const maxChangedByLength = 100
+
+// changedBy возвращает автора изменения справочника ячеек; без него запрос отклоняется с кодом 400
+func changedBy(c *gin.Context) (string, bool) {
+	user := c.GetHeader(changedByHeader)
+	if user == "" || len(user) > maxChangedByLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("заголовок %s обязателен и не длиннее %d символов", changedByHeader, maxChangedByLength)})
+		return "", false
+	}
+	return user, true
+}
+
+// ListSlots возвращает ячейки справочника по warehouse_id, zone_type и status; выведенные ячейки
+// возвращаются только по status=retired
+func (h *OrchestratorHandler) ListSlots(c *gin.Context) {
+	filter := domain.SlotFilter{
+		WarehouseID: c.Query("warehouse_id"),
+		ZoneType:    c.Query("zone_type"),
+		Status:      c.Query("status"),
+	}
+	slots, err := h.service.Slots(c.Request.Context(), filter)
+	if h.writeError(c, err, "Ошибка чтения ячеек: ") {
+		return
+	}
+	c.JSON(http.StatusOK, slots)
+}
+
+// GetSlot возвращает ячейку справочника с текущей заполненностью
+func (h *OrchestratorHandler) GetSlot(c *gin.Context) {
+	slot, err := h.service.Slot(c.Request.Context(), c.Param("id"))
+	if h.writeError(c, err, "Ошибка чтения ячейки: ") {
+		return
+	}
+	c.JSON(http.StatusOK, slot)
+}
+
+// GetSlotAudit возвращает журнал изменений ячейки: кто, когда и как ее менял
+func (h *OrchestratorHandler) GetSlotAudit(c *gin.Context) {
+	records, err := h.service.SlotAudit(c.Request.Context(), c.Param("id"))
+	if h.writeError(c, err, "Ошибка чтения журнала ячейки: ") {
+		return
+	}
+	c.JSON(http.StatusOK, records)
+}
+
+// CreateSlot создает ячейку
+func (h *OrchestratorHandler) CreateSlot(c *gin.Context) {
+	user, ok := changedBy(c)
+	if !ok {
+		return
+	}
+	var slot domain.Slot
+	if err := c.ShouldBindJSON(&slot); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	change, err := h.service.SaveSlot(c.Request.Context(), &slot, domain.SlotSaveCreate, user)
+	if h.writeError(c, err, "Ошибка создания ячейки: ") {
+		return
+	}
+	c.JSON(http.StatusCreated, change)
+}
+
+// UpdateSlot заменяет параметры ячейки: размеры, пределы, зону, уровень, условия хранения и статус.
+// Изменение, при котором содержимое ячейки в нее не помещается, отклоняется с кодом 409
+func (h *OrchestratorHandler) UpdateSlot(c *gin.Context) {
+	user, ok := changedBy(c)
+	if !ok {
+		return
+	}
+	var slot domain.Slot
+	if err := c.ShouldBindJSON(&slot); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if slot.SlotID != "" && slot.SlotID != c.Param("id") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "slot_id в теле не совпадает с идентификатором в пути"})
+		return
+	}
+	slot.SlotID = c.Param("id")
+
+	change, err := h.service.SaveSlot(c.Request.Context(), &slot, domain.SlotSaveUpdate, user)
+	if h.writeError(c, err, "Ошибка изменения ячейки: ") {
 		return
 	}
+	c.JSON(http.StatusOK, change)
+}
 
+// SaveSlots создает и изменяет пакет ячеек одной транзакцией
+func (h *OrchestratorHandler) SaveSlots(c *gin.Context) {
+	user, ok := changedBy(c)
+	if !ok {
+		return
+	}
+	var req domain.SlotBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.service.SaveSlots(c.Request.Context(), &req, user)
+	if h.writeError(c, err, "Ошибка сохранения ячеек: ") {
+		return
+	}
 	c.JSON(http.StatusOK, response)
-} 
\ No newline at end of file
+}
+
+// RetireSlot выводит ячейку из справочника. Занятая или зарезервированная ячейка не выводится
+func (h *OrchestratorHandler) RetireSlot(c *gin.Context) {
+	user, ok := changedBy(c)
+	if !ok {
+		return
+	}
+
+	change, err := h.service.RetireSlot(c.Request.Context(), c.Param("id"), user)
+	if h.writeError(c, err, "Ошибка вывода ячейки: ") {
+		return
+	}
+	c.JSON(http.StatusOK, change)
+}
+
+// PickList отбирает товар по листу отбора из нескольких строк
+func (h *OrchestratorHandler) PickList(c *gin.Context) {
+	var req domain.PickListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.service.ExecuteIdempotent(c.Request.Context(), c.GetHeader("Idempotency-Key"), "pick_list", &req, func(ctx context.Context) (interface{}, error) {
+		return h.service.PickList(ctx, &req)
+	})
+	if h.writeError(c, err, "Ошибка при отборе по листу: ") {
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", response)
+}
+
+// getStock возвращает обработчик остатков по ячейке, товару или партии из пути запроса. Параметры
+// warehouse_id, slot_id, item_id и batch_id дополнительно сужают выборку
+func (h *OrchestratorHandler) getStock(byPath func(filter *domain.StockFilter, id string)) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		filter := domain.StockFilter{
+			WarehouseID: c.Query("warehouse_id"),
+			SlotID:      c.Query("slot_id"),
+			ItemID:      c.Query("item_id"),
+			BatchID:     c.Query("batch_id"),
+		}
+		byPath(&filter, c.Param("id"))
+
+		stock, err := h.service.Stock(c.Request.Context(), filter)
+		if h.writeError(c, err, "Ошибка чтения остатков: ") {
+			return
+		}
+		c.JSON(http.StatusOK, stock)
+	}
+}
+
+// CheckStockConsistency сверяет остатки с заполненностью ячеек склада warehouse_id (по умолчанию всех складов)
+// и возвращает найденные расхождения
+func (h *OrchestratorHandler) CheckStockConsistency(c *gin.Context) {
+	report, err := h.service.CheckStockConsistency(c.Request.Context(), c.Query("warehouse_id"))
+	if h.writeError(c, err, "Ошибка сверки остатков: ") {
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// GetExpiryReport возвращает просроченные остатки и остатки, срок годности которых истекает
+// в ближайшие days дней, на складе warehouse_id или на всех складах
+func (h *OrchestratorHandler) GetExpiryReport(c *gin.Context) {
+	var days int
+	if value := c.Query("days"); value != "" {
+		var err error
+		if days, err = strconv.Atoi(value); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "некорректный параметр days"})
+			return
+		}
+	}
+
+	report, err := h.service.ExpiryReport(c.Request.Context(), c.Query("warehouse_id"), days)
+	if h.writeError(c, err, "Ошибка построения отчета о сроках годности: ") {
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// PlanReslotting предлагает ранжированный план перемещений остатков в зоны, которые им выбрала бы
+// классификация ABC или XYZ сегодня. Тело запроса необязательно
+func (h *OrchestratorHandler) PlanReslotting(c *gin.Context) {
+	var req domain.ReslotPlanRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	plan, err := h.service.ReslotPlan(c.Request.Context(), &req)
+	if h.writeError(c, err, "Ошибка построения плана перераскладки: ") {
+		return
+	}
+	c.JSON(http.StatusOK, plan)
+}
+
+// MoveStock перемещает остаток партии из одной ячейки в другую
+func (h *OrchestratorHandler) MoveStock(c *gin.Context) {
+	var req domain.MoveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, err := h.service.ExecuteIdempotent(c.Request.Context(), c.GetHeader("Idempotency-Key"), "move", &req, func(ctx context.Context) (interface{}, error) {
+		return h.service.MoveStock(ctx, &req)
+	})
+	if h.writeError(c, err, "Ошибка при перемещении: ") {
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", response)
+}
+
+// writeError отвечает клиенту по ошибке сервиса и сообщает, была ли ошибка
+func (h *OrchestratorHandler) writeError(c *gin.Context, err error, prefix string) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, service.ErrInvalidRequest):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrMasterDataConflict):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrIdempotentRequestInProgress), errors.Is(err, service.ErrSlotConflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, service.ErrSlotNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": prefix + err.Error()})
+	}
+	return true
+}
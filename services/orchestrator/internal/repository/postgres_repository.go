@@ -0,0 +1,1341 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"warehouse/pkg/capacity"
+	"warehouse/pkg/idempotency"
+	"warehouse/pkg/outbox"
+	"warehouse/pkg/stock"
+	"warehouse/pkg/topology"
+	"warehouse/services/orchestrator/internal/domain"
+
+	"github.com/lib/pq"
+)
+
+type PostgresRepository struct {
+	// KeyStore - ключи идемпотентности, общие с сервисами размещения
+	*idempotency.KeyStore
+
+	db *sql.DB
+	// distances кэширует кратчайшие пути по топологии склада
+	distances *topology.Cache
+}
+
+func NewPostgresRepository(db *sql.DB) *PostgresRepository {
+	return &PostgresRepository{KeyStore: idempotency.NewKeyStore(db), db: db, distances: topology.NewCache(db, topology.DefaultCacheTTL)}
+}
+
+// GetItem возвращает мастер-данные товара или nil, если товара нет
+func (r *PostgresRepository) GetItem(ctx context.Context, itemID string) (*domain.Item, error) {
+	var item domain.Item
+	var storageConditions sql.NullString
+	var storageTemp, storageHumidity sql.NullFloat64
+	err := r.db.QueryRowContext(ctx, `
+		SELECT item_id, name, item_type, weight, length, width, height, storage_conditions,
+		       turnover, mr, COALESCE(is_heavy, false), COALESCE(is_fragile, false), COALESCE(is_hazardous, false),
+		       storage_temp, storage_humidity
+		FROM items WHERE item_id = $1`, itemID,
+	).Scan(&item.ItemID, &item.Name, &item.ItemType, &item.Weight, &item.Length, &item.Width, &item.Height, &storageConditions,
+		&item.Turnover, &item.Mr, &item.IsHeavy, &item.IsFragile, &item.IsHazardous,
+		&storageTemp, &storageHumidity)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	item.StorageConditions = storageConditions.String
+	if storageTemp.Valid {
+		item.StorageTemp = &storageTemp.Float64
+	}
+	if storageHumidity.Valid {
+		item.StorageHumidity = &storageHumidity.Float64
+	}
+	return &item, nil
+}
+
+// GetBatch возвращает партию или nil, если партии нет
+func (r *PostgresRepository) GetBatch(ctx context.Context, batchID string) (*domain.Batch, error) {
+	var batch domain.Batch
+	var itemID sql.NullString
+	err := r.db.QueryRowContext(ctx, "SELECT batch_id, item_id, quantity FROM batches WHERE batch_id = $1", batchID).
+		Scan(&batch.BatchID, &itemID, &batch.Quantity)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	batch.ItemID = itemID.String
+	return &batch, nil
+}
+
+// HasFixedSlot сообщает, закреплена ли за товаром ячейка на складе
+func (r *PostgresRepository) HasFixedSlot(ctx context.Context, warehouseID, itemID string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRowContext(ctx,
+		"SELECT EXISTS(SELECT 1 FROM item_slot_map WHERE warehouse_id = $1 AND item_id = $2)",
+		warehouseID, itemID,
+	).Scan(&exists)
+	return exists, err
+}
+
+// GetWarehouseLoad возвращает заполненность склада по объему от 0 до 1
+func (r *PostgresRepository) GetWarehouseLoad(ctx context.Context, warehouseID string) (float64, error) {
+	var load float64
+	err := r.db.QueryRowContext(ctx,
+		"SELECT COALESCE(SUM(used_volume) / NULLIF(SUM(max_length * max_width * max_height), 0), 0) FROM slots WHERE warehouse_id = $1 AND status <> 'retired'",
+		warehouseID,
+	).Scan(&load)
+	return load, err
+}
+
+// warehouseColumns - колонки склада warehouses w со сводкой по его ячейкам slots s в порядке scanWarehouse
+const warehouseColumns = `w.warehouse_id, w.name, COALESCE(w.location, ''), w.created_at,
+		       COUNT(s.slot_id), COUNT(s.slot_id) FILTER (WHERE s.is_occupied),
+		       COALESCE(SUM(s.used_volume) / NULLIF(SUM(s.max_length * s.max_width * s.max_height), 0), 0)`
+
+// GetWarehouses возвращает склады со сводкой по их ячейкам; выведенные ячейки не учитываются
+func (r *PostgresRepository) GetWarehouses(ctx context.Context) ([]domain.Warehouse, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+warehouseColumns+`
+		FROM warehouses w
+		LEFT JOIN slots s ON s.warehouse_id = w.warehouse_id AND s.status <> 'retired'
+		GROUP BY w.warehouse_id
+		ORDER BY w.warehouse_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	warehouses := []domain.Warehouse{}
+	for rows.Next() {
+		warehouse, err := scanWarehouse(rows)
+		if err != nil {
+			return nil, err
+		}
+		warehouses = append(warehouses, *warehouse)
+	}
+	return warehouses, rows.Err()
+}
+
+// GetWarehouse возвращает склад со сводкой по его ячейкам; nil - склада нет
+func (r *PostgresRepository) GetWarehouse(ctx context.Context, warehouseID string) (*domain.Warehouse, error) {
+	warehouse, err := scanWarehouse(r.db.QueryRowContext(ctx, `
+		SELECT `+warehouseColumns+`
+		FROM warehouses w
+		LEFT JOIN slots s ON s.warehouse_id = w.warehouse_id AND s.status <> 'retired'
+		WHERE w.warehouse_id = $1
+		GROUP BY w.warehouse_id`,
+		warehouseID,
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return warehouse, err
+}
+
+func scanWarehouse(row interface{ Scan(dest ...interface{}) error }) (*domain.Warehouse, error) {
+	var warehouse domain.Warehouse
+	err := row.Scan(
+		&warehouse.WarehouseID, &warehouse.Name, &warehouse.Location, &warehouse.CreatedAt,
+		&warehouse.Slots, &warehouse.OccupiedSlots, &warehouse.Load,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &warehouse, nil
+}
+
+// GetSlotSignals возвращает зону каждой ячейки и признак того, размещался ли в ней товар
+func (r *PostgresRepository) GetSlotSignals(ctx context.Context, itemID string, slotIDs []string) (map[string]domain.SlotSignals, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.slot_id, s.zone_type,
+		       EXISTS(SELECT 1 FROM placement_logs l WHERE l.slot_id = s.slot_id AND l.item_id = $1 AND l.movement = 'placed')
+		FROM slots s
+		WHERE s.slot_id = ANY($2)`, itemID, pq.Array(slotIDs),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	signals := make(map[string]domain.SlotSignals)
+	for rows.Next() {
+		var slotID string
+		var slot domain.SlotSignals
+		if err := rows.Scan(&slotID, &slot.ZoneType, &slot.HasHistory); err != nil {
+			return nil, err
+		}
+		signals[slotID] = slot
+	}
+	return signals, rows.Err()
+}
+
+func (r *PostgresRepository) SaveShadowComparisons(ctx context.Context, comparisons []domain.ShadowComparison) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, c := range comparisons {
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO shadow_comparisons (item_id, batch_id, shadow_service, shadow_success, shadow_slot_id, shadow_score,
+			                                decision_success, decision_algorithm, decision_slot_id, decision_score)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7, $8, NULLIF($9, ''), $10)`,
+			c.ItemID, c.BatchID, c.ShadowService, c.ShadowSuccess, c.ShadowSlotID, c.ShadowScore,
+			c.DecisionSuccess, c.DecisionAlgorithm, c.DecisionSlotID, c.DecisionScore,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetShadowReport считает показатели теневых сервисов за период; границы периода необязательны.
+// Разница оценок и расстояний считается только по сравнениям, где ячейку предложили обе стороны
+func (r *PostgresRepository) GetShadowReport(ctx context.Context, from, to *time.Time) ([]domain.ShadowAlgorithmReport, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT c.shadow_service,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE c.shadow_success),
+		       COUNT(*) FILTER (WHERE c.shadow_success AND c.decision_success AND c.shadow_slot_id = c.decision_slot_id),
+		       COUNT(*) FILTER (WHERE c.decision_success),
+		       AVG(c.shadow_score - c.decision_score) FILTER (WHERE c.shadow_success AND c.decision_success),
+		       AVG(ss.distance_from_exit - ds.distance_from_exit) FILTER (WHERE c.shadow_success AND c.decision_success)
+		FROM shadow_comparisons c
+		LEFT JOIN slots ss ON ss.slot_id = c.shadow_slot_id
+		LEFT JOIN slots ds ON ds.slot_id = c.decision_slot_id
+		WHERE ($1::timestamp IS NULL OR c.created_at >= $1)
+		  AND ($2::timestamp IS NULL OR c.created_at < $2)
+		GROUP BY c.shadow_service
+		ORDER BY c.shadow_service`, from, to,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reports []domain.ShadowAlgorithmReport
+	for rows.Next() {
+		var report domain.ShadowAlgorithmReport
+		var decided int
+		var scoreDelta, distanceDelta sql.NullFloat64
+		if err := rows.Scan(&report.ServiceID, &report.Comparisons, &report.Suggested, &report.Agreements,
+			&decided, &scoreDelta, &distanceDelta); err != nil {
+			return nil, err
+		}
+		if decided > 0 {
+			report.AgreementRate = float64(report.Agreements) / float64(decided)
+		}
+		if scoreDelta.Valid {
+			report.AvgScoreDelta = &scoreDelta.Float64
+		}
+		if distanceDelta.Valid {
+			report.AvgDistanceDelta = &distanceDelta.Float64
+		}
+		reports = append(reports, report)
+	}
+	return reports, rows.Err()
+}
+
+func (r *PostgresRepository) SaveDecision(ctx context.Context, record *domain.DecisionRecord) (int64, error) {
+	var decisionID int64
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO orchestrator_decisions (operation, item_id, batch_id, slot_id, success, algorithm, score, policy_version, request, response)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7, $8, $9, $10)
+		RETURNING decision_id`,
+		record.Operation, record.ItemID, record.BatchID, record.SlotID, record.Success, record.Algorithm,
+		record.Score, record.PolicyVersion, []byte(record.Request), []byte(record.Response),
+	).Scan(&decisionID)
+	return decisionID, err
+}
+
+const decisionColumns = `decision_id, operation, item_id, batch_id, COALESCE(slot_id, ''), success, algorithm, score,
+		       policy_version, request, response, created_at`
+
+// FindDecisions возвращает решения по фильтру, начиная с самых новых
+func (r *PostgresRepository) FindDecisions(ctx context.Context, filter domain.DecisionFilter) ([]domain.DecisionRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+decisionColumns+`
+		FROM orchestrator_decisions
+		WHERE ($1 = '' OR item_id = $1)
+		  AND ($2 = '' OR batch_id = $2)
+		  AND ($3 = '' OR slot_id = $3)
+		  AND ($4::timestamp IS NULL OR created_at >= $4)
+		  AND ($5::timestamp IS NULL OR created_at < $5)
+		ORDER BY created_at DESC, decision_id DESC
+		LIMIT $6`,
+		filter.ItemID, filter.BatchID, filter.SlotID, filter.From, filter.To, filter.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var records []domain.DecisionRecord
+	for rows.Next() {
+		record, err := scanDecision(rows)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+	return records, rows.Err()
+}
+
+// GetDecision возвращает решение по идентификатору или nil, если его нет
+func (r *PostgresRepository) GetDecision(ctx context.Context, decisionID int64) (*domain.DecisionRecord, error) {
+	record, err := scanDecision(r.db.QueryRowContext(ctx, "SELECT "+decisionColumns+" FROM orchestrator_decisions WHERE decision_id = $1", decisionID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return record, err
+}
+
+// scanDecision читает строку журнала решений из *sql.Row или *sql.Rows
+func scanDecision(row interface{ Scan(dest ...interface{}) error }) (*domain.DecisionRecord, error) {
+	var record domain.DecisionRecord
+	var request, response []byte
+	err := row.Scan(&record.DecisionID, &record.Operation, &record.ItemID, &record.BatchID, &record.SlotID,
+		&record.Success, &record.Algorithm, &record.Score, &record.PolicyVersion, &request, &response, &record.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	record.Request = request
+	record.Response = response
+	return &record, nil
+}
+
+const jobColumns = `job_id, operation, state, request, response, COALESCE(error, ''), attempts, COALESCE(locked_by, ''), lease_until, created_at, started_at, finished_at`
+
+// ErrJobLeaseLost означает, что аренда задания истекла и задание вернулось в очередь или его взял другой экземпляр
+var ErrJobLeaseLost = errors.New("задание больше не закреплено за этим экземпляром")
+
+// CreateJob ставит задание в очередь
+func (r *PostgresRepository) CreateJob(ctx context.Context, operation string, request []byte) (*domain.Job, error) {
+	return scanJob(r.db.QueryRowContext(ctx, `
+		INSERT INTO placement_jobs (operation, request)
+		VALUES ($1, $2)
+		RETURNING `+jobColumns,
+		operation, request,
+	))
+}
+
+// ClaimJob переводит самое старое задание из очереди в состояние running, закрепляет его за owner
+// на lease и возвращает; FOR UPDATE SKIP LOCKED не дает двум обработчикам взять одно задание.
+// Если очередь пуста, возвращается nil
+func (r *PostgresRepository) ClaimJob(ctx context.Context, owner string, lease time.Duration) (*domain.Job, error) {
+	job, err := scanJob(r.db.QueryRowContext(ctx, `
+		UPDATE placement_jobs
+		SET state = 'running', started_at = CURRENT_TIMESTAMP, attempts = attempts + 1,
+		    locked_by = $1, lease_until = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		WHERE job_id = (
+			SELECT job_id FROM placement_jobs
+			WHERE state = 'queued'
+			ORDER BY job_id
+			FOR UPDATE SKIP LOCKED
+			LIMIT 1
+		)
+		RETURNING `+jobColumns,
+		owner, lease.Seconds(),
+	))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return job, err
+}
+
+// ExtendJobLease продлевает аренду задания, которое выполняет owner
+func (r *PostgresRepository) ExtendJobLease(ctx context.Context, jobID int64, owner string, lease time.Duration) (bool, error) {
+	result, err := r.db.ExecContext(ctx, `
+		UPDATE placement_jobs
+		SET lease_until = CURRENT_TIMESTAMP + make_interval(secs => $3)
+		WHERE job_id = $1 AND state = 'running' AND locked_by = $2`,
+		jobID, owner, lease.Seconds(),
+	)
+	if err != nil {
+		return false, err
+	}
+	affected, err := result.RowsAffected()
+	return affected == 1, err
+}
+
+// CompleteJob сохраняет ответ выполненного задания
+func (r *PostgresRepository) CompleteJob(ctx context.Context, jobID int64, owner string, response []byte) error {
+	return r.finishJob(ctx, `
+		UPDATE placement_jobs
+		SET state = 'done', response = $3, error = NULL, finished_at = CURRENT_TIMESTAMP, locked_by = NULL, lease_until = NULL
+		WHERE job_id = $1 AND state = 'running' AND locked_by = $2`,
+		jobID, owner, response,
+	)
+}
+
+// FailJob сохраняет ошибку задания
+func (r *PostgresRepository) FailJob(ctx context.Context, jobID int64, owner string, message string) error {
+	return r.finishJob(ctx, `
+		UPDATE placement_jobs
+		SET state = 'failed', error = $3, finished_at = CURRENT_TIMESTAMP, locked_by = NULL, lease_until = NULL
+		WHERE job_id = $1 AND state = 'running' AND locked_by = $2`,
+		jobID, owner, message,
+	)
+}
+
+// finishJob сохраняет итог задания; если задание уже не закреплено за экземпляром, возвращает ErrJobLeaseLost
+func (r *PostgresRepository) finishJob(ctx context.Context, query string, args ...interface{}) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrJobLeaseLost
+	}
+	return nil
+}
+
+// GetJob возвращает задание или nil, если его нет
+func (r *PostgresRepository) GetJob(ctx context.Context, jobID int64) (*domain.Job, error) {
+	job, err := scanJob(r.db.QueryRowContext(ctx, "SELECT "+jobColumns+" FROM placement_jobs WHERE job_id = $1", jobID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return job, err
+}
+
+// RequeueExpiredJobs возвращает в очередь задания, аренду которых никто не продлил: экземпляр,
+// выполнявший их, остановился или завис. Задания без аренды остались от версии без аренды и тоже
+// считаются прерванными. Задание, которое уже брали в работу maxAttempts раз, переводится в failed,
+// чтобы задание, роняющее оркестратор, не выполнялось бесконечно
+func (r *PostgresRepository) RequeueExpiredJobs(ctx context.Context, maxAttempts int) (int64, int64, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		UPDATE placement_jobs
+		SET state = CASE WHEN attempts >= $1 THEN 'failed' ELSE 'queued' END,
+		    error = CASE WHEN attempts >= $1 THEN 'задание прервано ' || attempts || ' раз, попытки исчерпаны' ELSE error END,
+		    finished_at = CASE WHEN attempts >= $1 THEN CURRENT_TIMESTAMP END,
+		    locked_by = NULL, lease_until = NULL
+		WHERE state = 'running' AND (lease_until IS NULL OR lease_until < CURRENT_TIMESTAMP)
+		RETURNING state`,
+		maxAttempts,
+	)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer rows.Close()
+
+	var requeued, failed int64
+	for rows.Next() {
+		var state string
+		if err := rows.Scan(&state); err != nil {
+			return 0, 0, err
+		}
+		if state == domain.JobStateFailed {
+			failed++
+		} else {
+			requeued++
+		}
+	}
+	return requeued, failed, rows.Err()
+}
+
+// scanJob читает строку задания из *sql.Row или *sql.Rows
+func scanJob(row interface{ Scan(dest ...interface{}) error }) (*domain.Job, error) {
+	var job domain.Job
+	var request, response []byte
+	var leaseUntil, startedAt, finishedAt sql.NullTime
+	err := row.Scan(&job.JobID, &job.Operation, &job.State, &request, &response, &job.Error, &job.Attempts,
+		&job.LockedBy, &leaseUntil, &job.CreatedAt, &startedAt, &finishedAt)
+	if err != nil {
+		return nil, err
+	}
+	job.Request = request
+	job.Response = response
+	if leaseUntil.Valid {
+		job.LeaseUntil = &leaseUntil.Time
+	}
+	if startedAt.Valid {
+		job.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		job.FinishedAt = &finishedAt.Time
+	}
+	return &job, nil
+}
+
+// pickAlgorithm - значение placement_logs.algorithm и источник событий для отбора
+const pickAlgorithm = "picking"
+
+// PickStock списывает строки отбора с остатков slot_stock. Каждый отбор записывается
+// в placement_logs с movement = 'picked', событие ItemPicked - в outbox; опустевшая ячейка
+// освобождается с событием SlotReleased
+func (r *PostgresRepository) PickStock(ctx context.Context, lines []domain.PickRequest, atomic bool) ([]domain.PickResult, error) {
+	results := make([]domain.PickResult, len(lines))
+
+	if !atomic {
+		for i := range lines {
+			result, err := r.pickLine(ctx, &lines[i])
+			if err != nil {
+				return nil, err
+			}
+			results[i] = *result
+		}
+		return results, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	allPicked := true
+	for i := range lines {
+		result, err := pickInTx(ctx, tx, &lines[i])
+		if err != nil {
+			return nil, err
+		}
+		results[i] = *result
+		allPicked = allPicked && result.Success
+	}
+	if !allPicked {
+		return results, nil
+	}
+	return results, tx.Commit()
+}
+
+// pickLine списывает одну строку отдельной транзакцией
+func (r *PostgresRepository) pickLine(ctx context.Context, line *domain.PickRequest) (*domain.PickResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := pickInTx(ctx, tx, line)
+	if err != nil || !result.Success {
+		return result, err
+	}
+	return result, tx.Commit()
+}
+
+// pickInTx распределяет количество строки по ячейкам с остатком в порядке размещения и списывает его
+func pickInTx(ctx context.Context, tx *sql.Tx, line *domain.PickRequest) (*domain.PickResult, error) {
+	result := &domain.PickResult{
+		ItemID:    line.ItemID,
+		BatchID:   line.BatchID,
+		SlotID:    line.SlotID,
+		Requested: line.Quantity,
+	}
+
+	// Остатки блокируются в порядке ключа, чтобы параллельные отборы не списали один остаток
+	// дважды и не заблокировали друг друга. Списываются они по FEFO: сначала партии с ближайшим
+	// сроком годности, затем без срока; при равном сроке - в порядке поступления в ячейку.
+	// Просроченные партии отбираются, только если это разрешено в строке
+	rows, err := tx.QueryContext(ctx, `
+		SELECT st.slot_id, st.batch_id, b.expires_at, st.quantity, st.placed_at
+		FROM slot_stock st
+		JOIN slots s ON s.slot_id = st.slot_id
+		LEFT JOIN batches b ON b.batch_id = st.batch_id
+		WHERE s.warehouse_id = $1 AND st.item_id = $2 AND ($3 = '' OR st.batch_id = $3) AND ($4 = '' OR st.slot_id = $4) AND st.quantity > 0
+		  AND ($5 OR b.expires_at IS NULL OR b.expires_at > CURRENT_DATE)
+		ORDER BY st.slot_id, st.batch_id
+		FOR UPDATE OF st`,
+		line.WarehouseID, line.ItemID, line.BatchID, line.SlotID, line.AllowExpired,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	var lots []pickLot
+	for rows.Next() {
+		var onHand pickLot
+		var expiresAt sql.NullTime
+		if err := rows.Scan(&onHand.SlotID, &onHand.BatchID, &expiresAt, &onHand.Remaining, &onHand.placedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if expiresAt.Valid {
+			onHand.ExpiresAt = &expiresAt.Time
+		}
+		result.Available += onHand.Remaining
+		lots = append(lots, onHand)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	sortFEFO(lots)
+
+	if result.Available < line.Quantity {
+		result.Comment = fmt.Sprintf("Недостаточно товара: доступно %d из %d", result.Available, line.Quantity)
+		return result, nil
+	}
+
+	remaining := line.Quantity
+	for _, onHand := range lots {
+		if remaining == 0 {
+			break
+		}
+		pick := onHand.SlotPick
+		pick.Quantity = min(pick.Remaining, remaining)
+		remaining -= pick.Quantity
+
+		released, err := recordPick(ctx, tx, line.ItemID, &pick)
+		if err != nil {
+			return nil, err
+		}
+		pick.SlotReleased = released
+		result.Picks = append(result.Picks, pick)
+	}
+
+	result.Success = true
+	result.Comment = fmt.Sprintf("Отобрано %d шт. из ячеек: %d", line.Quantity, len(result.Picks))
+	return result, nil
+}
+
+// pickLot - остаток партии в ячейке, из которого можно отобрать товар; placedAt - время поступления в ячейку
+type pickLot struct {
+	domain.SlotPick
+	placedAt time.Time
+}
+
+// sortFEFO упорядочивает остатки для отбора: сначала партии с ближайшим сроком годности, затем без срока;
+// при равном сроке - в порядке поступления в ячейку
+func sortFEFO(lots []pickLot) {
+	sort.SliceStable(lots, func(i, j int) bool {
+		a, b := lots[i].ExpiresAt, lots[j].ExpiresAt
+		switch {
+		case a != nil && b != nil && !a.Equal(*b):
+			return a.Before(*b)
+		case (a == nil) != (b == nil):
+			return a != nil
+		}
+		return lots[i].placedAt.Before(lots[j].placedAt)
+	})
+}
+
+// recordPick списывает отбор с остатка slot_stock, записывает его в placement_logs и outbox и вычитает
+// из заполненности ячейки. Ячейка, в которой не осталось товара, освобождается; возвращает, освобождена ли ячейка
+func recordPick(ctx context.Context, tx *sql.Tx, itemID string, pick *domain.SlotPick) (bool, error) {
+	left, err := stock.Issue(ctx, tx, pick.SlotID, itemID, pick.BatchID, pick.Quantity)
+	if err != nil {
+		return false, err
+	}
+	pick.Remaining = left
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO placement_logs (slot_id, item_id, batch_id, algorithm, movement, quantity) VALUES ($1, $2, NULLIF($3, ''), $4, 'picked', $5)",
+		pick.SlotID, itemID, pick.BatchID, pickAlgorithm, pick.Quantity,
+	); err != nil {
+		return false, err
+	}
+	if err := outbox.Insert(ctx, tx, outbox.Event{
+		Type:     outbox.EventItemPicked,
+		SlotID:   pick.SlotID,
+		ItemID:   itemID,
+		BatchID:  pick.BatchID,
+		Quantity: pick.Quantity,
+		Source:   pickAlgorithm,
+	}); err != nil {
+		return false, err
+	}
+
+	released, err := capacity.Vacate(ctx, tx, pick.SlotID, itemID, pick.Quantity)
+	if err != nil || !released {
+		return false, err
+	}
+	return true, outbox.Insert(ctx, tx, outbox.Event{
+		Type:   outbox.EventSlotReleased,
+		SlotID: pick.SlotID,
+		Source: pickAlgorithm,
+	})
+}
+
+// revertAlgorithm - значение placement_logs.algorithm и источник событий для отмены размещений пакета
+const revertAlgorithm = "batch_placement"
+
+// RevertPlacement отменяет размещение quantity единиц партии в ячейке (0 - вся партия, как при размещении):
+// списывает их с остатка и заполненности ячейки, пишет в placement_logs строку с movement = 'reverted'
+// и событие PlacementReverted в outbox. Отмена не выдается за отбор: ItemPicked и SlotReleased не пишутся
+func (r *PostgresRepository) RevertPlacement(ctx context.Context, slotID, itemID, batchID string, quantity int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	load, err := capacity.ResolveLoad(ctx, tx, itemID, batchID, quantity)
+	if err != nil {
+		return err
+	}
+	if _, err := stock.Issue(ctx, tx, slotID, itemID, batchID, load.Units); err != nil {
+		return err
+	}
+	if _, err := capacity.Vacate(ctx, tx, slotID, itemID, load.Units); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO placement_logs (slot_id, item_id, batch_id, algorithm, movement, quantity) VALUES ($1, $2, NULLIF($3, ''), $4, 'reverted', $5)",
+		slotID, itemID, batchID, revertAlgorithm, load.Units,
+	); err != nil {
+		return err
+	}
+	if err := outbox.Insert(ctx, tx, outbox.Event{
+		Type:     outbox.EventPlacementReverted,
+		SlotID:   slotID,
+		ItemID:   itemID,
+		BatchID:  batchID,
+		Quantity: load.Units,
+		Source:   revertAlgorithm,
+	}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// FindStock возвращает остатки slot_stock по складу, ячейке, товару и партии в порядке ячеек и поступления
+func (r *PostgresRepository) FindStock(ctx context.Context, filter domain.StockFilter) ([]domain.StockEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+stockEntryColumns+`
+		FROM slot_stock st
+		JOIN slots s ON s.slot_id = st.slot_id
+		LEFT JOIN batches b ON b.batch_id = st.batch_id
+		WHERE ($1 = '' OR s.warehouse_id = $1) AND ($2 = '' OR st.slot_id = $2) AND ($3 = '' OR st.item_id = $3)
+		  AND ($4 = '' OR st.batch_id = $4) AND st.quantity > 0
+		ORDER BY s.warehouse_id, st.slot_id, st.placed_at, st.item_id, st.batch_id`,
+		filter.WarehouseID, filter.SlotID, filter.ItemID, filter.BatchID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.StockEntry{}
+	for rows.Next() {
+		var entry domain.StockEntry
+		if err := scanStockEntry(rows, &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// stockEntryColumns - колонки остатка slot_stock st с его ячейкой slots s и партией batches b в порядке scanStockEntry
+const stockEntryColumns = "s.warehouse_id, st.slot_id, st.item_id, st.batch_id, COALESCE(b.lot_number, ''), b.expires_at, st.quantity, st.placed_at, st.updated_at"
+
+// scanStockEntry читает остаток, выбранный колонками stockEntryColumns, и дополнительные колонки extra
+func scanStockEntry(rows *sql.Rows, entry *domain.StockEntry, extra ...interface{}) error {
+	var expiresAt sql.NullTime
+	dest := []interface{}{&entry.WarehouseID, &entry.SlotID, &entry.ItemID, &entry.BatchID, &entry.LotNumber, &expiresAt, &entry.Quantity, &entry.PlacedAt, &entry.UpdatedAt}
+	if err := rows.Scan(append(dest, extra...)...); err != nil {
+		return err
+	}
+	if expiresAt.Valid {
+		entry.ExpiresAt = &expiresAt.Time
+	}
+	return nil
+}
+
+// FindExpiringStock возвращает остатки партий склада (пустой warehouseID - всех складов), срок годности
+// которых истек или истекает в ближайшие withinDays дней, начиная с самых ранних; DaysLeft отсчитывается от текущей даты
+func (r *PostgresRepository) FindExpiringStock(ctx context.Context, warehouseID string, withinDays int) ([]domain.ExpiryEntry, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+stockEntryColumns+`, b.expires_at - CURRENT_DATE
+		FROM slot_stock st
+		JOIN slots s ON s.slot_id = st.slot_id
+		JOIN batches b ON b.batch_id = st.batch_id
+		WHERE ($1 = '' OR s.warehouse_id = $1) AND b.expires_at <= CURRENT_DATE + $2::INTEGER AND st.quantity > 0
+		ORDER BY b.expires_at, s.warehouse_id, st.slot_id, st.item_id, st.batch_id`,
+		warehouseID, withinDays,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []domain.ExpiryEntry{}
+	for rows.Next() {
+		var entry domain.ExpiryEntry
+		if err := scanStockEntry(rows, &entry.StockEntry, &entry.DaysLeft); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// GetSlotStockTotals возвращает заполненность каждой ячейки склада (пустой warehouseID - всех складов)
+// и сумму, вес и объем ее остатков; поле Problems не заполняется
+func (r *PostgresRepository) GetSlotStockTotals(ctx context.Context, warehouseID string) ([]domain.StockDiscrepancy, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.warehouse_id, s.slot_id, COALESCE(s.is_occupied, false), s.used_units, s.used_weight, s.used_volume,
+		       COALESCE(SUM(st.quantity), 0),
+		       COALESCE(SUM(st.quantity * i.weight), 0),
+		       COALESCE(SUM(st.quantity * i.length * i.width * i.height), 0)
+		FROM slots s
+		LEFT JOIN slot_stock st ON st.slot_id = s.slot_id
+		LEFT JOIN items i ON i.item_id = st.item_id
+		WHERE $1 = '' OR s.warehouse_id = $1
+		GROUP BY s.slot_id
+		ORDER BY s.warehouse_id, s.slot_id`,
+		warehouseID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var totals []domain.StockDiscrepancy
+	for rows.Next() {
+		var slot domain.StockDiscrepancy
+		if err := rows.Scan(
+			&slot.WarehouseID, &slot.SlotID, &slot.IsOccupied, &slot.UsedUnits, &slot.UsedWeight, &slot.UsedVolume,
+			&slot.StockUnits, &slot.StockWeight, &slot.StockVolume,
+		); err != nil {
+			return nil, err
+		}
+		totals = append(totals, slot)
+	}
+	return totals, rows.Err()
+}
+
+// reslotAlgorithm - значение placement_logs.algorithm и источник событий для перемещений
+const reslotAlgorithm = "reslotting"
+
+// GetReslotStock возвращает остатки склада вместе с зоной и удаленностью их ячеек и оборачиваемостью
+// и коэффициентом вариации товара. Товары с закрепленной на этом складе ячейкой не перекладываются и не возвращаются
+func (r *PostgresRepository) GetReslotStock(ctx context.Context, warehouseID string) ([]domain.ReslotStock, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+stockEntryColumns+`, s.zone_type, s.distance_from_exit, i.turnover, i.mr
+		FROM slot_stock st
+		JOIN slots s ON s.slot_id = st.slot_id
+		JOIN items i ON i.item_id = st.item_id
+		LEFT JOIN batches b ON b.batch_id = st.batch_id
+		WHERE s.warehouse_id = $1 AND st.quantity > 0
+		  AND NOT EXISTS (SELECT 1 FROM item_slot_map m WHERE m.warehouse_id = s.warehouse_id AND m.item_id = st.item_id)
+		ORDER BY st.slot_id, st.item_id, st.batch_id`,
+		warehouseID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stocks []domain.ReslotStock
+	for rows.Next() {
+		var entry domain.ReslotStock
+		if err := scanStockEntry(rows, &entry.StockEntry, &entry.ZoneType, &entry.DistanceFromExit, &entry.Turnover, &entry.Mr); err != nil {
+			return nil, err
+		}
+		stocks = append(stocks, entry)
+	}
+	return stocks, rows.Err()
+}
+
+// FindReslotTargets возвращает незарезервированные ячейки зоны на том же складе и с теми же условиями хранения,
+// что у ячейки остатка, в которые остаток помещается, кроме ячеек exclude, по возрастанию distance_from_exit.
+// Возвращаются все такие ячейки: по топологии склада ближайшей может оказаться любая из них
+func (r *PostgresRepository) FindReslotTargets(ctx context.Context, zoneType string, entry domain.StockEntry, exclude []string) ([]topology.SlotDistance, error) {
+	load, err := capacity.ResolveLoad(ctx, r.db, entry.ItemID, entry.BatchID, entry.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	if exclude == nil {
+		// pq.Array(nil) передается как NULL, а = ANY(NULL) отбросило бы все ячейки
+		exclude = []string{}
+	}
+
+	condition, args := capacity.FitsCondition(load, 4)
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT s.slot_id, s.distance_from_exit
+		FROM slots s
+		JOIN slots src ON src.slot_id = $3
+		WHERE s.warehouse_id = src.warehouse_id AND s.zone_type = $1 AND NOT (s.slot_id = ANY($2))
+		  AND s.storage_conditions IS NOT DISTINCT FROM src.storage_conditions
+		  AND (s.reserved_until IS NULL OR s.reserved_until < NOW()) AND `+condition+`
+		ORDER BY s.distance_from_exit, s.slot_id`,
+		append([]interface{}{zoneType, pq.Array(exclude), entry.SlotID}, args...)...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []topology.SlotDistance
+	for rows.Next() {
+		var target topology.SlotDistance
+		if err := rows.Scan(&target.SlotID, &target.DistanceFromExit); err != nil {
+			return nil, err
+		}
+		targets = append(targets, target)
+	}
+	return targets, rows.Err()
+}
+
+// GetTravelDistances возвращает пути от ворот (пустой dockID - от ближайших) до ячеек склада;
+// nil - для склада не задана топология
+func (r *PostgresRepository) GetTravelDistances(ctx context.Context, warehouseID, dockID string) (map[string]float64, error) {
+	distances, err := r.distances.Distances(ctx, warehouseID)
+	if err != nil || distances == nil {
+		return nil, err
+	}
+	return distances.FromDock(dockID)
+}
+
+// ErrSlotNotFound означает, что ячейки нет на складе операции
+var ErrSlotNotFound = errors.New("ячейка не найдена на складе")
+
+// MoveStock перемещает остаток партии между ячейками склада одной транзакцией: занимает емкость
+// целевой ячейки, переносит остаток в slot_stock, освобождает емкость исходной ячейки
+// и записывает перемещение в placement_logs и outbox. Если товара в исходной ячейке не хватает
+// или он не помещается в целевую, возвращается stock.ErrInsufficientStock или
+// capacity.ErrInsufficientCapacity и ничего не меняется
+func (r *PostgresRepository) MoveStock(ctx context.Context, req *domain.MoveRequest) (*domain.MoveResult, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result := &domain.MoveResult{
+		WarehouseID: req.WarehouseID,
+		ItemID:      req.ItemID,
+		BatchID:     req.BatchID,
+		FromSlotID:  req.FromSlotID,
+		ToSlotID:    req.ToSlotID,
+		Quantity:    req.Quantity,
+	}
+
+	// Ячейки блокируются в порядке slot_id, чтобы встречные перемещения не заблокировали друг друга
+	lockRows, err := tx.QueryContext(ctx,
+		"SELECT slot_id FROM slots WHERE slot_id IN ($1, $2) AND warehouse_id = $3 ORDER BY slot_id FOR UPDATE",
+		req.FromSlotID, req.ToSlotID, req.WarehouseID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	locked := map[string]bool{}
+	for lockRows.Next() {
+		var slotID string
+		if err := lockRows.Scan(&slotID); err != nil {
+			lockRows.Close()
+			return nil, err
+		}
+		locked[slotID] = true
+	}
+	lockRows.Close()
+	if err := lockRows.Err(); err != nil {
+		return nil, err
+	}
+	for _, slotID := range []string{req.FromSlotID, req.ToSlotID} {
+		if !locked[slotID] {
+			return nil, fmt.Errorf("%w %s: %s", ErrSlotNotFound, req.WarehouseID, slotID)
+		}
+	}
+
+	if result.Quantity == 0 {
+		err := tx.QueryRowContext(ctx,
+			"SELECT quantity FROM slot_stock WHERE slot_id = $1 AND item_id = $2 AND batch_id = $3",
+			req.FromSlotID, req.ItemID, req.BatchID,
+		).Scan(&result.Quantity)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w %s: %s/%s", stock.ErrInsufficientStock, req.FromSlotID, req.ItemID, req.BatchID)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	load, err := capacity.ResolveLoad(ctx, tx, req.ItemID, req.BatchID, result.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	if err := capacity.Occupy(ctx, tx, req.ToSlotID, load); err != nil {
+		return nil, err
+	}
+	if result.Remaining, err = stock.Move(ctx, tx, req.FromSlotID, req.ToSlotID, req.ItemID, req.BatchID, result.Quantity); err != nil {
+		return nil, err
+	}
+	if result.FromSlotReleased, err = capacity.Vacate(ctx, tx, req.FromSlotID, req.ItemID, result.Quantity); err != nil {
+		return nil, err
+	}
+
+	for _, movement := range []struct{ slotID, kind string }{{req.FromSlotID, "moved_out"}, {req.ToSlotID, "moved_in"}} {
+		if _, err := tx.ExecContext(ctx,
+			"INSERT INTO placement_logs (slot_id, item_id, batch_id, algorithm, movement, quantity) VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6)",
+			movement.slotID, req.ItemID, req.BatchID, reslotAlgorithm, movement.kind, result.Quantity,
+		); err != nil {
+			return nil, err
+		}
+	}
+	if err := outbox.Insert(ctx, tx, outbox.Event{
+		Type:       outbox.EventItemMoved,
+		SlotID:     req.ToSlotID,
+		FromSlotID: req.FromSlotID,
+		ItemID:     req.ItemID,
+		BatchID:    req.BatchID,
+		Quantity:   result.Quantity,
+		Source:     reslotAlgorithm,
+	}); err != nil {
+		return nil, err
+	}
+	if result.FromSlotReleased {
+		if err := outbox.Insert(ctx, tx, outbox.Event{
+			Type:   outbox.EventSlotReleased,
+			SlotID: req.FromSlotID,
+			Source: reslotAlgorithm,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	result.Success = true
+	return result, tx.Commit()
+}
+
+var (
+	// ErrSlotExists означает, что ячейка с таким идентификатором уже есть
+	ErrSlotExists = errors.New("ячейка уже существует")
+	// ErrSlotInUse означает, что изменение ячейки противоречит ее содержимому или резерву
+	ErrSlotInUse = errors.New("изменение противоречит содержимому ячейки")
+)
+
+// slotColumns - колонки ячейки slots s в порядке scanSlot; истекший резерв не возвращается
+const slotColumns = `s.slot_id, s.warehouse_id, COALESCE(s.location_description, ''),
+		       s.max_weight, s.max_length, s.max_width, s.max_height, s.max_units,
+		       COALESCE(s.storage_conditions, ''), s.zone_type, s.level, s.distance_from_exit,
+		       s.mixing_policy, s.status, COALESCE(s.is_occupied, false),
+		       s.used_weight, s.used_volume, s.used_units,
+		       CASE WHEN s.reserved_until > NOW() THEN s.reserved_until END`
+
+func scanSlot(row interface{ Scan(dest ...interface{}) error }) (*domain.Slot, error) {
+	var slot domain.Slot
+	var maxUnits sql.NullInt64
+	var reservedUntil sql.NullTime
+	err := row.Scan(
+		&slot.SlotID, &slot.WarehouseID, &slot.LocationDescription,
+		&slot.MaxWeight, &slot.MaxLength, &slot.MaxWidth, &slot.MaxHeight, &maxUnits,
+		&slot.StorageConditions, &slot.ZoneType, &slot.Level, &slot.DistanceFromExit,
+		&slot.MixingPolicy, &slot.Status, &slot.IsOccupied,
+		&slot.UsedWeight, &slot.UsedVolume, &slot.UsedUnits,
+		&reservedUntil,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if maxUnits.Valid {
+		units := int(maxUnits.Int64)
+		slot.MaxUnits = &units
+	}
+	if reservedUntil.Valid {
+		slot.ReservedUntil = &reservedUntil.Time
+	}
+	return &slot, nil
+}
+
+// FindSlots возвращает ячейки по складу, зоне и статусу в порядке склада и идентификатора
+func (r *PostgresRepository) FindSlots(ctx context.Context, filter domain.SlotFilter) ([]domain.Slot, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+slotColumns+`
+		FROM slots s
+		WHERE ($1 = '' OR s.warehouse_id = $1) AND ($2 = '' OR s.zone_type = $2)
+		  AND (s.status = $3 OR ($3 = '' AND s.status <> 'retired'))
+		ORDER BY s.warehouse_id, s.slot_id`,
+		filter.WarehouseID, filter.ZoneType, filter.Status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	slots := []domain.Slot{}
+	for rows.Next() {
+		slot, err := scanSlot(rows)
+		if err != nil {
+			return nil, err
+		}
+		slots = append(slots, *slot)
+	}
+	return slots, rows.Err()
+}
+
+// GetSlot возвращает ячейку; nil - ячейки нет
+func (r *PostgresRepository) GetSlot(ctx context.Context, slotID string) (*domain.Slot, error) {
+	return getSlot(ctx, r.db, slotID, false)
+}
+
+// getSlot читает ячейку, при lock - с блокировкой строки до конца транзакции; nil - ячейки нет
+func getSlot(ctx context.Context, q capacity.Queryer, slotID string, lock bool) (*domain.Slot, error) {
+	query := "SELECT " + slotColumns + " FROM slots s WHERE s.slot_id = $1"
+	if lock {
+		query += " FOR UPDATE"
+	}
+	slot, err := scanSlot(q.QueryRowContext(ctx, query, slotID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return slot, err
+}
+
+// SaveSlots создает и изменяет ячейки одной транзакцией: если хотя бы одну сохранить нельзя, не меняется
+// ни одна. Режим mode (domain.SlotSave*) ограничивает создание и изменение. Изменения пишутся в slot_audit
+// от имени changedBy; ячейка без изменений журнал не пополняет
+func (r *PostgresRepository) SaveSlots(ctx context.Context, slots []domain.Slot, mode, changedBy string) ([]domain.SlotChange, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	changes := make([]domain.SlotChange, 0, len(slots))
+	for i := range slots {
+		change, err := saveSlotInTx(ctx, tx, &slots[i], mode, changedBy)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, *change)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+func saveSlotInTx(ctx context.Context, tx *sql.Tx, slot *domain.Slot, mode, changedBy string) (*domain.SlotChange, error) {
+	before, err := getSlot(ctx, tx, slot.SlotID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	if before == nil {
+		if mode == domain.SlotSaveUpdate {
+			return nil, fmt.Errorf("%w: %s", ErrSlotNotFound, slot.SlotID)
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO slots (slot_id, warehouse_id, location_description, max_weight, max_length, max_width,
+			                   max_height, max_units, storage_conditions, zone_type, level, distance_from_exit,
+			                   mixing_policy, status)
+			VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, NULLIF($9, ''), $10, $11, $12, $13, $14)`,
+			slot.SlotID, slot.WarehouseID, slot.LocationDescription, slot.MaxWeight, slot.MaxLength, slot.MaxWidth,
+			slot.MaxHeight, slot.MaxUnits, slot.StorageConditions, slot.ZoneType, slot.Level, slot.DistanceFromExit,
+			slot.MixingPolicy, slot.Status,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка создания ячейки %s: %w", slot.SlotID, err)
+		}
+		return auditSlot(ctx, tx, slot.SlotID, domain.SlotActionCreated, changedBy, nil)
+	}
+
+	if mode == domain.SlotSaveCreate {
+		return nil, fmt.Errorf("%w: %s", ErrSlotExists, slot.SlotID)
+	}
+	if before.WarehouseID != slot.WarehouseID {
+		return nil, fmt.Errorf("%w на складе %s: %s", ErrSlotExists, before.WarehouseID, slot.SlotID)
+	}
+	if sameSlotDefinition(before, slot) {
+		return &domain.SlotChange{SlotID: slot.SlotID, Action: domain.SlotActionUnchanged, Slot: before}, nil
+	}
+	if err := checkSlotContents(ctx, tx, before, slot); err != nil {
+		return nil, err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE slots
+		SET location_description = NULLIF($2, ''), max_weight = $3, max_length = $4, max_width = $5, max_height = $6,
+		    max_units = $7, storage_conditions = NULLIF($8, ''), zone_type = $9, level = $10,
+		    distance_from_exit = $11, mixing_policy = $12, status = $13
+		WHERE slot_id = $1`,
+		slot.SlotID, slot.LocationDescription, slot.MaxWeight, slot.MaxLength, slot.MaxWidth, slot.MaxHeight,
+		slot.MaxUnits, slot.StorageConditions, slot.ZoneType, slot.Level,
+		slot.DistanceFromExit, slot.MixingPolicy, slot.Status,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка изменения ячейки %s: %w", slot.SlotID, err)
+	}
+	return auditSlot(ctx, tx, slot.SlotID, domain.SlotActionUpdated, changedBy, before)
+}
+
+// sameSlotDefinition сообщает, совпадают ли изменяемые справочником поля ячеек
+func sameSlotDefinition(a, b *domain.Slot) bool {
+	sameUnits := (a.MaxUnits == nil) == (b.MaxUnits == nil) && (a.MaxUnits == nil || *a.MaxUnits == *b.MaxUnits)
+	return sameUnits &&
+		a.LocationDescription == b.LocationDescription &&
+		a.MaxWeight == b.MaxWeight && a.MaxLength == b.MaxLength && a.MaxWidth == b.MaxWidth && a.MaxHeight == b.MaxHeight &&
+		a.StorageConditions == b.StorageConditions && a.ZoneType == b.ZoneType && a.Level == b.Level &&
+		a.DistanceFromExit == b.DistanceFromExit && a.MixingPolicy == b.MixingPolicy && a.Status == b.Status
+}
+
+// checkSlotContents проверяет, что текущее содержимое ячейки before помещается в ячейку с новыми
+// параметрами slot: по весу, объему, числу единиц, размерам товаров и политике смешивания
+func checkSlotContents(ctx context.Context, tx *sql.Tx, before, slot *domain.Slot) error {
+	var batches, items int
+	var length, width, height float64
+	err := tx.QueryRowContext(ctx, `
+		SELECT COUNT(*), COUNT(DISTINCT st.item_id),
+		       COALESCE(MAX(i.length), 0), COALESCE(MAX(i.width), 0), COALESCE(MAX(i.height), 0)
+		FROM slot_stock st
+		LEFT JOIN items i ON i.item_id = st.item_id
+		WHERE st.slot_id = $1 AND st.quantity > 0`,
+		slot.SlotID,
+	).Scan(&batches, &items, &length, &width, &height)
+	if err != nil {
+		return err
+	}
+
+	const tolerance = 1e-6
+	volume := slot.MaxLength * slot.MaxWidth * slot.MaxHeight
+	switch {
+	case slot.MaxWeight < before.UsedWeight-tolerance:
+		return fmt.Errorf("%w %s: max_weight %.2f меньше веса содержимого %.2f", ErrSlotInUse, slot.SlotID, slot.MaxWeight, before.UsedWeight)
+	case volume < before.UsedVolume-tolerance:
+		return fmt.Errorf("%w %s: объем %.3f меньше объема содержимого %.3f", ErrSlotInUse, slot.SlotID, volume, before.UsedVolume)
+	case slot.MaxUnits != nil && *slot.MaxUnits < before.UsedUnits:
+		return fmt.Errorf("%w %s: max_units %d меньше числа единиц в ячейке %d", ErrSlotInUse, slot.SlotID, *slot.MaxUnits, before.UsedUnits)
+	case slot.MaxLength < length || slot.MaxWidth < width || slot.MaxHeight < height:
+		return fmt.Errorf("%w %s: размеры меньше размеров лежащего в ней товара", ErrSlotInUse, slot.SlotID)
+	case slot.MixingPolicy == capacity.MixingSingleBatch && batches > 1:
+		return fmt.Errorf("%w %s: политика single_batch, а в ячейке %d партий", ErrSlotInUse, slot.SlotID, batches)
+	case slot.MixingPolicy == capacity.MixingSameItem && items > 1:
+		return fmt.Errorf("%w %s: политика same_item, а в ячейке %d товаров", ErrSlotInUse, slot.SlotID, items)
+	case slot.Status == capacity.SlotRetired && before.Status != capacity.SlotRetired:
+		return checkSlotRetirable(ctx, tx, before, batches)
+	}
+	return nil
+}
+
+// checkSlotRetirable проверяет, что ячейку можно вывести: она пуста и не зарезервирована. Истекший резерв
+// выводу не мешает: reserved_until не сбрасывается, пока ячейку не зарезервируют снова. Срок сравнивается
+// с NOW() базы, как при резервировании, а не с часами оркестратора
+func checkSlotRetirable(ctx context.Context, q capacity.Queryer, slot *domain.Slot, batches int) error {
+	if slot.IsOccupied || slot.UsedUnits > 0 || batches > 0 {
+		return fmt.Errorf("%w %s: ячейка занята", ErrSlotInUse, slot.SlotID)
+	}
+	if slot.ReservedUntil == nil {
+		return nil
+	}
+
+	var reserved bool
+	if err := q.QueryRowContext(ctx,
+		"SELECT COALESCE(reserved_until > NOW(), false) FROM slots WHERE slot_id = $1", slot.SlotID,
+	).Scan(&reserved); err != nil {
+		return err
+	}
+	if reserved {
+		return fmt.Errorf("%w %s: ячейка зарезервирована под размещение", ErrSlotInUse, slot.SlotID)
+	}
+	return nil
+}
+
+// RetireSlot выводит пустую незарезервированную ячейку из справочника и пишет это в slot_audit.
+// Ячейка не удаляется: на нее ссылаются журналы размещений. Повторный вывод ничего не меняет
+func (r *PostgresRepository) RetireSlot(ctx context.Context, slotID, changedBy string) (*domain.SlotChange, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := getSlot(ctx, tx, slotID, true)
+	if err != nil {
+		return nil, err
+	}
+	if before == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSlotNotFound, slotID)
+	}
+	if before.Status == capacity.SlotRetired {
+		return &domain.SlotChange{SlotID: slotID, Action: domain.SlotActionUnchanged, Slot: before}, nil
+	}
+
+	var batches int
+	if err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM slot_stock WHERE slot_id = $1 AND quantity > 0", slotID,
+	).Scan(&batches); err != nil {
+		return nil, err
+	}
+	if err := checkSlotRetirable(ctx, tx, before, batches); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "UPDATE slots SET status = 'retired' WHERE slot_id = $1", slotID); err != nil {
+		return nil, fmt.Errorf("ошибка вывода ячейки %s: %w", slotID, err)
+	}
+	change, err := auditSlot(ctx, tx, slotID, domain.SlotActionRetired, changedBy, before)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return change, nil
+}
+
+// auditSlot перечитывает ячейку после изменения и пишет запись журнала с состоянием до и после
+func auditSlot(ctx context.Context, tx *sql.Tx, slotID, action, changedBy string, before *domain.Slot) (*domain.SlotChange, error) {
+	after, err := getSlot(ctx, tx, slotID, false)
+	if err != nil {
+		return nil, err
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		return nil, err
+	}
+	var beforeJSON []byte
+	if before != nil {
+		if beforeJSON, err = json.Marshal(before); err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"INSERT INTO slot_audit (slot_id, action, changed_by, before_state, after_state) VALUES ($1, $2, $3, $4, $5)",
+		slotID, action, changedBy, beforeJSON, afterJSON,
+	); err != nil {
+		return nil, fmt.Errorf("ошибка записи журнала ячейки %s: %w", slotID, err)
+	}
+	return &domain.SlotChange{SlotID: slotID, Action: action, Slot: after}, nil
+}
+
+// GetSlotAudit возвращает журнал изменений ячейки от старых записей к новым
+func (r *PostgresRepository) GetSlotAudit(ctx context.Context, slotID string) ([]domain.SlotAuditRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT audit_id, slot_id, action, changed_by, changed_at, before_state, after_state
+		FROM slot_audit
+		WHERE slot_id = $1
+		ORDER BY audit_id`,
+		slotID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	records := []domain.SlotAuditRecord{}
+	for rows.Next() {
+		var record domain.SlotAuditRecord
+		var before, after []byte
+		if err := rows.Scan(&record.AuditID, &record.SlotID, &record.Action, &record.ChangedBy, &record.ChangedAt, &before, &after); err != nil {
+			return nil, err
+		}
+		record.Before, record.After = before, after
+		records = append(records, record)
+	}
+	return records, rows.Err()
+}
@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"warehouse/services/orchestrator/internal/domain"
+)
+
+func TestSortFEFO(t *testing.T) {
+	day := func(d int) *time.Time {
+		date := time.Date(2026, time.March, d, 0, 0, 0, 0, time.UTC)
+		return &date
+	}
+	placed := func(hour int) time.Time {
+		return time.Date(2026, time.January, 1, hour, 0, 0, 0, time.UTC)
+	}
+	lot := func(slotID string, expiresAt *time.Time, placedAt time.Time) pickLot {
+		return pickLot{SlotPick: domain.SlotPick{SlotID: slotID, ExpiresAt: expiresAt}, placedAt: placedAt}
+	}
+
+	tests := []struct {
+		name string
+		lots []pickLot
+		want []string
+	}{
+		{
+			name: "ближайший срок годности первым",
+			lots: []pickLot{lot("A", day(20), placed(1)), lot("B", day(5), placed(2)), lot("C", day(10), placed(3))},
+			want: []string{"B", "C", "A"},
+		},
+		{
+			name: "партии без срока после партий со сроком",
+			lots: []pickLot{lot("A", nil, placed(1)), lot("B", day(20), placed(2)), lot("C", nil, placed(0))},
+			want: []string{"B", "C", "A"},
+		},
+		{
+			name: "при равном сроке - раньше поступившие",
+			lots: []pickLot{lot("A", day(5), placed(3)), lot("B", day(5), placed(1)), lot("C", day(5), placed(2))},
+			want: []string{"B", "C", "A"},
+		},
+		{
+			name: "равный срок в разных часовых поясах",
+			lots: []pickLot{
+				lot("A", day(5), placed(2)),
+				func() pickLot {
+					moscow := day(5).In(time.FixedZone("MSK", 3*60*60))
+					return lot("B", &moscow, placed(1))
+				}(),
+			},
+			want: []string{"B", "A"},
+		},
+		{
+			name: "при равных сроке и времени порядок сохраняется",
+			lots: []pickLot{lot("A", nil, placed(1)), lot("B", nil, placed(1)), lot("C", nil, placed(1))},
+			want: []string{"A", "B", "C"},
+		},
+		{
+			name: "без остатков",
+			lots: []pickLot{},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sortFEFO(tt.lots)
+			got := make([]string, 0, len(tt.lots))
+			for _, onHand := range tt.lots {
+				got = append(got, onHand.SlotID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("порядок отбора %v, ожидался %v", got, tt.want)
+			}
+		})
+	}
+}
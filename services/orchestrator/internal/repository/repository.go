@@ -0,0 +1,105 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"warehouse/pkg/topology"
+	"warehouse/services/orchestrator/internal/domain"
+)
+
+type Repository interface {
+
+	BeginIdempotentRequest(ctx context.Context, scope, key, requestHash string) (*domain.IdempotencyRecord, bool, error)
+
+	CompleteIdempotentRequest(ctx context.Context, scope, key string, response []byte) error
+
+	DeleteIdempotentRequest(ctx context.Context, scope, key string) error
+
+	GetItem(ctx context.Context, itemID string) (*domain.Item, error)
+
+	GetBatch(ctx context.Context, batchID string) (*domain.Batch, error)
+
+	HasFixedSlot(ctx context.Context, warehouseID, itemID string) (bool, error)
+
+	GetWarehouseLoad(ctx context.Context, warehouseID string) (float64, error)
+
+	// GetWarehouses возвращает склады со сводкой по их ячейкам
+	GetWarehouses(ctx context.Context) ([]domain.Warehouse, error)
+
+	// GetWarehouse возвращает склад со сводкой по его ячейкам или nil, если склада нет
+	GetWarehouse(ctx context.Context, warehouseID string) (*domain.Warehouse, error)
+
+	GetSlotSignals(ctx context.Context, itemID string, slotIDs []string) (map[string]domain.SlotSignals, error)
+
+	SaveShadowComparisons(ctx context.Context, comparisons []domain.ShadowComparison) error
+
+	GetShadowReport(ctx context.Context, from, to *time.Time) ([]domain.ShadowAlgorithmReport, error)
+
+	SaveDecision(ctx context.Context, record *domain.DecisionRecord) (int64, error)
+
+	FindDecisions(ctx context.Context, filter domain.DecisionFilter) ([]domain.DecisionRecord, error)
+
+	GetDecision(ctx context.Context, decisionID int64) (*domain.DecisionRecord, error)
+
+	CreateJob(ctx context.Context, operation string, request []byte) (*domain.Job, error)
+
+	// ClaimJob берет самое старое задание из очереди и закрепляет его за экземпляром owner на lease
+	ClaimJob(ctx context.Context, owner string, lease time.Duration) (*domain.Job, error)
+	// ExtendJobLease продлевает аренду задания; false - задание больше не закреплено за owner
+	ExtendJobLease(ctx context.Context, jobID int64, owner string, lease time.Duration) (bool, error)
+	// CompleteJob и FailJob сохраняют итог задания, если оно все еще закреплено за owner, иначе ErrJobLeaseLost
+	CompleteJob(ctx context.Context, jobID int64, owner string, response []byte) error
+
+	FailJob(ctx context.Context, jobID int64, owner string, message string) error
+
+	GetJob(ctx context.Context, jobID int64) (*domain.Job, error)
+	// RequeueExpiredJobs возвращает в очередь задания с истекшей арендой, а задания, взятые
+	// в работу maxAttempts раз, переводит в failed
+	RequeueExpiredJobs(ctx context.Context, maxAttempts int) (requeued, failed int64, err error)
+
+	// PickStock списывает строки отбора. В атомарном режиме строки списываются одной транзакцией
+	// и ничего не записывается, если хотя бы одну строку отобрать не удалось
+	PickStock(ctx context.Context, lines []domain.PickRequest, atomic bool) ([]domain.PickResult, error)
+	// RevertPlacement отменяет размещение в ячейке, например строки отмененного пакета all_or_nothing;
+	// отмена пишется в журнал и outbox отдельным видом движения, а не как отбор
+	RevertPlacement(ctx context.Context, slotID, itemID, batchID string, quantity int) error
+
+	// FindStock возвращает остатки slot_stock по складу, ячейке, товару и партии
+	FindStock(ctx context.Context, filter domain.StockFilter) ([]domain.StockEntry, error)
+
+	// FindExpiringStock возвращает остатки партий склада, срок годности которых истек или истекает в ближайшие withinDays дней
+	FindExpiringStock(ctx context.Context, warehouseID string, withinDays int) ([]domain.ExpiryEntry, error)
+
+	// GetSlotStockTotals возвращает заполненность каждой ячейки склада вместе с суммой, весом и объемом ее остатков
+	GetSlotStockTotals(ctx context.Context, warehouseID string) ([]domain.StockDiscrepancy, error)
+
+	// GetReslotStock возвращает остатки склада с зоной и удаленностью ячеек и классификацией товара
+	GetReslotStock(ctx context.Context, warehouseID string) ([]domain.ReslotStock, error)
+
+	// FindReslotTargets возвращает ячейки зоны склада остатка, в которые он помещается, кроме exclude,
+	// по возрастанию distance_from_exit
+	FindReslotTargets(ctx context.Context, zoneType string, entry domain.StockEntry, exclude []string) ([]topology.SlotDistance, error)
+
+	// GetTravelDistances возвращает пути от ворот (пустой dockID - от ближайших) до ячеек склада; nil - топология склада не задана
+	GetTravelDistances(ctx context.Context, warehouseID, dockID string) (map[string]float64, error)
+
+	// MoveStock перемещает остаток партии между ячейками склада одной транзакцией; ErrSlotNotFound - ячейки нет на складе
+	MoveStock(ctx context.Context, req *domain.MoveRequest) (*domain.MoveResult, error)
+
+	// FindSlots возвращает ячейки справочника по складу, зоне и статусу
+	FindSlots(ctx context.Context, filter domain.SlotFilter) ([]domain.Slot, error)
+
+	// GetSlot возвращает ячейку или nil, если ее нет
+	GetSlot(ctx context.Context, slotID string) (*domain.Slot, error)
+
+	// SaveSlots создает и изменяет ячейки одной транзакцией и пишет изменения в журнал от имени changedBy;
+	// ErrSlotNotFound, ErrSlotExists и ErrSlotInUse - ячейки нельзя сохранить
+	SaveSlots(ctx context.Context, slots []domain.Slot, mode, changedBy string) ([]domain.SlotChange, error)
+
+	// RetireSlot выводит пустую ячейку из справочника; ErrSlotInUse - ячейка занята или зарезервирована
+	RetireSlot(ctx context.Context, slotID, changedBy string) (*domain.SlotChange, error)
+
+	// GetSlotAudit возвращает журнал изменений ячейки
+	GetSlotAudit(ctx context.Context, slotID string) ([]domain.SlotAuditRecord, error)
+}
@@ -0,0 +1,127 @@
+package scoring
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Названия бонусных слагаемых итоговой оценки
+const (
+	BonusHasFixedSlot       = "has_fixed_slot"
+	BonusHighWarehouseLoad  = "high_warehouse_load"
+	BonusHighTurnover       = "high_turnover"
+	BonusHeavyItem          = "heavy_item"
+	BonusNoPlacementHistory = "no_placement_history"
+	BonusFastAccessZone     = "fast_access_zone"
+	BonusXYZCompliant       = "xyz_compliant"
+)
+
+// Policy описывает политику расчета итоговой оценки ответа сервиса размещения
+type Policy struct {
+	Version      string               `json:"version"`
+	BaseScore    TermToggle           `json:"base_score"`
+	ResponseTime ResponseTimeTerm     `json:"response_time"`
+	Distance     DistanceTerm         `json:"distance"`
+	Bonuses      map[string]BonusTerm `json:"bonuses"`
+}
+
+// TermToggle включает или отключает слагаемое без дополнительных параметров
+type TermToggle struct {
+	Enabled bool `json:"enabled"`
+}
+
+// ResponseTimeTerm - слагаемое Δt: бонус за быстрый ответ и штраф за медленный
+type ResponseTimeTerm struct {
+	Enabled         bool    `json:"enabled"`
+	FastThresholdMs int64   `json:"fast_threshold_ms"`
+	FastBonus       float64 `json:"fast_bonus"`
+	SlowThresholdMs int64   `json:"slow_threshold_ms"`
+	SlowPenalty     float64 `json:"slow_penalty"`
+}
+
+// DistanceTerm - слагаемое Δd: бонус за близость к выходу и штраф за удаленность
+type DistanceTerm struct {
+	Enabled       bool    `json:"enabled"`
+	NearThreshold float64 `json:"near_threshold"`
+	NearBonus     float64 `json:"near_bonus"`
+	FarThreshold  float64 `json:"far_threshold"`
+	FarPenalty    float64 `json:"far_penalty"`
+}
+
+// BonusTerm - бонус, начисляемый при выполнении признака из ответа сервиса
+type BonusTerm struct {
+	Enabled bool    `json:"enabled"`
+	Weight  float64 `json:"weight"`
+}
+
+// DefaultPolicy возвращает политику, совпадающую с исходными захардкоженными коэффициентами
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Version:   "default",
+		BaseScore: TermToggle{Enabled: true},
+		ResponseTime: ResponseTimeTerm{
+			Enabled:         true,
+			FastThresholdMs: 300,
+			FastBonus:       0.03,
+			SlowThresholdMs: 1000,
+			SlowPenalty:     0.05,
+		},
+		Distance: DistanceTerm{
+			Enabled:       true,
+			NearThreshold: 5,
+			NearBonus:     0.05,
+			FarThreshold:  15,
+			FarPenalty:    0.03,
+		},
+		Bonuses: map[string]BonusTerm{
+			BonusHasFixedSlot:       {Enabled: true, Weight: 0.07},
+			BonusHighWarehouseLoad:  {Enabled: true, Weight: 0.05},
+			BonusHighTurnover:       {Enabled: true, Weight: 0.05},
+			BonusHeavyItem:          {Enabled: true, Weight: 0.03},
+			BonusNoPlacementHistory: {Enabled: true, Weight: 0.02},
+			BonusFastAccessZone:     {Enabled: true, Weight: 0.03},
+			BonusXYZCompliant:       {Enabled: true, Weight: 0.02},
+		},
+	}
+}
+
+// LoadPolicy читает политику из JSON-файла и проверяет ее корректность
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения файла политики: %w", err)
+	}
+
+	var policy Policy
+	if err := json.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("ошибка разбора файла политики: %w", err)
+	}
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &policy, nil
+}
+
+// Validate проверяет версию, пороги и названия бонусов
+func (p *Policy) Validate() error {
+	if p.Version == "" {
+		return fmt.Errorf("в политике не указана версия")
+	}
+	if p.ResponseTime.Enabled && p.ResponseTime.FastThresholdMs >= p.ResponseTime.SlowThresholdMs {
+		return fmt.Errorf("порог быстрого ответа (%d мс) должен быть меньше порога медленного (%d мс)",
+			p.ResponseTime.FastThresholdMs, p.ResponseTime.SlowThresholdMs)
+	}
+	if p.Distance.Enabled && p.Distance.NearThreshold >= p.Distance.FarThreshold {
+		return fmt.Errorf("порог близкой ячейки (%.2f) должен быть меньше порога дальней (%.2f)",
+			p.Distance.NearThreshold, p.Distance.FarThreshold)
+	}
+	for name := range p.Bonuses {
+		if _, ok := bonusFlags[name]; !ok {
+			return fmt.Errorf("неизвестный бонус в политике: %s", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package scoring
+
+import (
+	"sync"
+
+	"warehouse/services/orchestrator/internal/domain"
+)
+
+// bonusFlags сопоставляет название бонуса с признаком в ответе сервиса
+var bonusFlags = map[string]func(resp *domain.PlacementResponse) bool{
+	BonusHasFixedSlot:       func(resp *domain.PlacementResponse) bool { return resp.HasFixedSlot },
+	BonusHighWarehouseLoad:  func(resp *domain.PlacementResponse) bool { return resp.HighWarehouseLoad },
+	BonusHighTurnover:       func(resp *domain.PlacementResponse) bool { return resp.HighTurnover },
+	BonusHeavyItem:          func(resp *domain.PlacementResponse) bool { return resp.HeavyItem },
+	BonusNoPlacementHistory: func(resp *domain.PlacementResponse) bool { return resp.NoPlacementHistory },
+	BonusFastAccessZone:     func(resp *domain.PlacementResponse) bool { return resp.FastAccessZone },
+	BonusXYZCompliant:       func(resp *domain.PlacementResponse) bool { return resp.XYZCompliant },
+}
+
+// bonusOrder фиксирует порядок суммирования бонусов, чтобы итог не зависел от обхода map
+var bonusOrder = []string{
+	BonusHasFixedSlot,
+	BonusHighWarehouseLoad,
+	BonusHighTurnover,
+	BonusHeavyItem,
+	BonusNoPlacementHistory,
+	BonusFastAccessZone,
+	BonusXYZCompliant,
+}
+
+// Score раскладывает итоговую оценку ответа сервиса на слагаемые политики
+func (p *Policy) Score(resp *domain.PlacementResponse) domain.ScoreBreakdown {
+	breakdown := domain.ScoreBreakdown{
+		PolicyVersion: p.Version,
+		Bonuses:       make(map[string]float64),
+	}
+
+	if p.BaseScore.Enabled {
+		breakdown.BaseScore = resp.BaseScore
+	}
+
+	if p.ResponseTime.Enabled {
+		switch {
+		case resp.ResponseTimeMs < p.ResponseTime.FastThresholdMs:
+			breakdown.ResponseTime = p.ResponseTime.FastBonus
+		case resp.ResponseTimeMs > p.ResponseTime.SlowThresholdMs:
+			breakdown.ResponseTime = -p.ResponseTime.SlowPenalty
+		}
+	}
+
+	// Без расстояния от сервиса Δd не начисляется: ни бонус, ни штраф
+	if p.Distance.Enabled && resp.DistanceToExit != nil {
+		switch {
+		case *resp.DistanceToExit < p.Distance.NearThreshold:
+			breakdown.Distance = p.Distance.NearBonus
+		case *resp.DistanceToExit > p.Distance.FarThreshold:
+			breakdown.Distance = -p.Distance.FarPenalty
+		}
+	}
+
+	total := breakdown.BaseScore + breakdown.ResponseTime + breakdown.Distance
+	for _, name := range bonusOrder {
+		term, ok := p.Bonuses[name]
+		if !ok || !term.Enabled {
+			continue
+		}
+		value := 0.0
+		if bonusFlags[name](resp) {
+			value = term.Weight
+		}
+		breakdown.Bonuses[name] = value
+		total += value
+	}
+	breakdown.Total = total
+
+	return breakdown
+}
+
+// Store хранит текущую политику и позволяет перечитать ее из файла во время работы
+type Store struct {
+	mu     sync.RWMutex
+	path   string
+	policy *Policy
+}
+
+// NewStore загружает политику из файла; если путь не задан, используется политика по умолчанию
+func NewStore(path string) (*Store, error) {
+	store := &Store{path: path, policy: DefaultPolicy()}
+	if path == "" {
+		return store, nil
+	}
+	if _, err := store.Reload(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Current возвращает действующую политику
+func (s *Store) Current() *Policy {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.policy
+}
+
+// Reload перечитывает файл политики; при ошибке продолжает действовать прежняя политика
+func (s *Store) Reload() (*Policy, error) {
+	if s.path == "" {
+		return s.Current(), nil
+	}
+
+	policy, err := LoadPolicy(s.path)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.policy = policy
+	s.mu.Unlock()
+
+	return policy, nil
+}
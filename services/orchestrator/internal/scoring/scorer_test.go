@@ -0,0 +1,113 @@
+package scoring
+
+import (
+	"math"
+	"testing"
+
+	"warehouse/services/orchestrator/internal/domain"
+)
+
+func distance(value float64) *float64 {
+	return &value
+}
+
+func TestPolicyScore(t *testing.T) {
+	tests := []struct {
+		name string
+		resp domain.PlacementResponse
+		// base, responseTime, distance - ожидаемые S0, Δt и Δd
+		base, responseTime, distanceTerm float64
+		bonuses                          map[string]float64
+		total                            float64
+	}{
+		{
+			name:         "быстрый ответ, ячейка у выхода",
+			resp:         domain.PlacementResponse{Success: true, BaseScore: 0.9, ResponseTimeMs: 100, DistanceToExit: distance(2)},
+			base:         0.9,
+			responseTime: 0.03,
+			distanceTerm: 0.05,
+			total:        0.98,
+		},
+		{
+			name:         "медленный ответ, дальняя ячейка",
+			resp:         domain.PlacementResponse{Success: true, BaseScore: 0.6, ResponseTimeMs: 1500, DistanceToExit: distance(20)},
+			base:         0.6,
+			responseTime: -0.05,
+			distanceTerm: -0.03,
+			total:        0.52,
+		},
+		{
+			name:  "средние время и расстояние не дают поправок",
+			resp:  domain.PlacementResponse{Success: true, BaseScore: 0.75, ResponseTimeMs: 500, DistanceToExit: distance(10)},
+			base:  0.75,
+			total: 0.75,
+		},
+		{
+			name:         "без расстояния Δd не начисляется",
+			resp:         domain.PlacementResponse{Success: true, BaseScore: 0.9, ResponseTimeMs: 100},
+			base:         0.9,
+			responseTime: 0.03,
+			total:        0.93,
+		},
+		{
+			name:         "нулевое расстояние - ячейка у выхода",
+			resp:         domain.PlacementResponse{Success: true, BaseScore: 0.5, ResponseTimeMs: 500, DistanceToExit: distance(0)},
+			base:         0.5,
+			distanceTerm: 0.05,
+			total:        0.55,
+		},
+		{
+			name: "бонусы суммируются",
+			resp: domain.PlacementResponse{
+				Success: true, BaseScore: 0.9, ResponseTimeMs: 500, DistanceToExit: distance(10),
+				HasFixedSlot: true, HeavyItem: true,
+			},
+			base:    0.9,
+			bonuses: map[string]float64{BonusHasFixedSlot: 0.07, BonusHeavyItem: 0.03},
+			total:   1.0,
+		},
+	}
+
+	policy := DefaultPolicy()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breakdown := policy.Score(&tt.resp)
+
+			if breakdown.PolicyVersion != policy.Version {
+				t.Errorf("версия политики %q, ожидалась %q", breakdown.PolicyVersion, policy.Version)
+			}
+			checkTerm(t, "S0", breakdown.BaseScore, tt.base)
+			checkTerm(t, "Δt", breakdown.ResponseTime, tt.responseTime)
+			checkTerm(t, "Δd", breakdown.Distance, tt.distanceTerm)
+			for _, name := range bonusOrder {
+				checkTerm(t, name, breakdown.Bonuses[name], tt.bonuses[name])
+			}
+			checkTerm(t, "итог", breakdown.Total, tt.total)
+		})
+	}
+}
+
+func TestPolicyScoreDisabledTerms(t *testing.T) {
+	policy := DefaultPolicy()
+	policy.BaseScore.Enabled = false
+	policy.ResponseTime.Enabled = false
+	policy.Distance.Enabled = false
+	policy.Bonuses = map[string]BonusTerm{BonusHeavyItem: {Enabled: false, Weight: 0.03}}
+
+	breakdown := policy.Score(&domain.PlacementResponse{
+		Success: true, BaseScore: 0.9, ResponseTimeMs: 100, DistanceToExit: distance(2), HeavyItem: true,
+	})
+	if breakdown.Total != 0 {
+		t.Errorf("итог %v при отключенных слагаемых, ожидался 0", breakdown.Total)
+	}
+	if _, ok := breakdown.Bonuses[BonusHeavyItem]; ok {
+		t.Error("отключенный бонус попал в разложение оценки")
+	}
+}
+
+func checkTerm(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 1e-9 {
+		t.Errorf("%s = %v, ожидалось %v", name, got, want)
+	}
+}
@@ -0,0 +1,487 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"warehouse/services/orchestrator/internal/client"
+	"warehouse/services/orchestrator/internal/domain"
+)
+
+// batchLine - строка пакетного запроса вместе с ячейкой, зарезервированной для нее победителем
+type batchLine struct {
+	request *domain.PlacementRequest
+	winner  *domain.ServiceResult
+	result  domain.BatchLineResult
+	shadow  []domain.ServiceResult
+
+	analysis   *domain.OrchestratorResponse
+	enrichment *domain.EnrichmentReport
+
+	// options - подходящие строке ячейки от лучшей к худшей, по одному ответу сервиса на ячейку
+	options []domain.ServiceResult
+	// reservedUntil - когда истекает резерв победителя; перед подтверждением истекающий резерв продлевается
+	reservedUntil time.Time
+}
+
+// PlaceBatch размещает набор партий так, чтобы никакие две строки не попали в одну ячейку.
+// Ячейки распределяются сразу между всеми строками (reserveBatch), затем резервы подтверждаются.
+// В режиме all_or_nothing подтверждение начинается, только если ячейка зарезервирована для каждой
+// строки, а если подтвердить строку не удалось, уже размещенные строки отменяются
+func (s *OrchestratorService) PlaceBatch(ctx context.Context, req *domain.BatchPlacementRequest) (*domain.BatchPlacementResponse, error) {
+	mode := req.Mode
+	if mode == "" {
+		mode = domain.BatchModeBestEffort
+	}
+	if mode != domain.BatchModeBestEffort && mode != domain.BatchModeAllOrNothing {
+		return nil, fmt.Errorf("%w: неизвестный режим пакетного размещения: %s", ErrInvalidRequest, mode)
+	}
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("%w: пустой список строк", ErrInvalidRequest)
+	}
+	enrichments := make([]*domain.EnrichmentReport, len(req.Items))
+	for i := range req.Items {
+		enrichment, err := s.prepareRequest(ctx, &req.Items[i])
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: %w", i+1, err)
+		}
+		enrichments[i] = enrichment
+	}
+
+	lines := make([]*batchLine, len(req.Items))
+	for i := range req.Items {
+		lines[i] = &batchLine{
+			request: &req.Items[i],
+			result: domain.BatchLineResult{
+				Line:       i + 1,
+				ItemID:     req.Items[i].ItemID,
+				BatchID:    req.Items[i].BatchID,
+				Enrichment: enrichments[i],
+			},
+			enrichment: enrichments[i],
+		}
+		// Теневые сервисы опрашиваются до резервирования, чтобы резервы пакета не скрывали от них ячейки
+		lines[i].shadow = s.shadowAnalyze(lineContext(ctx, i+1), lines[i].request)
+	}
+
+	s.reserveBatch(ctx, lines)
+
+	if mode == domain.BatchModeAllOrNothing {
+		s.commitAllOrNothing(ctx, lines)
+		s.recordBatchLines(ctx, lines)
+		return s.batchResponse(mode, lines), nil
+	}
+
+	for _, line := range lines {
+		if line.winner == nil {
+			continue
+		}
+		lineCtx := lineContext(ctx, line.result.Line)
+		if !s.refreshBatchLine(lineCtx, line) || !s.commitBatchLine(lineCtx, line) {
+			s.replaceBatchLine(lineCtx, line)
+		}
+	}
+
+	s.recordBatchLines(ctx, lines)
+	return s.batchResponse(mode, lines), nil
+}
+
+// commitAllOrNothing подтверждает резервы пакета в режиме all_or_nothing. Подтверждение начинается,
+// только если для каждой строки есть резерв и ни один резерв не истек. Если подтвердить строку
+// не удалось, остальные резервы снимаются, а товар уже размещенных строк снимается из ячеек
+func (s *OrchestratorService) commitAllOrNothing(ctx context.Context, lines []*batchLine) {
+	for _, line := range lines {
+		if line.winner == nil {
+			s.cancelBatch(ctx, lines, "Размещение отменено: не для всех строк найдены ячейки")
+			return
+		}
+	}
+	for _, line := range lines {
+		if !s.refreshBatchLine(lineContext(ctx, line.result.Line), line) {
+			s.cancelBatch(ctx, lines, fmt.Sprintf("Размещение отменено: резерв строки %d истек и не продлен", line.result.Line))
+			return
+		}
+	}
+
+	for i, line := range lines {
+		if s.commitBatchLine(lineContext(ctx, line.result.Line), line) {
+			continue
+		}
+
+		reason := fmt.Sprintf("Размещение отменено: не удалось разместить строку %d", line.result.Line)
+		s.cancelBatch(ctx, lines[i+1:], reason)
+		for _, committed := range lines[:i] {
+			s.compensateBatchLine(ctx, committed, reason)
+		}
+		return
+	}
+}
+
+// cancelBatch снимает резервы строк и отмечает строки без собственной причины отказа причиной reason
+func (s *OrchestratorService) cancelBatch(ctx context.Context, lines []*batchLine, reason string) {
+	for _, line := range lines {
+		if line.winner == nil {
+			if line.result.Comment == "" {
+				line.result.Comment = reason
+			}
+			continue
+		}
+		s.releaseReservations(ctx, line.request, []domain.ServiceResult{*line.winner}, "")
+		line.winner = nil
+		line.result.Comment = reason
+	}
+}
+
+// compensateBatchLine отменяет размещение строки, которая уже размещена, когда пакет
+// all_or_nothing отменяется. Отмена записывается как movement 'reverted' и событие PlacementReverted,
+// а не как отбор. Если отменить размещение не удалось, строка остается размещенной:
+// ответ должен описывать фактическое состояние склада
+func (s *OrchestratorService) compensateBatchLine(ctx context.Context, line *batchLine, reason string) {
+	err := s.repo.RevertPlacement(context.WithoutCancel(ctx), line.result.SlotID, line.request.ItemID, line.request.BatchID, line.request.Quantity)
+	if err != nil {
+		log.Printf("Ошибка отмены размещения строки %d пакета в ячейке %s: %v", line.result.Line, line.result.SlotID, err)
+		line.result.Comment = fmt.Sprintf("%s, но товар не удалось снять из ячейки %s: %s", reason, line.result.SlotID, err.Error())
+		return
+	}
+
+	line.result.Success = false
+	line.result.Comment = fmt.Sprintf("%s, размещение в ячейке %s отменено", reason, line.result.SlotID)
+}
+
+// recordBatchLines сохраняет итог каждой строки в журнал решений, а предложения теневых
+// сервисов - рядом с этим итогом
+func (s *OrchestratorService) recordBatchLines(ctx context.Context, lines []*batchLine) {
+	for _, line := range lines {
+		decision := &domain.OrchestratorResponse{
+			Success:    line.result.Success,
+			SlotID:     line.result.SlotID,
+			Comment:    line.result.Comment,
+			Score:      line.result.Score,
+			Algorithm:  line.result.Algorithm,
+			Attempts:   line.result.Attempts,
+			Enrichment: line.enrichment,
+		}
+		if !decision.Success {
+			decision.SlotID = ""
+			decision.Score = 0
+		}
+		if line.analysis != nil {
+			decision.PolicyVersion = line.analysis.PolicyVersion
+			decision.QuorumReached = line.analysis.QuorumReached
+			decision.SignalSources = line.analysis.SignalSources
+			decision.AllResults = line.analysis.AllResults
+		}
+		decision.ShadowResults = line.shadow
+
+		s.recordShadowComparisons(ctx, line.request, line.shadow, decision)
+		s.recordDecision(ctx, domain.DecisionOperationBatchLine, line.request, decision)
+		line.result.DecisionID = decision.DecisionID
+	}
+}
+
+// lineContext выделяет строке пакета собственный ключ идемпотентности для запросов к сервисам
+func lineContext(ctx context.Context, lineNumber int) context.Context {
+	if key := client.IdempotencyKey(ctx); key != "" {
+		return client.WithIdempotencyKey(ctx, fmt.Sprintf("%s:line-%d", key, lineNumber))
+	}
+	return ctx
+}
+
+// reserveBatch резервирует ячейки для всех строк пакета. Сначала боевые сервисы предлагают каждой
+// строке подходящие ячейки, и ячейки распределяются сразу между всеми строками (assignSlots), чтобы
+// строка не заняла ячейку, без которой не разместить другую. Строки, которым ячейка не досталась или
+// распределенную ячейку успел занять параллельный запрос, резервируются обычным опросом по очереди
+func (s *OrchestratorService) reserveBatch(ctx context.Context, lines []*batchLine) {
+	options := make([][]string, len(lines))
+	for i, line := range lines {
+		// Строке не нужно больше ячеек, чем строк в пакете: остальные строки займут не больше len(lines)-1
+		s.batchCandidates(lineContext(ctx, line.result.Line), line, len(lines))
+		for _, option := range line.options {
+			options[i] = append(options[i], line.request.WarehouseID+"/"+option.Response.SlotID)
+		}
+	}
+
+	assignment := assignSlots(options)
+	for i, line := range lines {
+		if assignment[i] >= 0 {
+			s.reserveAssignedSlot(lineContext(ctx, line.result.Line), line, line.options[assignment[i]])
+		}
+	}
+	for _, line := range lines {
+		if line.winner == nil {
+			s.reserveBatchLine(lineContext(ctx, line.result.Line), line)
+		}
+	}
+}
+
+// batchCandidates запрашивает у боевых сервисов до limit подходящих строке ячеек и ранжирует их
+// по действующей политике. Сервис без потоковой выдачи кандидатов (HTTP) предлагает одну ячейку, как analyze
+func (s *OrchestratorService) batchCandidates(ctx context.Context, line *batchLine, limit int) {
+	req := line.request
+	if req.DeadlineMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(req.DeadlineMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var results []domain.ServiceResult
+	for serviceID, placementClient := range s.clients {
+		if s.isShadow(serviceID) {
+			continue
+		}
+		if placementClient.CircuitState() == client.StateOpen {
+			results = append(results, skippedResult(serviceID, s.config.Services[serviceID].Name, client.StateOpen))
+			continue
+		}
+
+		wg.Add(1)
+		go func(serviceID string, placementClient *client.PlacementClient) {
+			defer wg.Done()
+			serviceResults := s.serviceCandidates(ctx, serviceID, placementClient, req, limit)
+
+			mu.Lock()
+			results = append(results, serviceResults...)
+			mu.Unlock()
+		}(serviceID, placementClient)
+	}
+	wg.Wait()
+
+	line.analysis = s.buildResponse(ctx, req, results, false)
+	line.options = slotOptions(line.analysis.AllResults)
+}
+
+// serviceCandidates запрашивает кандидатов у одного сервиса и возвращает результат опроса на каждую ячейку
+func (s *OrchestratorService) serviceCandidates(ctx context.Context, serviceID string, placementClient *client.PlacementClient, req *domain.PlacementRequest, limit int) []domain.ServiceResult {
+	var candidates []domain.PlacementResponse
+	result := s.callService(ctx, serviceID, placementClient, func(ctx context.Context, placementClient *client.PlacementClient) (*domain.PlacementResponse, error) {
+		streamed, err := placementClient.StreamCandidates(ctx, req, limit)
+		if errors.Is(err, client.ErrStreamingUnsupported) {
+			return placementClient.AnalyzePlacement(ctx, req)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(streamed) == 0 {
+			return &domain.PlacementResponse{Success: false, Comment: "Сервис не предложил ни одной ячейки", Score: 0}, nil
+		}
+		candidates = streamed
+		return &streamed[0], nil
+	})
+	if len(candidates) < 2 {
+		return []domain.ServiceResult{result}
+	}
+
+	results := make([]domain.ServiceResult, len(candidates))
+	for i := range candidates {
+		results[i] = result
+		results[i].Response = candidates[i]
+	}
+	return results
+}
+
+// slotOptions оставляет успешные ответы в порядке рейтинга, по одному на ячейку: ячейку, которую
+// предложили несколько сервисов, резервирует тот, чей ответ оценен выше
+func slotOptions(results []domain.ServiceResult) []domain.ServiceResult {
+	var options []domain.ServiceResult
+	seen := make(map[string]bool)
+	for _, result := range results {
+		if !result.Response.Success || seen[result.Response.SlotID] {
+			continue
+		}
+		seen[result.Response.SlotID] = true
+		options = append(options, result)
+	}
+	return options
+}
+
+// assignSlots распределяет ячейки между строками пакета: options[i] - ячейки, подходящие строке i,
+// от лучшей к худшей. Возвращает для каждой строки индекс ее ячейки в options[i] или -1, если ячейки
+// не хватило. Распределение максимальное (алгоритм Куна): строка получает ячейку, если для этого
+// достаточно переставить ячейки других строк. Строки рассматриваются по порядку и пробуют ячейки
+// от лучшей к худшей, поэтому без конфликтов каждая строка получает свою лучшую ячейку
+func assignSlots(options [][]string) []int {
+	assigned := make([]int, len(options))
+	for i := range assigned {
+		assigned[i] = -1
+	}
+	owner := make(map[string]int)
+
+	var augment func(line int, visited map[string]bool) bool
+	augment = func(line int, visited map[string]bool) bool {
+		for i, slot := range options[line] {
+			if visited[slot] {
+				continue
+			}
+			visited[slot] = true
+
+			other, taken := owner[slot]
+			if !taken || augment(other, visited) {
+				owner[slot] = line
+				assigned[line] = i
+				return true
+			}
+		}
+		return false
+	}
+
+	for line := range options {
+		augment(line, make(map[string]bool))
+	}
+	return assigned
+}
+
+// reserveAssignedSlot резервирует для строки распределенную ей ячейку в сервисе, который ее предложил
+func (s *OrchestratorService) reserveAssignedSlot(ctx context.Context, line *batchLine, option domain.ServiceResult) {
+	if key := client.IdempotencyKey(ctx); key != "" {
+		ctx = client.WithIdempotencyKey(ctx, key+":assigned")
+	}
+
+	reservedUntil := time.Now().Add(s.config.ReservationTTL)
+	resp, err := s.clients[option.ServiceID].ReserveSlot(ctx, line.request, option.Response.SlotID, s.config.ReservationTTL)
+	if err != nil {
+		log.Printf("Ошибка резервирования ячейки %s в сервисе %s: %v", option.Response.SlotID, option.ServiceName, err)
+		return
+	}
+	if !resp.Success {
+		// Ячейку успел занять параллельный запрос; строка будет зарезервирована обычным опросом
+		return
+	}
+
+	winner := option
+	winner.Response.ReservationToken = resp.ReservationToken
+	winner.Response.ReservedUntil = resp.ReservedUntil
+	line.setWinner(&winner, reservedUntil)
+}
+
+// reserveBatchLine опрашивает сервисы командой reserve для одной строки и оставляет только резерв победителя
+func (s *OrchestratorService) reserveBatchLine(ctx context.Context, line *batchLine) {
+	req := line.request
+	reservedUntil := time.Now().Add(s.config.ReservationTTL)
+	results, quorumReached := s.fanOut(ctx, req, true, func(ctx context.Context, placementClient *client.PlacementClient) (*domain.PlacementResponse, error) {
+		return placementClient.ReservePlacement(ctx, req, s.config.ReservationTTL)
+	})
+
+	analysis := s.buildResponse(ctx, req, results, quorumReached)
+	line.analysis = analysis
+	if !analysis.Success {
+		s.releaseReservations(ctx, req, results, "")
+		line.result.Comment = analysis.Comment
+		if line.result.Comment == "" {
+			line.result.Comment = "Ни один сервис не предложил ячейку"
+		}
+		return
+	}
+
+	winner := analysis.AllResults[0]
+	s.releaseReservations(ctx, req, results, winner.ServiceID)
+	line.setWinner(&winner, reservedUntil)
+}
+
+// setWinner закрепляет за строкой резерв победителя
+func (line *batchLine) setWinner(winner *domain.ServiceResult, reservedUntil time.Time) {
+	line.winner = winner
+	line.reservedUntil = reservedUntil
+	line.result.SlotID = winner.Response.SlotID
+	line.result.Algorithm = winner.ServiceName
+	line.result.Score = winner.FinalScore
+}
+
+// refreshBatchLine продлевает резерв строки, если до его истечения осталось меньше половины
+// ReservationTTL: строки резервируются и подтверждаются по очереди, и резервы первых строк могут
+// истечь до их подтверждения. Резерв продлевается снятием и повторным резервированием той же ячейки;
+// если ее за это время занял параллельный запрос, строка остается без резерва
+func (s *OrchestratorService) refreshBatchLine(ctx context.Context, line *batchLine) bool {
+	if time.Until(line.reservedUntil) >= s.config.ReservationTTL/2 {
+		return true
+	}
+
+	winner := *line.winner
+	s.releaseReservations(ctx, line.request, []domain.ServiceResult{winner}, "")
+	line.winner = nil
+
+	if key := client.IdempotencyKey(ctx); key != "" {
+		ctx = client.WithIdempotencyKey(ctx, key+":refresh")
+	}
+	reservedUntil := time.Now().Add(s.config.ReservationTTL)
+	resp, err := s.clients[winner.ServiceID].ReserveSlot(ctx, line.request, winner.Response.SlotID, s.config.ReservationTTL)
+	if err != nil {
+		line.result.Comment = fmt.Sprintf("Не удалось продлить резерв ячейки %s: %s", winner.Response.SlotID, err.Error())
+		return false
+	}
+	if !resp.Success {
+		line.result.Comment = fmt.Sprintf("Не удалось продлить резерв ячейки %s: %s", winner.Response.SlotID, resp.Comment)
+		return false
+	}
+
+	winner.Response.ReservationToken = resp.ReservationToken
+	winner.Response.ReservedUntil = resp.ReservedUntil
+	line.setWinner(&winner, reservedUntil)
+	return true
+}
+
+// commitBatchLine подтверждает резерв строки; если это не удалось, резерв снимается
+func (s *OrchestratorService) commitBatchLine(ctx context.Context, line *batchLine) bool {
+	resp, attempt := s.commitCandidate(ctx, line.request, *line.winner)
+	attempt.Attempt = len(line.result.Attempts) + 1
+	line.result.Attempts = append(line.result.Attempts, attempt)
+	if attempt.Success {
+		line.result.Success = true
+		line.result.SlotID = resp.SlotID
+		line.result.Comment = resp.Comment
+		return true
+	}
+
+	s.releaseReservations(ctx, line.request, []domain.ServiceResult{*line.winner}, "")
+	line.winner = nil
+	line.result.Comment = attempt.Comment
+	return false
+}
+
+// replaceBatchLine размещает строку, резерв которой не удалось подтвердить или продлить, заново
+// тем же перебором кандидатов, что и PlaceItem. Решение по строке сохраняет recordBatchLines,
+// поэтому собственное решение place для повтора не записывается
+func (s *OrchestratorService) replaceBatchLine(ctx context.Context, line *batchLine) {
+	if key := client.IdempotencyKey(ctx); key != "" {
+		ctx = client.WithIdempotencyKey(ctx, key+":retry")
+	}
+	retry := s.placeRanked(ctx, line.request, line.enrichment)
+	line.analysis = retry
+	for _, retryAttempt := range retry.Attempts {
+		retryAttempt.Attempt = len(line.result.Attempts) + 1
+		line.result.Attempts = append(line.result.Attempts, retryAttempt)
+	}
+
+	line.result.Success = retry.Success
+	line.result.SlotID = retry.SlotID
+	line.result.Algorithm = retry.Algorithm
+	line.result.Score = retry.Score
+	line.result.Comment = retry.Comment
+}
+
+// batchResponse собирает построчные результаты и сводку
+func (s *OrchestratorService) batchResponse(mode string, lines []*batchLine) *domain.BatchPlacementResponse {
+	response := &domain.BatchPlacementResponse{
+		Mode:  mode,
+		Lines: make([]domain.BatchLineResult, 0, len(lines)),
+	}
+
+	for _, line := range lines {
+		if !line.result.Success {
+			line.result.SlotID = ""
+			response.Summary.Failed++
+		} else {
+			response.Summary.Placed++
+		}
+		response.Lines = append(response.Lines, line.result)
+	}
+	response.Summary.Total = len(lines)
+	response.Success = response.Summary.Failed == 0
+
+	return response
+}
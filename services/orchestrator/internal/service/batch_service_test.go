@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"warehouse/services/orchestrator/internal/domain"
+	"warehouse/services/orchestrator/internal/repository"
+)
+
+func TestAssignSlots(t *testing.T) {
+	tests := []struct {
+		name    string
+		options [][]string
+		want    []int
+	}{
+		{
+			name:    "без конфликтов каждая строка получает лучшую ячейку",
+			options: [][]string{{"A", "B"}, {"C", "D"}},
+			want:    []int{0, 0},
+		},
+		{
+			name:    "первая строка уступает ячейку, без которой не разместить вторую",
+			options: [][]string{{"A", "B"}, {"A"}},
+			want:    []int{1, 0},
+		},
+		{
+			name:    "перестановка через цепочку строк",
+			options: [][]string{{"A", "B"}, {"B", "C"}, {"A"}},
+			want:    []int{1, 1, 0},
+		},
+		{
+			name:    "ячеек меньше, чем строк",
+			options: [][]string{{"A"}, {"A"}, {"A", "B"}},
+			want:    []int{0, -1, 1},
+		},
+		{
+			name:    "строка без подходящих ячеек",
+			options: [][]string{{"A"}, nil},
+			want:    []int{0, -1},
+		},
+		{
+			name:    "одна ячейка на разных складах - разные ячейки",
+			options: [][]string{{"WH001/A"}, {"WH002/A"}},
+			want:    []int{0, 0},
+		},
+		{
+			name:    "пустой пакет",
+			options: [][]string{},
+			want:    []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := assignSlots(tt.options)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("assignSlots() = %v, ожидалось %v", got, tt.want)
+			}
+
+			used := make(map[string]int)
+			for line, index := range got {
+				if index < 0 {
+					continue
+				}
+				slot := tt.options[line][index]
+				if other, ok := used[slot]; ok {
+					t.Errorf("ячейка %s досталась строкам %d и %d", slot, other+1, line+1)
+				}
+				used[slot] = line
+			}
+		})
+	}
+}
+
+// revertRepo запоминает отмененные размещения; отбор пакету отмены не нужен, и его вызов роняет тест
+type revertRepo struct {
+	repository.Repository
+	reverted []string
+	err      error
+}
+
+func (r *revertRepo) RevertPlacement(ctx context.Context, slotID, itemID, batchID string, quantity int) error {
+	r.reverted = append(r.reverted, slotID+"/"+itemID+"/"+batchID)
+	return r.err
+}
+
+func TestCompensateBatchLine(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantSuccess bool
+		wantComment string
+	}{
+		{
+			name:        "размещение отменено",
+			wantSuccess: false,
+			wantComment: "размещение в ячейке SLOT001 отменено",
+		},
+		{
+			name:        "отменить не удалось - строка остается размещенной",
+			err:         errors.New("недостаточно товара в ячейке"),
+			wantSuccess: true,
+			wantComment: "товар не удалось снять из ячейки SLOT001",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &revertRepo{err: tt.err}
+			s := &OrchestratorService{repo: repo}
+			line := &batchLine{
+				request: &domain.PlacementRequest{ItemID: "ITEM001", BatchID: "BATCH001", Quantity: 5},
+				result:  domain.BatchLineResult{Line: 1, Success: true, SlotID: "SLOT001"},
+			}
+
+			s.compensateBatchLine(context.Background(), line, "Размещение отменено")
+
+			if want := []string{"SLOT001/ITEM001/BATCH001"}; !reflect.DeepEqual(repo.reverted, want) {
+				t.Errorf("отменены размещения %v, ожидалось %v", repo.reverted, want)
+			}
+			if line.result.Success != tt.wantSuccess {
+				t.Errorf("success = %v, ожидалось %v", line.result.Success, tt.wantSuccess)
+			}
+			if !strings.Contains(line.result.Comment, tt.wantComment) {
+				t.Errorf("комментарий %q не содержит %q", line.result.Comment, tt.wantComment)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"warehouse/services/orchestrator/internal/client"
+	"warehouse/services/orchestrator/internal/domain"
+)
+
+// Candidates запрашивает у одного сервиса до limit подходящих ячеек, начиная с лучшей.
+// Сервис должен быть подключен по gRPC: потоковая выдача есть только в контракте placement.v1
+func (s *OrchestratorService) Candidates(ctx context.Context, serviceID string, req *domain.PlacementRequest, limit int) (*domain.CandidatesResponse, error) {
+	placementClient, ok := s.clients[serviceID]
+	if !ok {
+		return nil, fmt.Errorf("%w: неизвестный сервис: %s", ErrInvalidRequest, serviceID)
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("%w: limit не может быть отрицательным", ErrInvalidRequest)
+	}
+
+	enrichment, err := s.enrichRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	candidates, err := placementClient.StreamCandidates(ctx, req, limit)
+	if errors.Is(err, client.ErrStreamingUnsupported) {
+		return nil, fmt.Errorf("%w: сервис %s: %s", ErrInvalidRequest, serviceID, err.Error())
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка запроса кандидатов у сервиса %s: %w", serviceID, err)
+	}
+
+	return &domain.CandidatesResponse{
+		ServiceID:   serviceID,
+		ServiceName: s.config.Services[serviceID].Name,
+		Candidates:  candidates,
+		Enrichment:  enrichment,
+	}, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"warehouse/services/orchestrator/internal/domain"
+)
+
+// ErrDecisionNotFound означает, что в журнале нет решения с таким идентификатором
+var ErrDecisionNotFound = errors.New("решение не найдено")
+
+const (
+	defaultDecisionLimit = 100
+	maxDecisionLimit     = 1000
+)
+
+// recordDecision сохраняет решение оркестратора в журнал и проставляет его идентификатор в ответе.
+// Ошибка журнала только логируется: размещение к этому моменту уже выполнено
+func (s *OrchestratorService) recordDecision(ctx context.Context, operation string, req *domain.PlacementRequest, response *domain.OrchestratorResponse) {
+	requestJSON, err := json.Marshal(req)
+	if err != nil {
+		log.Printf("Ошибка сериализации запроса для журнала решений: %v", err)
+		return
+	}
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		log.Printf("Ошибка сериализации ответа для журнала решений: %v", err)
+		return
+	}
+
+	record := &domain.DecisionRecord{
+		Operation:     operation,
+		ItemID:        req.ItemID,
+		BatchID:       req.BatchID,
+		Success:       response.Success,
+		Algorithm:     response.Algorithm,
+		Score:         response.Score,
+		PolicyVersion: response.PolicyVersion,
+		Request:       requestJSON,
+		Response:      responseJSON,
+	}
+	if response.Success {
+		record.SlotID = response.SlotID
+	}
+
+	decisionID, err := s.repo.SaveDecision(context.WithoutCancel(ctx), record)
+	if err != nil {
+		log.Printf("Ошибка сохранения решения в журнал: %v", err)
+		return
+	}
+	response.DecisionID = decisionID
+}
+
+// Decisions ищет решения в журнале по товару, партии, ячейке и периоду
+func (s *OrchestratorService) Decisions(ctx context.Context, filter domain.DecisionFilter) ([]domain.DecisionRecord, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultDecisionLimit
+	}
+	if filter.Limit > maxDecisionLimit {
+		filter.Limit = maxDecisionLimit
+	}
+
+	records, err := s.repo.FindDecisions(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if records == nil {
+		records = []domain.DecisionRecord{}
+	}
+	return records, nil
+}
+
+// Decision возвращает одно решение из журнала
+func (s *OrchestratorService) Decision(ctx context.Context, decisionID int64) (*domain.DecisionRecord, error) {
+	record, err := s.repo.GetDecision(ctx, decisionID)
+	if err != nil {
+		return nil, err
+	}
+	if record == nil {
+		return nil, ErrDecisionNotFound
+	}
+	return record, nil
+}
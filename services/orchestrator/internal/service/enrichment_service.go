@@ -0,0 +1,174 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"warehouse/pkg/placement"
+	"warehouse/services/orchestrator/internal/client"
+	"warehouse/services/orchestrator/internal/domain"
+)
+
+// ErrMasterDataConflict означает, что параметры запроса расходятся со справочником товаров,
+// а политика расхождений требует отклонить запрос
+var ErrMasterDataConflict = errors.New("параметры запроса расходятся со справочником товаров")
+
+// masterDataTolerance - допустимая относительная погрешность при сравнении числовых параметров
+const masterDataTolerance = 1e-6
+
+// enrichRequest дополняет запрос мастер-данными товара и партии: заполняет не переданные
+// параметры, сверяет переданные со справочником и рассчитывает HasFixedSlot и WarehouseLoad
+// по данным склада запроса вместо значений клиента
+func (s *OrchestratorService) enrichRequest(ctx context.Context, req *domain.PlacementRequest) (*domain.EnrichmentReport, error) {
+	policy := s.config.ConflictPolicy
+	if policy != domain.ConflictPolicyReject {
+		policy = domain.ConflictPolicyWarn
+	}
+	report := &domain.EnrichmentReport{ConflictPolicy: policy}
+
+	if req.ItemID == "" {
+		return nil, fmt.Errorf("%w: не указан item_id", ErrInvalidRequest)
+	}
+
+	warehouseID, err := s.resolveWarehouse(ctx, req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	if req.WarehouseID == "" {
+		req.WarehouseID = warehouseID
+		report.Filled = append(report.Filled, "warehouse_id")
+	}
+
+	item, err := s.repo.GetItem(ctx, req.ItemID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка загрузки товара %s: %w", req.ItemID, err)
+	}
+	if item == nil {
+		return nil, fmt.Errorf("%w: товар %s не найден", ErrInvalidRequest, req.ItemID)
+	}
+
+	if req.BatchID != "" {
+		batch, err := s.repo.GetBatch(ctx, req.BatchID)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка загрузки партии %s: %w", req.BatchID, err)
+		}
+		if batch == nil {
+			return nil, fmt.Errorf("%w: партия %s не найдена", ErrInvalidRequest, req.BatchID)
+		}
+		if batch.ItemID != item.ItemID {
+			return nil, fmt.Errorf("%w: партия %s относится к товару %s, а не %s", ErrInvalidRequest, batch.BatchID, batch.ItemID, item.ItemID)
+		}
+		if req.Quantity == 0 {
+			req.Quantity = batch.Quantity
+			report.Filled = append(report.Filled, "quantity")
+		} else if req.Quantity > batch.Quantity {
+			report.Conflicts = append(report.Conflicts, domain.FieldConflict{Field: "quantity", Supplied: req.Quantity, Stored: batch.Quantity})
+			req.Quantity = batch.Quantity
+		}
+	}
+
+	mergeFloat(report, "weight", &req.Weight, item.Weight)
+	mergeFloat(report, "volume", &req.Volume, item.Length*item.Width*item.Height)
+	mergeFloat(report, "turnover_rate", &req.TurnoverRate, item.Turnover)
+	mergeClass(report, "abc_class", &req.ABCClass, placement.ABCClass(item.Turnover))
+	mergeClass(report, "xyz_class", &req.XYZClass, placement.XYZClass(item.Mr))
+	mergeBool(report, "is_heavy", &req.IsHeavy, item.IsHeavy)
+	mergeBool(report, "is_fragile", &req.IsFragile, item.IsFragile)
+	mergeBool(report, "is_hazardous", &req.IsHazardous, item.IsHazardous)
+	if item.StorageTemp != nil {
+		mergeFloat(report, "storage_temp", &req.StorageTemp, *item.StorageTemp)
+	}
+	if item.StorageHumidity != nil {
+		mergeFloat(report, "storage_humidity", &req.StorageHumidity, *item.StorageHumidity)
+	}
+
+	if policy == domain.ConflictPolicyReject && len(report.Conflicts) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMasterDataConflict, describeConflicts(report.Conflicts))
+	}
+
+	hasFixedSlot, err := s.repo.HasFixedSlot(ctx, req.WarehouseID, item.ItemID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка проверки закрепленной ячейки: %w", err)
+	}
+	req.HasFixedSlot = hasFixedSlot
+
+	load, err := s.repo.GetWarehouseLoad(ctx, req.WarehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расчета загрузки склада: %w", err)
+	}
+	req.WarehouseLoad = load
+	report.Computed = append(report.Computed, "has_fixed_slot", "warehouse_load")
+
+	return report, nil
+}
+
+// prepareRequest проверяет запрос и дополняет его мастер-данными перед опросом сервисов
+func (s *OrchestratorService) prepareRequest(ctx context.Context, req *domain.PlacementRequest) (*domain.EnrichmentReport, error) {
+	if err := s.validateQuorum(req); err != nil {
+		return nil, err
+	}
+	report, err := s.enrichRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	// Запрос, который сервисы отклонят по контракту, отклоняется сразу, а не шестью ответами 400
+	if err := client.ValidateRequest(req); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+	}
+	return report, nil
+}
+
+// mergeFloat заполняет нулевое значение из справочника или фиксирует расхождение
+func mergeFloat(report *domain.EnrichmentReport, field string, value *float64, stored float64) {
+	if *value == 0 {
+		if stored != 0 {
+			*value = stored
+			report.Filled = append(report.Filled, field)
+		}
+		return
+	}
+	if math.Abs(*value-stored) > masterDataTolerance*math.Max(1, math.Abs(stored)) {
+		report.Conflicts = append(report.Conflicts, domain.FieldConflict{Field: field, Supplied: *value, Stored: stored})
+		*value = stored
+	}
+}
+
+// mergeClass заполняет пустую категорию из справочника или фиксирует расхождение без учета регистра
+func mergeClass(report *domain.EnrichmentReport, field string, value *string, stored string) {
+	if *value == "" {
+		*value = stored
+		report.Filled = append(report.Filled, field)
+		return
+	}
+	if !strings.EqualFold(*value, stored) {
+		report.Conflicts = append(report.Conflicts, domain.FieldConflict{Field: field, Supplied: *value, Stored: stored})
+	}
+	*value = stored
+}
+
+// mergeBool дополняет признак из справочника. Не переданный признак неотличим от false,
+// поэтому расхождением считается только true в запросе при false в справочнике
+func mergeBool(report *domain.EnrichmentReport, field string, value *bool, stored bool) {
+	if *value == stored {
+		return
+	}
+	if stored {
+		*value = true
+		report.Filled = append(report.Filled, field)
+		return
+	}
+	report.Conflicts = append(report.Conflicts, domain.FieldConflict{Field: field, Supplied: *value, Stored: stored})
+	*value = false
+}
+
+// describeConflicts перечисляет расхождения для сообщения об ошибке
+func describeConflicts(conflicts []domain.FieldConflict) string {
+	parts := make([]string, 0, len(conflicts))
+	for _, conflict := range conflicts {
+		parts = append(parts, fmt.Sprintf("%s (запрос: %v, справочник: %v)", conflict.Field, conflict.Supplied, conflict.Stored))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+
+	"warehouse/services/orchestrator/internal/client"
+)
+
+const idempotencyScope = "orchestrator"
+
+var (
+	ErrIdempotencyKeyReused        = errors.New("ключ идемпотентности уже использован с другим запросом")
+	ErrIdempotentRequestInProgress = errors.New("запрос с этим ключом идемпотентности еще выполняется")
+)
+
+// ExecuteIdempotent выполняет операцию оркестратора не более одного раза на ключ идемпотентности и
+// возвращает ответ в виде JSON. Повтор с тем же ключом и телом возвращает сохраненный ответ, другое тело
+// отклоняется. Ключ также передается сервисам размещения, чтобы повтор не занял вторую ячейку,
+// даже если оркестратор упал, не успев сохранить ответ
+func (s *OrchestratorService) ExecuteIdempotent(ctx context.Context, key, operation string, req interface{}, run func(ctx context.Context) (interface{}, error)) ([]byte, error) {
+	if key == "" {
+		response, err := run(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(response)
+	}
+
+	fingerprint, err := operationFingerprint(operation, req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка вычисления отпечатка запроса: %w", err)
+	}
+
+	record, created, err := s.repo.BeginIdempotentRequest(ctx, idempotencyScope, key, fingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка регистрации ключа идемпотентности: %w", err)
+	}
+
+	if !created {
+		if record.RequestHash != fingerprint {
+			return nil, ErrIdempotencyKeyReused
+		}
+		if !record.Completed {
+			return nil, ErrIdempotentRequestInProgress
+		}
+		return record.Response, nil
+	}
+
+	response, err := run(client.WithIdempotencyKey(ctx, key))
+	if err != nil {
+		if delErr := s.repo.DeleteIdempotentRequest(ctx, idempotencyScope, key); delErr != nil {
+			log.Printf("Ошибка удаления ключа идемпотентности: %v", delErr)
+		}
+		return nil, err
+	}
+
+	body, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сериализации ответа: %w", err)
+	}
+	if err := s.repo.CompleteIdempotentRequest(ctx, idempotencyScope, key, body); err != nil {
+		log.Printf("Ошибка сохранения ответа идемпотентного запроса: %v", err)
+	}
+
+	return body, nil
+}
+
+// operationFingerprint вычисляет хеш операции и тела запроса
+func operationFingerprint(operation string, req interface{}) (string, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(append([]byte(operation+"\n"), body...))
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"warehouse/services/orchestrator/internal/client"
+	"warehouse/services/orchestrator/internal/domain"
+)
+
+// ErrJobNotFound означает, что задания с таким идентификатором нет
+var ErrJobNotFound = errors.New("задание не найдено")
+
+// SubmitJob сохраняет запрос в очередь заданий и сразу возвращает идентификатор задания.
+// Запрос выполнит один из обработчиков StartJobWorkers, результат доступен через Job
+func (s *OrchestratorService) SubmitJob(ctx context.Context, operation string, req interface{}) (*domain.JobSubmission, error) {
+	switch operation {
+	case domain.JobOperationAnalyze, domain.JobOperationPlace, domain.JobOperationPlaceBatch:
+	default:
+		return nil, fmt.Errorf("%w: неизвестная операция задания: %s", ErrInvalidRequest, operation)
+	}
+
+	request, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сериализации запроса: %w", err)
+	}
+
+	job, err := s.repo.CreateJob(ctx, operation, request)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка постановки задания в очередь: %w", err)
+	}
+
+	select {
+	case s.jobQueued <- struct{}{}:
+	default:
+	}
+
+	return &domain.JobSubmission{
+		JobID:     job.JobID,
+		Operation: job.Operation,
+		State:     job.State,
+		StatusURL: fmt.Sprintf("/jobs/%d", job.JobID),
+	}, nil
+}
+
+// Job возвращает состояние задания и, если оно выполнено, ответ оркестратора
+func (s *OrchestratorService) Job(ctx context.Context, jobID int64) (*domain.Job, error) {
+	job, err := s.repo.GetJob(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// StartJobWorkers запускает пул обработчиков и возврат в очередь прерванных заданий (reapJobs).
+// Блокируется до отмены контекста и завершения обработчиков
+func (s *OrchestratorService) StartJobWorkers(ctx context.Context) {
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		s.reapJobs(ctx)
+	}()
+	for i := 0; i < s.config.JobWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.jobWorker(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+// reapJobs периодически возвращает в очередь задания с истекшей арендой: выполнявший их экземпляр
+// остановился или завис. Задания работающих экземпляров не трогаются - те продлевают аренду
+func (s *OrchestratorService) reapJobs(ctx context.Context) {
+	ticker := time.NewTicker(s.config.JobLeaseTTL / 2)
+	defer ticker.Stop()
+
+	for {
+		requeued, failed, err := s.repo.RequeueExpiredJobs(ctx, s.config.JobMaxAttempts)
+		switch {
+		case err != nil:
+			if ctx.Err() == nil {
+				log.Printf("Ошибка возврата прерванных заданий в очередь: %v", err)
+			}
+		case requeued > 0 || failed > 0:
+			log.Printf("Прерванные задания: возвращено в очередь %d, исчерпали попытки %d", requeued, failed)
+			if requeued > 0 {
+				select {
+				case s.jobQueued <- struct{}{}:
+				default:
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// jobWorker выполняет задания, пока очередь не опустеет, затем ждет сигнала о новом задании
+// или очередного опроса базы: задания могли поставить другие экземпляры оркестратора
+func (s *OrchestratorService) jobWorker(ctx context.Context) {
+	ticker := time.NewTicker(s.config.JobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for ctx.Err() == nil {
+			job, err := s.repo.ClaimJob(ctx, s.config.InstanceID, s.config.JobLeaseTTL)
+			if err != nil {
+				log.Printf("Ошибка получения задания из очереди: %v", err)
+				break
+			}
+			if job == nil {
+				break
+			}
+			s.runJob(ctx, job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.jobQueued:
+		case <-ticker.C:
+		}
+	}
+}
+
+// runJob выполняет задание и сохраняет его результат. Ключ идемпотентности задания передается
+// сервисам размещения, поэтому повторное выполнение после перезапуска не займет вторую ячейку
+func (s *OrchestratorService) runJob(ctx context.Context, job *domain.Job) {
+	ctx, cancel := context.WithCancel(client.WithIdempotencyKey(ctx, fmt.Sprintf("job-%d", job.JobID)))
+	defer cancel()
+	go s.keepJobLease(ctx, cancel, job.JobID)
+
+	response, err := s.executeJob(ctx, job)
+	if ctx.Err() != nil {
+		// Оркестратор останавливается или аренда потеряна: задание вернется в очередь, когда истечет аренда
+		return
+	}
+
+	if err == nil {
+		var body []byte
+		if body, err = json.Marshal(response); err == nil {
+			if err := s.repo.CompleteJob(context.WithoutCancel(ctx), job.JobID, s.config.InstanceID, body); err != nil {
+				log.Printf("Ошибка сохранения результата задания %d: %v", job.JobID, err)
+			}
+			return
+		}
+	}
+
+	if err := s.repo.FailJob(context.WithoutCancel(ctx), job.JobID, s.config.InstanceID, err.Error()); err != nil {
+		log.Printf("Ошибка сохранения ошибки задания %d: %v", job.JobID, err)
+	}
+}
+
+// keepJobLease продлевает аренду задания, пока оно выполняется. Если задание больше не закреплено
+// за экземпляром (аренда истекла, и задание вернулось в очередь), его выполнение отменяется
+func (s *OrchestratorService) keepJobLease(ctx context.Context, cancel context.CancelFunc, jobID int64) {
+	ticker := time.NewTicker(s.config.JobLeaseTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		extended, err := s.repo.ExtendJobLease(ctx, jobID, s.config.InstanceID, s.config.JobLeaseTTL)
+		if err != nil {
+			// Аренда еще действует, продление повторится на следующем тике
+			if ctx.Err() == nil {
+				log.Printf("Ошибка продления аренды задания %d: %v", jobID, err)
+			}
+			continue
+		}
+		if !extended {
+			log.Printf("Задание %d больше не закреплено за экземпляром %s, выполнение отменено", jobID, s.config.InstanceID)
+			cancel()
+			return
+		}
+	}
+}
+
+// executeJob разбирает запрос задания и вызывает соответствующую операцию оркестратора
+func (s *OrchestratorService) executeJob(ctx context.Context, job *domain.Job) (interface{}, error) {
+	switch job.Operation {
+	case domain.JobOperationAnalyze, domain.JobOperationPlace:
+		var req domain.PlacementRequest
+		if err := json.Unmarshal(job.Request, &req); err != nil {
+			return nil, fmt.Errorf("ошибка разбора запроса задания: %w", err)
+		}
+		if job.Operation == domain.JobOperationAnalyze {
+			return s.AnalyzePlacement(ctx, &req)
+		}
+		return s.PlaceItem(ctx, &req)
+	case domain.JobOperationPlaceBatch:
+		var req domain.BatchPlacementRequest
+		if err := json.Unmarshal(job.Request, &req); err != nil {
+			return nil, fmt.Errorf("ошибка разбора запроса задания: %w", err)
+		}
+		return s.PlaceBatch(ctx, &req)
+	default:
+		return nil, fmt.Errorf("неизвестная операция задания: %s", job.Operation)
+	}
+}
@@ -2,187 +2,537 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"sort"
 	"sync"
+	"time"
 
+	"warehouse/pkg/placement"
 	"warehouse/services/orchestrator/internal/client"
 	"warehouse/services/orchestrator/internal/config"
 	"warehouse/services/orchestrator/internal/domain"
+	"warehouse/services/orchestrator/internal/repository"
+	"warehouse/services/orchestrator/internal/scoring"
 )
 
 
+// ErrInvalidRequest означает, что запрос к оркестратору составлен некорректно
+var ErrInvalidRequest = errors.New("некорректный запрос")
+
 type OrchestratorService struct {
-	config  *config.Config
-	clients map[string]*client.PlacementClient
+	config   *config.Config
+	clients  map[string]*client.PlacementClient
+	policies *scoring.Store
+	repo     repository.Repository
+
+	// jobQueued будит обработчик заданий, не дожидаясь очередного опроса очереди
+	jobQueued chan struct{}
 }
 
 
-func NewOrchestratorService(cfg *config.Config) *OrchestratorService {
+// NewOrchestratorService создает оркестратор. local - алгоритмы, встроенные в процесс: сервис с транспортом
+// local вызывается через них напрямую, остальные - по HTTP или gRPC
+func NewOrchestratorService(cfg *config.Config, policies *scoring.Store, repo repository.Repository, local map[string]placement.Service) *OrchestratorService {
 	clients := make(map[string]*client.PlacementClient)
 	for serviceID, serviceCfg := range cfg.Services {
-		clients[serviceID] = client.NewPlacementClient(serviceCfg.URL)
+		if serviceCfg.Transport == config.TransportLocal {
+			if algorithm, ok := local[serviceID]; ok {
+				clients[serviceID] = client.NewLocalPlacementClient(serviceCfg, algorithm)
+				continue
+			}
+			log.Printf("Сервис %s не встроен в процесс, используется HTTP", serviceID)
+		}
+		clients[serviceID] = client.NewPlacementClient(serviceCfg)
 	}
 
 	return &OrchestratorService{
-		config:  cfg,
-		clients: clients,
+		config:   cfg,
+		clients:  clients,
+		policies: policies,
+		repo:     repo,
+
+		jobQueued: make(chan struct{}, 1),
 	}
 }
 
-func (s *OrchestratorService) AnalyzePlacement(ctx context.Context, req *domain.PlacementRequest) (*domain.OrchestratorResponse, error) {
-	var (
-		wg      sync.WaitGroup
-		mu      sync.Mutex
-		results []domain.ServiceResult
-	)
-
-
-	for serviceID, placementClient := range s.clients {
-		wg.Add(1)
-		go func(serviceID string, placementClient *client.PlacementClient) {
-			defer wg.Done()
+// ScoringPolicy возвращает действующую политику скоринга
+func (s *OrchestratorService) ScoringPolicy() *scoring.Policy {
+	return s.policies.Current()
+}
 
-			resp, err := placementClient.AnalyzePlacement(ctx, req)
-			if err != nil {
-	
-				mu.Lock()
-				results = append(results, domain.ServiceResult{
-					ServiceName: s.config.Services[serviceID].Name,
-					Response: domain.PlacementResponse{
-						Success: false,
-						Comment: "Ошибка сервиса: " + err.Error(),
-						Score:   0,
-					},
-				})
-				mu.Unlock()
-				return
-			}
+// ReloadScoringPolicy перечитывает политику скоринга из файла без перезапуска сервиса
+func (s *OrchestratorService) ReloadScoringPolicy() (*scoring.Policy, error) {
+	return s.policies.Reload()
+}
 
-			mu.Lock()
-			results = append(results, domain.ServiceResult{
-				ServiceName: s.config.Services[serviceID].Name,
-				Response:    *resp,
-			})
-			mu.Unlock()
-		}(serviceID, placementClient)
+func (s *OrchestratorService) AnalyzePlacement(ctx context.Context, req *domain.PlacementRequest) (*domain.OrchestratorResponse, error) {
+	enrichment, err := s.prepareRequest(ctx, req)
+	if err != nil {
+		return nil, err
 	}
 
-	wg.Wait()
-
+	shadowCh := make(chan []domain.ServiceResult, 1)
+	go func() {
+		shadowCh <- s.shadowAnalyze(ctx, req)
+	}()
 
-	bestResult := s.selectBestResult(results)
+	results, quorumReached := s.fanOut(ctx, req, false, func(ctx context.Context, placementClient *client.PlacementClient) (*domain.PlacementResponse, error) {
+		return placementClient.AnalyzePlacement(ctx, req)
+	})
 
-	return &domain.OrchestratorResponse{
-		Success:    bestResult.Response.Success,
-		SlotID:     bestResult.Response.SlotID,
-		Comment:    bestResult.Response.Comment,
-		Score:      bestResult.Response.Score,
-		Algorithm:  bestResult.ServiceName,
-		AllResults: results,
-	}, nil
+	analysis := s.buildResponse(ctx, req, results, quorumReached)
+	analysis.Enrichment = enrichment
+	analysis.ShadowResults = <-shadowCh
+	s.recordDecision(ctx, domain.DecisionOperationAnalyze, req, analysis)
+	return analysis, nil
 }
 
-
+// PlaceItem резервирует ячейку в каждом сервисе и размещает товар ровно в зарезервированную
+// ячейку лучшего кандидата. Если размещение не удалось, пробует следующих по рейтингу
+// успешных кандидатов, но не более MaxPlacementAttempts раз. Резервы всех остальных сервисов снимаются
 func (s *OrchestratorService) PlaceItem(ctx context.Context, req *domain.PlacementRequest) (*domain.OrchestratorResponse, error) {
-	
-	analysis, err := s.AnalyzePlacement(ctx, req)
+	enrichment, err := s.prepareRequest(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
+	// Теневые сервисы опрашиваются параллельно с размещением и не задерживают боевое решение
+	shadowCh := make(chan []domain.ServiceResult, 1)
+	go func() {
+		shadowCh <- s.shadowAnalyze(ctx, req)
+	}()
+
+	response := s.placeRanked(ctx, req, enrichment)
+	shadow := <-shadowCh
+	response.ShadowResults = shadow
+	s.recordShadowComparisons(ctx, req, shadow, response)
+	s.recordDecision(ctx, domain.DecisionOperationPlace, req, response)
+
+	return response, nil
+}
+
+// placeRanked резервирует ячейку в каждом боевом сервисе и подтверждает резервы кандидатов по рейтингу
+func (s *OrchestratorService) placeRanked(ctx context.Context, req *domain.PlacementRequest, enrichment *domain.EnrichmentReport) *domain.OrchestratorResponse {
+	results, quorumReached := s.fanOut(ctx, req, true, func(ctx context.Context, placementClient *client.PlacementClient) (*domain.PlacementResponse, error) {
+		return placementClient.ReservePlacement(ctx, req, s.config.ReservationTTL)
+	})
+
+	analysis := s.buildResponse(ctx, req, results, quorumReached)
+	analysis.Enrichment = enrichment
 	if !analysis.Success {
-		return analysis, nil
+		s.releaseReservations(ctx, req, results, "")
+		return analysis
 	}
 
+	maxAttempts := s.config.MaxPlacementAttempts
+	if req.MaxAttempts > 0 {
+		maxAttempts = req.MaxAttempts
+	}
 
-	var selectedClient *client.PlacementClient
-	for serviceID, serviceCfg := range s.config.Services {
-		if serviceCfg.Name == analysis.Algorithm {
-			selectedClient = s.clients[serviceID]
+	var attempts []domain.PlacementAttempt
+	for _, candidate := range analysis.AllResults {
+		if len(attempts) >= maxAttempts || !candidate.Response.Success {
 			break
 		}
+
+		resp, attempt := s.commitCandidate(ctx, req, candidate)
+		attempt.Attempt = len(attempts) + 1
+		attempts = append(attempts, attempt)
+		if !attempt.Success {
+			continue
+		}
+
+		s.releaseReservations(ctx, req, results, candidate.ServiceID)
+		return &domain.OrchestratorResponse{
+			Success:       true,
+			SlotID:        resp.SlotID,
+			Comment:       resp.Comment,
+			Score:         candidate.FinalScore,
+			Algorithm:     candidate.ServiceName,
+			PolicyVersion: analysis.PolicyVersion,
+			QuorumReached: analysis.QuorumReached,
+			Attempts:      attempts,
+			Enrichment:    enrichment,
+			SignalSources: analysis.SignalSources,
+			AllResults:    analysis.AllResults,
+		}
+	}
+
+	s.releaseReservations(ctx, req, results, "")
+	return &domain.OrchestratorResponse{
+		Success:       false,
+		Comment:       fmt.Sprintf("Не удалось разместить товар: все попытки (%d) завершились неудачей", len(attempts)),
+		Score:         0,
+		Algorithm:     analysis.Algorithm,
+		PolicyVersion: analysis.PolicyVersion,
+		QuorumReached: analysis.QuorumReached,
+		Attempts:      attempts,
+		Enrichment:    enrichment,
+		SignalSources: analysis.SignalSources,
+		AllResults:    analysis.AllResults,
 	}
+}
 
-	if selectedClient == nil {
-		return nil, fmt.Errorf("не найден клиент для алгоритма %s", analysis.Algorithm)
+// commitCandidate подтверждает резерв кандидата и описывает результат попытки размещения
+func (s *OrchestratorService) commitCandidate(ctx context.Context, req *domain.PlacementRequest, candidate domain.ServiceResult) (*domain.PlacementResponse, domain.PlacementAttempt) {
+	attempt := domain.PlacementAttempt{
+		ServiceID:   candidate.ServiceID,
+		ServiceName: candidate.ServiceName,
+		SlotID:      candidate.Response.SlotID,
 	}
 
+	selectedClient, ok := s.clients[candidate.ServiceID]
+	if !ok {
+		attempt.Comment = fmt.Sprintf("не найден клиент для алгоритма %s", candidate.ServiceName)
+		return nil, attempt
+	}
 
-	resp, err := selectedClient.PlaceItem(ctx, req)
+	if candidate.Response.ReservationToken == "" {
+		attempt.Comment = fmt.Sprintf("сервис %s не вернул токен резерва", candidate.ServiceName)
+		return nil, attempt
+	}
+
+	resp, err := selectedClient.CommitReservation(ctx, req, candidate.Response.ReservationToken)
 	if err != nil {
-		return nil, err
+		attempt.Comment = "Ошибка сервиса: " + err.Error()
+		return nil, attempt
 	}
 
-	return &domain.OrchestratorResponse{
-		Success:    resp.Success,
-		SlotID:     resp.SlotID,
-		Comment:    resp.Comment,
-		Score:      resp.Score,
-		Algorithm:  analysis.Algorithm,
-		AllResults: analysis.AllResults,
-	}, nil
+	attempt.Success = resp.Success
+	attempt.Comment = resp.Comment
+	if resp.SlotID != "" {
+		attempt.SlotID = resp.SlotID
+	}
+	return resp, attempt
 }
 
+// fanOut параллельно вызывает call для каждого сервиса размещения и собирает ответы;
+// ошибка сервиса превращается в неуспешный ответ. Если в запросе заданы deadline_ms или quorum,
+// сбор завершается досрочно: по достижении кворума или по истечении срока. Не ответившие сервисы
+// попадают в результаты как timed out. Вызовы, которые ничего не резервируют, при этом отменяются
+// через контекст. Вызовы reserve (reserving) не отменяются: поздний ответ несет токен резерва,
+// поэтому такие ответы дожидаются в фоне и их резервы снимаются (drainLate)
+func (s *OrchestratorService) fanOut(ctx context.Context, req *domain.PlacementRequest, reserving bool, call func(ctx context.Context, placementClient *client.PlacementClient) (*domain.PlacementResponse, error)) ([]domain.ServiceResult, bool) {
+	// Длительность неотменяемого вызова reserve ограничена таймаутом клиента сервиса
+	callCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if reserving {
+		callCtx = context.WithoutCancel(ctx)
+	}
 
-func (s *OrchestratorService) selectBestResult(results []domain.ServiceResult) domain.ServiceResult {
-	var bestResult domain.ServiceResult
-	bestScore := -1.0
+	var deadline <-chan time.Time
+	if req.DeadlineMs > 0 {
+		timer := time.NewTimer(time.Duration(req.DeadlineMs) * time.Millisecond)
+		defer timer.Stop()
+		deadline = timer.C
+	}
 
-	for _, result := range results {
-		if !result.Response.Success {
+	var results []domain.ServiceResult
+	pending := make(map[string]bool)
+	resultsCh := make(chan domain.ServiceResult, len(s.clients))
+
+
+	for serviceID, placementClient := range s.clients {
+		if s.isShadow(serviceID) {
+			continue
+		}
+		if placementClient.CircuitState() == client.StateOpen {
+			results = append(results, skippedResult(serviceID, s.config.Services[serviceID].Name, client.StateOpen))
 			continue
 		}
 
+		pending[serviceID] = true
+		go func(serviceID string, placementClient *client.PlacementClient) {
+			resultsCh <- s.callService(callCtx, serviceID, placementClient, call)
+		}(serviceID, placementClient)
+	}
 
-		score := result.Response.BaseScore
-
+	quorumReached := false
+	expired := false
+	for len(pending) > 0 && !quorumReached && !expired {
+		select {
+		case result := <-resultsCh:
+			delete(pending, result.ServiceID)
+			results = append(results, result)
+			quorumReached = req.Quorum != nil && req.Quorum.IsMet(results)
+		case <-deadline:
+			expired = true
+		case <-ctx.Done():
+			expired = true
+		}
+	}
 
-		switch {
-		case result.Response.ResponseTimeMs < 300:
-			score += 0.03
-		case result.Response.ResponseTimeMs > 1000:
-			score -= 0.05
+	if len(pending) > 0 {
+		results = append(results, s.timedOutResults(pending, reserving)...)
+		// Отмененные вызовы без резерва завершаются сами: канал результатов вмещает все ответы
+		if reserving {
+			go s.drainLate(ctx, *req, resultsCh, len(pending))
 		}
+	}
+
+	return results, quorumReached
+}
 
+// drainLate дожидается count ответов, которые fanOut уже не учитывает, и снимает пришедшие в них
+// резервы; иначе ячейки оставались бы заблокированными до истечения TTL
+func (s *OrchestratorService) drainLate(ctx context.Context, req domain.PlacementRequest, resultsCh <-chan domain.ServiceResult, count int) {
+	for i := 0; i < count; i++ {
+		result := <-resultsCh
+		s.releaseReservations(ctx, &req, []domain.ServiceResult{result}, "")
+	}
+}
 
-		switch {
-		case result.Response.DistanceToExit < 5:
-			score += 0.05
-		case result.Response.DistanceToExit > 15:
-			score -= 0.03
+// callService вызывает один сервис и превращает ответ или ошибку в результат опроса
+func (s *OrchestratorService) callService(ctx context.Context, serviceID string, placementClient *client.PlacementClient, call func(ctx context.Context, placementClient *client.PlacementClient) (*domain.PlacementResponse, error)) domain.ServiceResult {
+	startTime := time.Now()
+	resp, err := call(ctx, placementClient)
+	latencyMs := time.Since(startTime).Milliseconds()
+	circuitState := placementClient.CircuitState()
+	if errors.Is(err, client.ErrCircuitOpen) {
+		return skippedResult(serviceID, s.config.Services[serviceID].Name, circuitState)
+	}
+	if err != nil {
+		return domain.ServiceResult{
+			ServiceID:    serviceID,
+			ServiceName:  s.config.Services[serviceID].Name,
+			CircuitState: circuitState,
+			LatencyMs:    latencyMs,
+			Error:        err.Error(),
+			Response: domain.PlacementResponse{
+				Success: false,
+				Comment: "Ошибка сервиса: " + err.Error(),
+				Score:   0,
+			},
 		}
+	}
 
+	return domain.ServiceResult{
+		ServiceID:    serviceID,
+		ServiceName:  s.config.Services[serviceID].Name,
+		CircuitState: circuitState,
+		LatencyMs:    latencyMs,
+		Response:     *resp,
+	}
+}
 
-		if result.Response.HasFixedSlot {
-			score += 0.07
-		}
-		if result.Response.HighWarehouseLoad {
-			score += 0.05
-		}
-		if result.Response.HighTurnover {
-			score += 0.05
+// timedOutResults формирует результаты для сервисов, ответ которых не дождались; вызовы без резерва
+// к этому моменту отменены, а резерв из позднего ответа reserve будет снят
+func (s *OrchestratorService) timedOutResults(pending map[string]bool, reserving bool) []domain.ServiceResult {
+	comment := "Сервис не ответил в отведенное время, запрос отменен"
+	if reserving {
+		comment = "Сервис не ответил в отведенное время, резерв из позднего ответа будет снят"
+	}
+
+	var results []domain.ServiceResult
+	for serviceID := range pending {
+		results = append(results, domain.ServiceResult{
+			ServiceID:    serviceID,
+			ServiceName:  s.config.Services[serviceID].Name,
+			CircuitState: s.clients[serviceID].CircuitState(),
+			TimedOut:     true,
+			Response: domain.PlacementResponse{
+				Success: false,
+				Comment: comment,
+				Score:   0,
+			},
+		})
+	}
+	return results
+}
+
+// skippedResult формирует результат для сервиса, который не опрашивался из-за разомкнутой цепи
+func skippedResult(serviceID, serviceName, circuitState string) domain.ServiceResult {
+	return domain.ServiceResult{
+		ServiceID:    serviceID,
+		ServiceName:  serviceName,
+		CircuitState: circuitState,
+		Skipped:      true,
+		Response: domain.PlacementResponse{
+			Success: false,
+			Comment: "Сервис пропущен: " + client.ErrCircuitOpen.Error(),
+			Score:   0,
+		},
+	}
+}
+
+// StartHealthChecks периодически опрашивает /healthz всех сервисов, чтобы выключатели
+// размыкались и восстанавливались без ожидания пользовательских запросов
+func (s *OrchestratorService) StartHealthChecks(ctx context.Context) {
+	if s.config.HealthCheckInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.config.HealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.ServicesHealth(ctx)
 		}
-		if result.Response.HeavyItem {
-			score += 0.03
+	}
+}
+
+// ServicesHealth опрашивает /healthz всех сервисов и возвращает их состояние вместе с состоянием выключателей
+func (s *OrchestratorService) ServicesHealth(ctx context.Context) []domain.ServiceHealth {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		health []domain.ServiceHealth
+	)
+
+	for serviceID, placementClient := range s.clients {
+		wg.Add(1)
+		go func(serviceID string, placementClient *client.PlacementClient) {
+			defer wg.Done()
+
+			status := domain.ServiceHealth{
+				ServiceID:   serviceID,
+				ServiceName: s.config.Services[serviceID].Name,
+				Healthy:     true,
+			}
+			if err := placementClient.CheckHealth(ctx); err != nil {
+				status.Healthy = false
+				status.Error = err.Error()
+			}
+			status.CircuitState = placementClient.CircuitState()
+
+			mu.Lock()
+			health = append(health, status)
+			mu.Unlock()
+		}(serviceID, placementClient)
+	}
+
+	wg.Wait()
+
+	sort.Slice(health, func(i, j int) bool {
+		return health[i].ServiceID < health[j].ServiceID
+	})
+
+	return health
+}
+
+// buildResponse дополняет ответы сервисов признаками по данным склада, ранжирует их
+// по действующей политике и формирует ответ с победителем
+func (s *OrchestratorService) buildResponse(ctx context.Context, req *domain.PlacementRequest, results []domain.ServiceResult, quorumReached bool) *domain.OrchestratorResponse {
+	slotSignalsDerived := s.deriveSlotSignals(ctx, req, results)
+
+	policy := s.policies.Current()
+	s.rankResults(policy, results)
+	bestResult := s.selectBestResult(results)
+
+	return &domain.OrchestratorResponse{
+		Success:       bestResult.Response.Success,
+		SlotID:        bestResult.Response.SlotID,
+		Comment:       bestResult.Response.Comment,
+		Score:         bestResult.Response.Score,
+		Algorithm:     bestResult.ServiceName,
+		PolicyVersion: policy.Version,
+		QuorumReached: quorumReached,
+		SignalSources: signalSources(slotSignalsDerived),
+		AllResults:    results,
+	}
+}
+
+// validateQuorum проверяет, что политика кворума ссылается только на известные сервисы и выполнима
+func (s *OrchestratorService) validateQuorum(req *domain.PlacementRequest) error {
+	if req.DeadlineMs < 0 {
+		return fmt.Errorf("%w: deadline_ms не может быть отрицательным", ErrInvalidRequest)
+	}
+	if req.Quorum == nil {
+		return nil
+	}
+	for _, serviceID := range req.Quorum.RequiredServices {
+		if _, ok := s.clients[serviceID]; !ok {
+			return fmt.Errorf("%w: неизвестный сервис в кворуме: %s", ErrInvalidRequest, serviceID)
 		}
-		if result.Response.NoPlacementHistory {
-			score += 0.02
+		if s.isShadow(serviceID) {
+			return fmt.Errorf("%w: сервис %s работает в теневом режиме и не может входить в кворум", ErrInvalidRequest, serviceID)
 		}
-		if result.Response.FastAccessZone {
-			score += 0.03
+	}
+	liveServices := len(s.clients) - len(s.config.ShadowServices)
+	if req.Quorum.MinResponses > liveServices {
+		return fmt.Errorf("%w: min_responses (%d) больше числа сервисов (%d)", ErrInvalidRequest, req.Quorum.MinResponses, liveServices)
+	}
+	return nil
+}
+
+// releaseReservations снимает резервы всех сервисов, кроме keepServiceID; ошибки только логируются,
+// так как неснятый резерв все равно истечет по TTL
+func (s *OrchestratorService) releaseReservations(ctx context.Context, req *domain.PlacementRequest, results []domain.ServiceResult, keepServiceID string) {
+	ctx = context.WithoutCancel(ctx)
+
+	var wg sync.WaitGroup
+	for _, result := range results {
+		if result.ServiceID == keepServiceID || result.Response.ReservationToken == "" {
+			continue
 		}
-		if result.Response.XYZCompliant {
-			score += 0.02
+
+		wg.Add(1)
+		go func(result domain.ServiceResult) {
+			defer wg.Done()
+
+			if _, err := s.clients[result.ServiceID].ReleaseReservation(ctx, req, result.Response.ReservationToken); err != nil {
+				log.Printf("Ошибка снятия резерва в сервисе %s: %v", result.ServiceName, err)
+			}
+		}(result)
+	}
+
+	wg.Wait()
+}
+
+
+func (s *OrchestratorService) selectBestResult(results []domain.ServiceResult) domain.ServiceResult {
+	var bestResult domain.ServiceResult
+	bestScore := -1.0
+
+	for _, result := range results {
+		if !result.Response.Success {
+			continue
 		}
 
 	
-		if score > bestScore {
-			bestScore = score
+		if result.FinalScore > bestScore {
+			bestScore = result.FinalScore
 			bestResult = result
 	
-			bestResult.Response.Score = score
+			bestResult.Response.Score = result.FinalScore
 		}
 	}
 
 	return bestResult
-} 
\ No newline at end of file
+}
+
+// rankResults рассчитывает по политике итоговую оценку и ее разложение для каждого ответа
+// и сортирует ответы: успешные по убыванию оценки, неуспешные — в конце списка
+func (s *OrchestratorService) rankResults(policy *scoring.Policy, results []domain.ServiceResult) {
+	for i := range results {
+		if !results[i].Response.Success {
+			results[i].FinalScore = 0
+			results[i].Breakdown = nil
+			continue
+		}
+		breakdown := policy.Score(&results[i].Response)
+		results[i].FinalScore = breakdown.Total
+		results[i].Breakdown = &breakdown
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Response.Success != b.Response.Success {
+			return a.Response.Success
+		}
+		if a.FinalScore != b.FinalScore {
+			return a.FinalScore > b.FinalScore
+		}
+		return a.ServiceName < b.ServiceName
+	})
+
+	for i := range results {
+		results[i].Rank = i + 1
+	}
+}
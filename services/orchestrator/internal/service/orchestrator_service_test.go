@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"sort"
+	"testing"
+	"time"
+
+	"warehouse/pkg/placement"
+	"warehouse/services/orchestrator/internal/client"
+	"warehouse/services/orchestrator/internal/config"
+	"warehouse/services/orchestrator/internal/domain"
+	"warehouse/services/orchestrator/internal/scoring"
+)
+
+// fakeAlgorithm - алгоритм размещения, который отвечает через delay, сообщает о снятых резервах в released
+// и об отмененных вызовах в canceled
+type fakeAlgorithm struct {
+	serviceID string
+	delay     time.Duration
+	released  chan string
+	canceled  chan string
+}
+
+func (f *fakeAlgorithm) respond(ctx context.Context, token string) (*placement.Response, error) {
+	select {
+	case <-time.After(f.delay):
+	case <-ctx.Done():
+		f.canceled <- f.serviceID
+		return nil, ctx.Err()
+	}
+	return &placement.Response{
+		SchemaVersion:    placement.SchemaVersion,
+		Success:          true,
+		SlotID:           f.serviceID + "-SLOT",
+		Comment:          "ячейка подобрана",
+		Score:            0.9,
+		BaseScore:        0.9,
+		ReservationToken: token,
+	}, nil
+}
+
+func (f *fakeAlgorithm) Analyze(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	return f.respond(ctx, "")
+}
+
+func (f *fakeAlgorithm) Place(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	return f.respond(ctx, "")
+}
+
+func (f *fakeAlgorithm) Reserve(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	return f.respond(ctx, f.serviceID+"-token")
+}
+
+func (f *fakeAlgorithm) Commit(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	return f.respond(ctx, "")
+}
+
+func (f *fakeAlgorithm) Release(ctx context.Context, req *placement.Request) (*placement.Response, error) {
+	f.released <- req.ReservationToken
+	return &placement.Response{SchemaVersion: placement.SchemaVersion, Success: true, Comment: "резерв снят"}, nil
+}
+
+func (f *fakeAlgorithm) Candidates(ctx context.Context, req *placement.Request, limit int) ([]placement.Response, error) {
+	return nil, nil
+}
+
+func (f *fakeAlgorithm) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// newTestOrchestrator создает оркестратор, который вызывает алгоритмы delays в процессе
+func newTestOrchestrator(t *testing.T, delays map[string]time.Duration, released, canceled chan string) *OrchestratorService {
+	t.Helper()
+
+	cfg := &config.Config{Services: map[string]config.ServiceConfig{}, ShadowServices: map[string]bool{}}
+	local := map[string]placement.Service{}
+	for serviceID, delay := range delays {
+		cfg.Services[serviceID] = config.ServiceConfig{Name: serviceID, Transport: config.TransportLocal, FailureThreshold: 5}
+		local[serviceID] = &fakeAlgorithm{serviceID: serviceID, delay: delay, released: released, canceled: canceled}
+	}
+
+	policies, err := scoring.NewStore("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewOrchestratorService(cfg, policies, nil, local)
+}
+
+func TestFanOut(t *testing.T) {
+	const slow = 300 * time.Millisecond
+
+	tests := []struct {
+		name       string
+		delays     map[string]time.Duration
+		deadlineMs int
+		quorum     *domain.QuorumPolicy
+		// analyze - опрос командой analyze, которая ничего не резервирует; иначе reserve
+		analyze    bool
+		wantQuorum bool
+		// wantTimedOut - сервисы, ответа которых fanOut не дождался: вызовы analyze должны быть отменены,
+		// а резервы из поздних ответов reserve сняты
+		wantTimedOut []string
+	}{
+		{
+			name:   "без срока и кворума дожидается всех",
+			delays: map[string]time.Duration{"fast": 0, "slow": 30 * time.Millisecond},
+		},
+		{
+			name:         "кворум завершает опрос досрочно",
+			delays:       map[string]time.Duration{"fast": 0, "slow": slow},
+			quorum:       &domain.QuorumPolicy{RequiredServices: []string{"fast"}, MinResponses: 1},
+			wantQuorum:   true,
+			wantTimedOut: []string{"slow"},
+		},
+		{
+			name:   "кворум без обязательного сервиса ждет его",
+			delays: map[string]time.Duration{"fast": 0, "slow": 30 * time.Millisecond},
+			quorum: &domain.QuorumPolicy{RequiredServices: []string{"slow"}, MinResponses: 1},
+			// кворум набирается последним ответом
+			wantQuorum: true,
+		},
+		{
+			name:         "срок ожидания завершает опрос",
+			delays:       map[string]time.Duration{"fast": 0, "slow": slow},
+			deadlineMs:   50,
+			wantTimedOut: []string{"slow"},
+		},
+		{
+			name:         "кворум отменяет не дождавшиеся вызовы analyze",
+			delays:       map[string]time.Duration{"fast": 0, "slow": time.Minute},
+			quorum:       &domain.QuorumPolicy{RequiredServices: []string{"fast"}, MinResponses: 1},
+			analyze:      true,
+			wantQuorum:   true,
+			wantTimedOut: []string{"slow"},
+		},
+		{
+			name:         "срок ожидания отменяет не дождавшиеся вызовы analyze",
+			delays:       map[string]time.Duration{"fast": 0, "slow": time.Minute},
+			deadlineMs:   50,
+			analyze:      true,
+			wantTimedOut: []string{"slow"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			released := make(chan string, len(tt.delays))
+			canceled := make(chan string, len(tt.delays))
+			s := newTestOrchestrator(t, tt.delays, released, canceled)
+			req := &domain.PlacementRequest{ItemID: "ITEM001", Quantity: 1, DeadlineMs: tt.deadlineMs, Quorum: tt.quorum}
+
+			results, quorumReached := s.fanOut(context.Background(), req, !tt.analyze, func(ctx context.Context, placementClient *client.PlacementClient) (*domain.PlacementResponse, error) {
+				if tt.analyze {
+					return placementClient.AnalyzePlacement(ctx, req)
+				}
+				return placementClient.ReservePlacement(ctx, req, time.Minute)
+			})
+
+			if quorumReached != tt.wantQuorum {
+				t.Errorf("quorum_reached = %v, ожидалось %v", quorumReached, tt.wantQuorum)
+			}
+			if len(results) != len(tt.delays) {
+				t.Fatalf("результатов %d, ожидалось %d", len(results), len(tt.delays))
+			}
+
+			var timedOut []string
+			for _, result := range results {
+				if result.TimedOut {
+					timedOut = append(timedOut, result.ServiceID)
+					continue
+				}
+				if !tt.analyze && result.Response.ReservationToken != result.ServiceID+"-token" {
+					t.Errorf("сервис %s: токен резерва %q", result.ServiceID, result.Response.ReservationToken)
+				}
+			}
+			sort.Strings(timedOut)
+			if !equalStrings(timedOut, tt.wantTimedOut) {
+				t.Fatalf("не дождались %v, ожидалось %v", timedOut, tt.wantTimedOut)
+			}
+
+			for range tt.wantTimedOut {
+				if tt.analyze {
+					// Вызовы analyze отменяются через контекст, не дожидаясь ответа
+					select {
+					case serviceID := <-canceled:
+						if !slices.Contains(tt.wantTimedOut, serviceID) {
+							t.Errorf("отменен вызов сервиса %s, ответ которого учтен", serviceID)
+						}
+					case <-time.After(5 * time.Second):
+						t.Fatal("не дождавшийся вызов analyze не отменен")
+					}
+					continue
+				}
+
+				// Поздние ответы reserve дожидаются в фоне, и их резервы снимаются
+				select {
+				case token := <-released:
+					if !containsToken(tt.wantTimedOut, token) {
+						t.Errorf("снят резерв %s сервиса, ответ которого учтен", token)
+					}
+				case <-time.After(5 * time.Second):
+					t.Fatal("резерв из позднего ответа не снят")
+				}
+			}
+			select {
+			case serviceID := <-canceled:
+				t.Errorf("отменен вызов reserve сервиса %s", serviceID)
+			default:
+			}
+			select {
+			case token := <-released:
+				t.Errorf("лишнее снятие резерва %s", token)
+			default:
+			}
+		})
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsToken(serviceIDs []string, token string) bool {
+	for _, serviceID := range serviceIDs {
+		if serviceID+"-token" == token {
+			return true
+		}
+	}
+	return false
+}
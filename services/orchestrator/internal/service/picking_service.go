@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"warehouse/services/orchestrator/internal/domain"
+)
+
+// Pick отбирает количество товара или партии со склада. Отбор выполняется целиком или не выполняется:
+// если товара не хватает, ответ содержит Success = false и доступный остаток
+func (s *OrchestratorService) Pick(ctx context.Context, req *domain.PickRequest) (*domain.PickResult, error) {
+	if err := s.validatePick(ctx, req); err != nil {
+		return nil, err
+	}
+
+	results, err := s.repo.PickStock(ctx, []domain.PickRequest{*req}, false)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка отбора: %w", err)
+	}
+	return &results[0], nil
+}
+
+// PickList отбирает строки листа отбора. В режиме best_effort каждая строка списывается отдельно,
+// в режиме all_or_nothing строки списываются одной транзакцией и только если отобрать удалось все
+func (s *OrchestratorService) PickList(ctx context.Context, req *domain.PickListRequest) (*domain.PickListResponse, error) {
+	mode := req.Mode
+	if mode == "" {
+		mode = domain.BatchModeBestEffort
+	}
+	if mode != domain.BatchModeBestEffort && mode != domain.BatchModeAllOrNothing {
+		return nil, fmt.Errorf("%w: неизвестный режим листа отбора: %s", ErrInvalidRequest, mode)
+	}
+	if len(req.Lines) == 0 {
+		return nil, fmt.Errorf("%w: пустой лист отбора", ErrInvalidRequest)
+	}
+	for i := range req.Lines {
+		if err := s.validatePick(ctx, &req.Lines[i]); err != nil {
+			return nil, fmt.Errorf("строка %d: %w", i+1, err)
+		}
+	}
+
+	results, err := s.repo.PickStock(ctx, req.Lines, mode == domain.BatchModeAllOrNothing)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка отбора: %w", err)
+	}
+
+	response := &domain.PickListResponse{Mode: mode, Lines: results}
+	for i := range results {
+		if results[i].Success {
+			response.Summary.Picked++
+		} else {
+			response.Summary.Failed++
+		}
+	}
+
+	// В режиме all_or_nothing неудача одной строки отменяет списание остальных
+	if mode == domain.BatchModeAllOrNothing && response.Summary.Failed > 0 {
+		for i := range results {
+			if results[i].Success {
+				results[i].Success = false
+				results[i].Picks = nil
+				results[i].Comment = "Отбор отменен: не все строки удалось отобрать"
+			}
+		}
+		response.Summary.Picked = 0
+		response.Summary.Failed = len(results)
+	}
+
+	for i := range results {
+		results[i].Line = i + 1
+	}
+	response.Summary.Total = len(results)
+	response.Success = response.Summary.Failed == 0
+	return response, nil
+}
+
+// validatePick проверяет строку отбора и подставляет склад по умолчанию
+func (s *OrchestratorService) validatePick(ctx context.Context, req *domain.PickRequest) error {
+	if req.ItemID == "" {
+		return fmt.Errorf("%w: не указан item_id", ErrInvalidRequest)
+	}
+	if req.Quantity <= 0 {
+		return fmt.Errorf("%w: quantity должно быть больше нуля", ErrInvalidRequest)
+	}
+	warehouseID, err := s.resolveWarehouse(ctx, req.WarehouseID)
+	if err != nil {
+		return err
+	}
+	req.WarehouseID = warehouseID
+	return nil
+}
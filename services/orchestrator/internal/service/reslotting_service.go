@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	"warehouse/pkg/capacity"
+	"warehouse/pkg/placement"
+	"warehouse/pkg/stock"
+	"warehouse/pkg/topology"
+	"warehouse/services/orchestrator/internal/domain"
+	"warehouse/services/orchestrator/internal/repository"
+)
+
+const (
+	defaultReslotMoves = 10
+	maxReslotMoves     = 100
+)
+
+// zoneRank - удаленность зоны от выхода: чем меньше, тем ближе
+var zoneRank = map[string]int{
+	placement.ZoneFastAccess: 0,
+	placement.ZoneRegular:    1,
+	placement.ZoneDeep:       2,
+}
+
+// ReslotPlan сравнивает ячейки остатков с зонами, которые сервис ABC или XYZ выбрал бы им сегодня,
+// и предлагает не больше MaxMoves перемещений: ближе к выходу для товаров, ставших оборачиваемее,
+// и дальше от выхода для товаров, которые занимают ближние ячейки, хотя их категория понизилась.
+// Каждая ячейка участвует в плане не больше одного раза, поэтому перемещения не конфликтуют
+func (s *OrchestratorService) ReslotPlan(ctx context.Context, req *domain.ReslotPlanRequest) (*domain.ReslotPlan, error) {
+	strategy := req.Strategy
+	if strategy == "" {
+		strategy = domain.ReslotStrategyABC
+	}
+	if strategy != domain.ReslotStrategyABC && strategy != domain.ReslotStrategyXYZ {
+		return nil, fmt.Errorf("%w: неизвестная стратегия перераскладки: %s", ErrInvalidRequest, strategy)
+	}
+	maxMoves := req.MaxMoves
+	if maxMoves == 0 {
+		maxMoves = defaultReslotMoves
+	}
+	if maxMoves < 0 || maxMoves > maxReslotMoves {
+		return nil, fmt.Errorf("%w: max_moves должно быть от 1 до %d", ErrInvalidRequest, maxReslotMoves)
+	}
+
+	warehouseID, err := s.resolveWarehouse(ctx, req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	stocks, err := s.repo.GetReslotStock(ctx, warehouseID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения остатков: %w", err)
+	}
+	// Удаленность ячеек считается по топологии склада от ближайших ворот, если она задана
+	distances, err := s.repo.GetTravelDistances(ctx, warehouseID, "")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка расчета путей по топологии склада: %w", err)
+	}
+
+	type misplacedStock struct {
+		domain.ReslotStock
+		class, zone, reason string
+		demotion            bool
+		distance            float64
+	}
+	now := time.Now()
+	var misplaced []misplacedStock
+	for _, entry := range stocks {
+		class, zone, reason := reslotTarget(strategy, entry, now)
+		rank, ok := zoneRank[entry.ZoneType]
+		if !ok || zoneRank[zone] == rank {
+			continue
+		}
+		misplaced = append(misplaced, misplacedStock{
+			ReslotStock: entry,
+			class:       class,
+			zone:        zone,
+			reason:      reason,
+			demotion:    zoneRank[zone] > rank,
+			distance:    slotDistance(entry.SlotID, entry.DistanceFromExit, distances),
+		})
+	}
+
+	// Сначала товары переносятся ближе к выходу: ближайшие ячейки достаются самым оборачиваемым товарам,
+	// лежащим дальше всего от выхода. Затем из ближних ячеек уходят наименее оборачиваемые товары
+	sort.SliceStable(misplaced, func(i, j int) bool {
+		a, b := misplaced[i], misplaced[j]
+		if a.demotion != b.demotion {
+			return !a.demotion
+		}
+		if a.Turnover != b.Turnover {
+			if a.demotion {
+				return a.Turnover < b.Turnover
+			}
+			return a.Turnover > b.Turnover
+		}
+		if a.demotion {
+			return a.distance < b.distance
+		}
+		return a.distance > b.distance
+	})
+
+	plan := &domain.ReslotPlan{
+		WarehouseID: warehouseID,
+		Strategy:    strategy,
+		MaxMoves:    maxMoves,
+		Misplaced:   len(misplaced),
+		Moves:       []domain.ReslotMove{},
+	}
+	used := []string{}
+	for _, entry := range misplaced {
+		if len(plan.Moves) == maxMoves {
+			break
+		}
+		if slices.Contains(used, entry.SlotID) {
+			continue
+		}
+
+		// Исходная ячейка лежит в другой зоне, поэтому сама целевой стать не может
+		targets, err := s.repo.FindReslotTargets(ctx, entry.zone, entry.StockEntry, used)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка поиска ячейки для %s: %w", entry.ItemID, err)
+		}
+		if len(targets) == 0 {
+			continue
+		}
+		topology.OrderSlots(targets, distances)
+		target := targets[0]
+
+		// Пути сравниваются в одной мере: по топологии, только если она знает обе ячейки
+		fromDistance, toDistance := float64(entry.DistanceFromExit), float64(target.DistanceFromExit)
+		if travel, ok := distances[entry.SlotID]; ok && target.TravelDistance != nil {
+			fromDistance, toDistance = travel, *target.TravelDistance
+		}
+		// Перенос ближе к выходу имеет смысл, только если путь к товару сокращается; перенос дальше
+		// удлиняет путь к малооборачиваемому товару, но освобождает ближнюю ячейку
+		gain := fromDistance - toDistance
+		if !entry.demotion && gain <= 0 {
+			continue
+		}
+		direction := domain.ReslotPromote
+		if entry.demotion {
+			direction = domain.ReslotDemote
+		}
+
+		used = append(used, entry.SlotID, target.SlotID)
+		plan.Moves = append(plan.Moves, domain.ReslotMove{
+			ItemID:       entry.ItemID,
+			BatchID:      entry.BatchID,
+			Quantity:     entry.Quantity,
+			Class:        entry.class,
+			Direction:    direction,
+			FromSlotID:   entry.SlotID,
+			FromZone:     entry.ZoneType,
+			FromDistance: fromDistance,
+			ToSlotID:     target.SlotID,
+			ToZone:       entry.zone,
+			ToDistance:   toDistance,
+			DistanceGain: gain,
+			ExpectedGain: gain * entry.Turnover,
+			Reason:       entry.reason,
+		})
+	}
+
+	sort.SliceStable(plan.Moves, func(i, j int) bool {
+		return plan.Moves[i].ExpectedGain > plan.Moves[j].ExpectedGain
+	})
+	for _, move := range plan.Moves {
+		plan.TotalExpectedGain += move.ExpectedGain
+	}
+	return plan, nil
+}
+
+// slotDistance возвращает путь до ячейки по топологии склада, если он известен, иначе distance_from_exit
+func slotDistance(slotID string, distanceFromExit int, distances map[string]float64) float64 {
+	if travel, ok := distances[slotID]; ok {
+		return travel
+	}
+	return float64(distanceFromExit)
+}
+
+// reslotTarget возвращает категорию остатка, зону, которую ему выбрал бы сервис ABC или XYZ,
+// и причину выбора. Короткая партия, как и у сервисов, уходит на зону ближе к выходу
+func reslotTarget(strategy string, entry domain.ReslotStock, now time.Time) (string, string, string) {
+	class := placement.ABCClass(entry.Turnover)
+	reason := fmt.Sprintf("ABC-категория %s (оборачиваемость %.2f)", class, entry.Turnover)
+	if strategy == domain.ReslotStrategyXYZ {
+		class = placement.XYZClass(entry.Mr)
+		reason = fmt.Sprintf("XYZ-категория %s (коэффициент вариации %.2f)", class, entry.Mr)
+	}
+
+	zone := placement.ClassZone(class)
+	if placement.ExpiryUrgency(entry.ExpiresAt, now) > 0 && !placement.Expired(entry.ExpiresAt, now) {
+		if faster, ok := placement.FasterZone(zone); ok {
+			zone = faster
+			reason += ", короткий срок годности"
+		}
+	}
+	return class, zone, reason
+}
+
+// MoveStock выполняет перемещение остатка партии между ячейками склада. Если товара в исходной ячейке
+// не хватает или он не помещается в целевую, ответ содержит Success = false и причину
+func (s *OrchestratorService) MoveStock(ctx context.Context, req *domain.MoveRequest) (*domain.MoveResult, error) {
+	if req.FromSlotID == req.ToSlotID {
+		return nil, fmt.Errorf("%w: исходная и целевая ячейки совпадают", ErrInvalidRequest)
+	}
+	if req.Quantity < 0 {
+		return nil, fmt.Errorf("%w: quantity не может быть отрицательным", ErrInvalidRequest)
+	}
+	warehouseID, err := s.resolveWarehouse(ctx, req.WarehouseID)
+	if err != nil {
+		return nil, err
+	}
+	req.WarehouseID = warehouseID
+
+	result, err := s.repo.MoveStock(ctx, req)
+	switch {
+	case errors.Is(err, repository.ErrSlotNotFound), errors.Is(err, capacity.ErrUnknownItem),
+		errors.Is(err, capacity.ErrUnknownBatch), errors.Is(err, capacity.ErrEmptyLoad):
+		return nil, fmt.Errorf("%w: %s", ErrInvalidRequest, err.Error())
+	case errors.Is(err, stock.ErrInsufficientStock), errors.Is(err, capacity.ErrInsufficientCapacity):
+		return &domain.MoveResult{
+			WarehouseID: req.WarehouseID,
+			ItemID:      req.ItemID,
+			BatchID:     req.BatchID,
+			FromSlotID:  req.FromSlotID,
+			ToSlotID:    req.ToSlotID,
+			Quantity:    req.Quantity,
+			Comment:     err.Error(),
+		}, nil
+	case err != nil:
+		return nil, fmt.Errorf("ошибка перемещения: %w", err)
+	}
+
+	result.Comment = fmt.Sprintf("Перемещено %d шт. из ячейки %s в %s", result.Quantity, result.FromSlotID, result.ToSlotID)
+	return result, nil
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"testing"
+
+	"warehouse/pkg/topology"
+	"warehouse/services/orchestrator/internal/domain"
+	"warehouse/services/orchestrator/internal/repository"
+)
+
+// reslotRepo отдает остатки склада, свободные ячейки зон и пути по топологии склада
+type reslotRepo struct {
+	repository.Repository
+	stocks    []domain.ReslotStock
+	targets   map[string][]topology.SlotDistance
+	distances map[string]float64
+}
+
+func (r *reslotRepo) GetWarehouse(ctx context.Context, warehouseID string) (*domain.Warehouse, error) {
+	return &domain.Warehouse{WarehouseID: warehouseID}, nil
+}
+
+func (r *reslotRepo) GetReslotStock(ctx context.Context, warehouseID string) ([]domain.ReslotStock, error) {
+	return r.stocks, nil
+}
+
+func (r *reslotRepo) FindReslotTargets(ctx context.Context, zoneType string, entry domain.StockEntry, exclude []string) ([]topology.SlotDistance, error) {
+	var targets []topology.SlotDistance
+	for _, target := range r.targets[zoneType] {
+		if !slices.Contains(exclude, target.SlotID) {
+			targets = append(targets, target)
+		}
+	}
+	return targets, nil
+}
+
+func (r *reslotRepo) GetTravelDistances(ctx context.Context, warehouseID, dockID string) (map[string]float64, error) {
+	return r.distances, nil
+}
+
+// reslotStock - остаток товара в ячейке зоны
+func reslotStock(itemID, slotID, zone string, distance int, turnover float64) domain.ReslotStock {
+	return domain.ReslotStock{
+		StockEntry:       domain.StockEntry{SlotID: slotID, ItemID: itemID, Quantity: 10},
+		ZoneType:         zone,
+		DistanceFromExit: distance,
+		Turnover:         turnover,
+	}
+}
+
+func TestReslotPlan(t *testing.T) {
+	targets := map[string][]topology.SlotDistance{
+		"fast-access": {{SlotID: "F2", DistanceFromExit: 5}, {SlotID: "F4", DistanceFromExit: 8}},
+		"regular":     {{SlotID: "R2", DistanceFromExit: 20}},
+		"deep":        {{SlotID: "D2", DistanceFromExit: 50}},
+	}
+
+	tests := []struct {
+		name          string
+		stocks        []domain.ReslotStock
+		distances     map[string]float64
+		maxMoves      int
+		wantMoves     []string
+		wantGains     []float64
+		wantMisplaced int
+	}{
+		{
+			name:          "оборачиваемый товар переносится ближе к выходу",
+			stocks:        []domain.ReslotStock{reslotStock("ITEM_A", "D1", "deep", 60, 0.9)},
+			wantMoves:     []string{"ITEM_A D1->F2 promote"},
+			wantGains:     []float64{55},
+			wantMisplaced: 1,
+		},
+		{
+			name:          "товар категории C уходит из ближней зоны",
+			stocks:        []domain.ReslotStock{reslotStock("ITEM_C", "F1", "fast-access", 4, 0.05)},
+			wantMoves:     []string{"ITEM_C F1->D2 demote"},
+			wantGains:     []float64{-46},
+			wantMisplaced: 1,
+		},
+		{
+			name:          "товар в своей зоне не перемещается",
+			stocks:        []domain.ReslotStock{reslotStock("ITEM_B", "R1", "regular", 20, 0.5)},
+			wantMisplaced: 0,
+		},
+		{
+			name:          "перенос ближе без сокращения пути не предлагается",
+			stocks:        []domain.ReslotStock{reslotStock("ITEM_A", "R1", "regular", 3, 0.9)},
+			wantMisplaced: 1,
+		},
+		{
+			name: "переносы ближе к выходу занимают бюджет раньше",
+			stocks: []domain.ReslotStock{
+				reslotStock("ITEM_C", "F1", "fast-access", 4, 0.05),
+				reslotStock("ITEM_A", "D1", "deep", 60, 0.9),
+			},
+			maxMoves:      1,
+			wantMoves:     []string{"ITEM_A D1->F2 promote"},
+			wantGains:     []float64{55},
+			wantMisplaced: 2,
+		},
+		{
+			name: "из ближней зоны первым уходит наименее оборачиваемый товар",
+			stocks: []domain.ReslotStock{
+				reslotStock("ITEM_C1", "F1", "fast-access", 4, 0.1),
+				reslotStock("ITEM_C2", "F3", "fast-access", 6, 0.01),
+			},
+			wantMoves:     []string{"ITEM_C2 F3->D2 demote"},
+			wantGains:     []float64{-44},
+			wantMisplaced: 2,
+		},
+		{
+			name:          "ячейка выбирается по пути по топологии склада",
+			stocks:        []domain.ReslotStock{reslotStock("ITEM_A", "D1", "deep", 60, 0.9)},
+			distances:     map[string]float64{"D1": 80, "F2": 40, "F4": 10},
+			wantMoves:     []string{"ITEM_A D1->F4 promote"},
+			wantGains:     []float64{70},
+			wantMisplaced: 1,
+		},
+		{
+			name:          "без пути до исходной ячейки выигрыш считается по distance_from_exit",
+			stocks:        []domain.ReslotStock{reslotStock("ITEM_A", "D1", "deep", 60, 0.9)},
+			distances:     map[string]float64{"F2": 40, "F4": 10},
+			wantMoves:     []string{"ITEM_A D1->F4 promote"},
+			wantGains:     []float64{52},
+			wantMisplaced: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &OrchestratorService{repo: &reslotRepo{stocks: tt.stocks, targets: targets, distances: tt.distances}}
+
+			plan, err := s.ReslotPlan(context.Background(), &domain.ReslotPlanRequest{MaxMoves: tt.maxMoves})
+			if err != nil {
+				t.Fatalf("ReslotPlan() ошибка = %v", err)
+			}
+
+			var moves []string
+			var gains []float64
+			for _, move := range plan.Moves {
+				moves = append(moves, move.ItemID+" "+move.FromSlotID+"->"+move.ToSlotID+" "+move.Direction)
+				gains = append(gains, move.DistanceGain)
+			}
+			if !reflect.DeepEqual(moves, tt.wantMoves) {
+				t.Errorf("перемещения %v, ожидалось %v", moves, tt.wantMoves)
+			}
+			if !reflect.DeepEqual(gains, tt.wantGains) {
+				t.Errorf("выигрыш в пути %v, ожидалось %v", gains, tt.wantGains)
+			}
+			if plan.Misplaced != tt.wantMisplaced {
+				t.Errorf("misplaced = %d, ожидалось %d", plan.Misplaced, tt.wantMisplaced)
+			}
+		})
+	}
+}
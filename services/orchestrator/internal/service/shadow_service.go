@@ -0,0 +1,112 @@
+package service
+
+import (
+	"context"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"warehouse/services/orchestrator/internal/client"
+	"warehouse/services/orchestrator/internal/domain"
+)
+
+// isShadow сообщает, работает ли сервис в теневом режиме
+func (s *OrchestratorService) isShadow(serviceID string) bool {
+	return s.config.ShadowServices[serviceID]
+}
+
+// shadowAnalyze опрашивает теневые сервисы командой analyze и оценивает их ответы по действующей
+// политике. Теневые сервисы ничего не резервируют и не участвуют в выборе ячейки. Опрос выполняется
+// до резервирования, чтобы резервы боевых сервисов не скрывали от теневых уже выбранные ячейки
+func (s *OrchestratorService) shadowAnalyze(ctx context.Context, req *domain.PlacementRequest) []domain.ServiceResult {
+	var serviceIDs []string
+	for serviceID := range s.config.ShadowServices {
+		serviceIDs = append(serviceIDs, serviceID)
+	}
+	if len(serviceIDs) == 0 {
+		return nil
+	}
+	sort.Strings(serviceIDs)
+
+	if req.DeadlineMs > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(req.DeadlineMs)*time.Millisecond)
+		defer cancel()
+	}
+
+	results := make([]domain.ServiceResult, len(serviceIDs))
+	var wg sync.WaitGroup
+	for i, serviceID := range serviceIDs {
+		placementClient := s.clients[serviceID]
+		if placementClient.CircuitState() == client.StateOpen {
+			results[i] = skippedResult(serviceID, s.config.Services[serviceID].Name, client.StateOpen)
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, serviceID string, placementClient *client.PlacementClient) {
+			defer wg.Done()
+			results[i] = s.callService(ctx, serviceID, placementClient, func(ctx context.Context, placementClient *client.PlacementClient) (*domain.PlacementResponse, error) {
+				return placementClient.AnalyzePlacement(ctx, req)
+			})
+		}(i, serviceID, placementClient)
+	}
+	wg.Wait()
+
+	s.deriveSlotSignals(ctx, req, results)
+	s.rankResults(s.policies.Current(), results)
+	return results
+}
+
+// recordShadowComparisons сохраняет предложения теневых сервисов рядом с реальным решением;
+// ошибка только логируется, так как на размещение она не влияет
+func (s *OrchestratorService) recordShadowComparisons(ctx context.Context, req *domain.PlacementRequest, shadow []domain.ServiceResult, decision *domain.OrchestratorResponse) {
+	if len(shadow) == 0 {
+		return
+	}
+
+	comparisons := make([]domain.ShadowComparison, 0, len(shadow))
+	for _, result := range shadow {
+		if result.Skipped || result.TimedOut {
+			continue
+		}
+		comparisons = append(comparisons, domain.ShadowComparison{
+			ItemID:            req.ItemID,
+			BatchID:           req.BatchID,
+			ShadowService:     result.ServiceID,
+			ShadowSuccess:     result.Response.Success,
+			ShadowSlotID:      result.Response.SlotID,
+			ShadowScore:       result.FinalScore,
+			DecisionSuccess:   decision.Success,
+			DecisionAlgorithm: decision.Algorithm,
+			DecisionSlotID:    decision.SlotID,
+			DecisionScore:     decision.Score,
+		})
+	}
+	if len(comparisons) == 0 {
+		return
+	}
+
+	if err := s.repo.SaveShadowComparisons(context.WithoutCancel(ctx), comparisons); err != nil {
+		log.Printf("Ошибка сохранения сравнения теневых сервисов: %v", err)
+	}
+}
+
+// ShadowReport возвращает для каждого теневого сервиса долю совпадений с реальными решениями
+// и средние разницы оценок и расстояний до выхода за период
+func (s *OrchestratorService) ShadowReport(ctx context.Context, from, to *time.Time) (*domain.ShadowReport, error) {
+	algorithms, err := s.repo.GetShadowReport(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range algorithms {
+		algorithms[i].ServiceName = s.config.Services[algorithms[i].ServiceID].Name
+	}
+	if algorithms == nil {
+		algorithms = []domain.ShadowAlgorithmReport{}
+	}
+
+	return &domain.ShadowReport{From: from, To: to, Algorithms: algorithms}, nil
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"log"
+
+	"warehouse/services/orchestrator/internal/domain"
+	"warehouse/services/orchestrator/internal/scoring"
+)
+
+// xyzZones - зона хранения, соответствующая XYZ-категории, как в сервисе XYZ-размещения
+var xyzZones = map[string]string{
+	"X": "fast-access",
+	"Y": "regular",
+	"Z": "deep",
+}
+
+// deriveSlotSignals рассчитывает признаки скоринга, зависящие от предложенной ячейки:
+// no_placement_history - товар еще не размещался в ячейке по placement_logs,
+// xyz_compliant - зона ячейки соответствует XYZ-категории товара. Если данные склада
+// недоступны, признаки остаются выключенными. Возвращает true, если признаки рассчитаны
+// для каждой предложенной ячейки
+func (s *OrchestratorService) deriveSlotSignals(ctx context.Context, req *domain.PlacementRequest, results []domain.ServiceResult) bool {
+	var slotIDs []string
+	for _, result := range results {
+		if result.Response.Success && result.Response.SlotID != "" {
+			slotIDs = append(slotIDs, result.Response.SlotID)
+		}
+	}
+	if len(slotIDs) == 0 {
+		return false
+	}
+
+	signals, err := s.repo.GetSlotSignals(ctx, req.ItemID, slotIDs)
+	if err != nil {
+		log.Printf("Ошибка загрузки данных ячеек для скоринга: %v", err)
+		return false
+	}
+
+	derived := true
+	targetZone := xyzZones[req.XYZClass]
+	for i := range results {
+		if !results[i].Response.Success {
+			continue
+		}
+		slot, ok := signals[results[i].Response.SlotID]
+		if !ok {
+			derived = false
+			continue
+		}
+		results[i].Response.NoPlacementHistory = !slot.HasHistory
+		results[i].Response.XYZCompliant = targetZone != "" && slot.ZoneType == targetZone
+	}
+	return derived
+}
+
+// signalSources описывает, какие признаки скоринга рассчитаны по данным, а какие взяты из запроса.
+// Параметры товара сверены со справочником при дополнении запроса, поэтому считаются данными.
+// Признаки ячеек считаются данными, только если slotSignalsDerived (результат deriveSlotSignals),
+// иначе они остались выключенными по умолчанию
+func signalSources(slotSignalsDerived bool) map[string]string {
+	slotSource := domain.SignalSourceData
+	if !slotSignalsDerived {
+		slotSource = domain.SignalSourceDefault
+	}
+	return map[string]string{
+		scoring.BonusHasFixedSlot:       domain.SignalSourceData,
+		scoring.BonusHighWarehouseLoad:  domain.SignalSourceData,
+		scoring.BonusHighTurnover:       domain.SignalSourceData,
+		scoring.BonusHeavyItem:          domain.SignalSourceData,
+		scoring.BonusNoPlacementHistory: slotSource,
+		scoring.BonusXYZCompliant:       slotSource,
+		scoring.BonusFastAccessZone:     domain.SignalSourceRequest,
+	}
+}
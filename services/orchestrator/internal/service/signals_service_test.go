@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"warehouse/services/orchestrator/internal/domain"
+	"warehouse/services/orchestrator/internal/repository"
+	"warehouse/services/orchestrator/internal/scoring"
+)
+
+// signalsRepo отдает данные ячеек для скоринга; остальные методы репозитория тесту не нужны
+type signalsRepo struct {
+	repository.Repository
+	signals map[string]domain.SlotSignals
+	err     error
+}
+
+func (r *signalsRepo) GetSlotSignals(ctx context.Context, itemID string, slotIDs []string) (map[string]domain.SlotSignals, error) {
+	return r.signals, r.err
+}
+
+func TestDeriveSlotSignals(t *testing.T) {
+	proposed := func(slotID string) domain.ServiceResult {
+		return domain.ServiceResult{Response: domain.PlacementResponse{Success: true, SlotID: slotID}}
+	}
+	rejected := domain.ServiceResult{Response: domain.PlacementResponse{Success: false, Comment: "нет ячеек"}}
+
+	tests := []struct {
+		name    string
+		repo    *signalsRepo
+		results []domain.ServiceResult
+		// wantSource - источник признаков ячеек в signal_sources
+		wantSource    string
+		wantCompliant []bool
+	}{
+		{
+			name: "данные есть для всех ячеек",
+			repo: &signalsRepo{signals: map[string]domain.SlotSignals{
+				"A": {ZoneType: "fast-access"},
+				"B": {ZoneType: "deep", HasHistory: true},
+			}},
+			results:       []domain.ServiceResult{proposed("A"), proposed("B"), rejected},
+			wantSource:    domain.SignalSourceData,
+			wantCompliant: []bool{true, false, false},
+		},
+		{
+			name:          "данные ячеек не загрузились",
+			repo:          &signalsRepo{err: errors.New("база недоступна")},
+			results:       []domain.ServiceResult{proposed("A")},
+			wantSource:    domain.SignalSourceDefault,
+			wantCompliant: []bool{false},
+		},
+		{
+			name: "ячейки нет в данных склада",
+			repo: &signalsRepo{signals: map[string]domain.SlotSignals{
+				"A": {ZoneType: "fast-access"},
+			}},
+			results:       []domain.ServiceResult{proposed("A"), proposed("GONE")},
+			wantSource:    domain.SignalSourceDefault,
+			wantCompliant: []bool{true, false},
+		},
+		{
+			name:          "ни одной предложенной ячейки",
+			repo:          &signalsRepo{},
+			results:       []domain.ServiceResult{rejected},
+			wantSource:    domain.SignalSourceDefault,
+			wantCompliant: []bool{false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &OrchestratorService{repo: tt.repo}
+			req := &domain.PlacementRequest{ItemID: "ITEM001", XYZClass: "X"}
+
+			sources := signalSources(s.deriveSlotSignals(context.Background(), req, tt.results))
+			for _, bonus := range []string{scoring.BonusNoPlacementHistory, scoring.BonusXYZCompliant} {
+				if sources[bonus] != tt.wantSource {
+					t.Errorf("источник %s = %q, ожидался %q", bonus, sources[bonus], tt.wantSource)
+				}
+			}
+			if sources[scoring.BonusHasFixedSlot] != domain.SignalSourceData {
+				t.Errorf("источник %s = %q, ожидался %q", scoring.BonusHasFixedSlot, sources[scoring.BonusHasFixedSlot], domain.SignalSourceData)
+			}
+			for i, want := range tt.wantCompliant {
+				if got := tt.results[i].Response.XYZCompliant; got != want {
+					t.Errorf("результат %d: xyz_compliant = %v, ожидалось %v", i+1, got, want)
+				}
+			}
+		})
+	}
+}
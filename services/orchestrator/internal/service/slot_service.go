@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"warehouse/pkg/capacity"
+	"warehouse/services/orchestrator/internal/domain"
+	"warehouse/services/orchestrator/internal/repository"
+)
+
+var (
+	// ErrSlotNotFound означает, что в справочнике нет ячейки с таким идентификатором
+	ErrSlotNotFound = errors.New("ячейка не найдена")
+	// ErrSlotConflict означает, что изменение ячейки противоречит ее содержимому или справочнику
+	ErrSlotConflict = errors.New("изменение ячейки отклонено")
+)
+
+const (
+	maxSlotIDLength = 50
+	maxSlotBatch    = 1000
+)
+
+// slotStatuses - статусы, которые можно задать ячейке
+var slotStatuses = map[string]bool{
+	capacity.SlotActive:  true,
+	capacity.SlotBlocked: true,
+	capacity.SlotRetired: true,
+}
+
+// mixingPolicies - политики смешивания ячейки
+var mixingPolicies = map[string]bool{
+	capacity.MixingSingleBatch: true,
+	capacity.MixingSameItem:    true,
+	capacity.MixingMixed:       true,
+}
+
+// Slots ищет ячейки справочника по складу, зоне и статусу
+func (s *OrchestratorService) Slots(ctx context.Context, filter domain.SlotFilter) ([]domain.Slot, error) {
+	if err := s.checkWarehouseFilter(ctx, filter.WarehouseID); err != nil {
+		return nil, err
+	}
+	if filter.Status != "" && !slotStatuses[filter.Status] {
+		return nil, fmt.Errorf("%w: неизвестный статус ячейки: %s", ErrInvalidRequest, filter.Status)
+	}
+	return s.repo.FindSlots(ctx, filter)
+}
+
+// Slot возвращает ячейку справочника
+func (s *OrchestratorService) Slot(ctx context.Context, slotID string) (*domain.Slot, error) {
+	slot, err := s.repo.GetSlot(ctx, slotID)
+	if err != nil {
+		return nil, err
+	}
+	if slot == nil {
+		return nil, fmt.Errorf("%w: %s", ErrSlotNotFound, slotID)
+	}
+	return slot, nil
+}
+
+// SlotAudit возвращает журнал изменений ячейки
+func (s *OrchestratorService) SlotAudit(ctx context.Context, slotID string) ([]domain.SlotAuditRecord, error) {
+	if _, err := s.Slot(ctx, slotID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetSlotAudit(ctx, slotID)
+}
+
+// SaveSlot создает или изменяет одну ячейку в режиме mode (domain.SlotSave*)
+func (s *OrchestratorService) SaveSlot(ctx context.Context, slot *domain.Slot, mode, changedBy string) (*domain.SlotChange, error) {
+	if err := s.prepareSlot(ctx, slot); err != nil {
+		return nil, err
+	}
+	if mode == domain.SlotSaveUpdate {
+		if _, err := s.Slot(ctx, slot.SlotID); err != nil {
+			return nil, err
+		}
+	}
+	changes, err := s.saveSlots(ctx, []domain.Slot{*slot}, mode, changedBy)
+	if err != nil {
+		return nil, err
+	}
+	return &changes[0], nil
+}
+
+// SaveSlots создает и изменяет пакет ячеек одной транзакцией: если хотя бы одну ячейку сохранить
+// нельзя, пакет отклоняется целиком
+func (s *OrchestratorService) SaveSlots(ctx context.Context, req *domain.SlotBatchRequest, changedBy string) (*domain.SlotBatchResponse, error) {
+	if len(req.Slots) == 0 {
+		return nil, fmt.Errorf("%w: пустой пакет ячеек", ErrInvalidRequest)
+	}
+	if len(req.Slots) > maxSlotBatch {
+		return nil, fmt.Errorf("%w: в пакете больше %d ячеек", ErrInvalidRequest, maxSlotBatch)
+	}
+	seen := map[string]bool{}
+	for i := range req.Slots {
+		if err := s.prepareSlot(ctx, &req.Slots[i]); err != nil {
+			return nil, fmt.Errorf("строка %d: %w", i+1, err)
+		}
+		if seen[req.Slots[i].SlotID] {
+			return nil, fmt.Errorf("%w: ячейка %s указана в пакете дважды", ErrInvalidRequest, req.Slots[i].SlotID)
+		}
+		seen[req.Slots[i].SlotID] = true
+	}
+
+	changes, err := s.saveSlots(ctx, req.Slots, domain.SlotSaveUpsert, changedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.SlotBatchResponse{Slots: changes}
+	for _, change := range changes {
+		switch change.Action {
+		case domain.SlotActionCreated:
+			response.Created++
+		case domain.SlotActionUpdated:
+			response.Updated++
+		default:
+			response.Unchanged++
+		}
+	}
+	return response, nil
+}
+
+// RetireSlot выводит пустую ячейку из справочника
+func (s *OrchestratorService) RetireSlot(ctx context.Context, slotID, changedBy string) (*domain.SlotChange, error) {
+	if _, err := s.Slot(ctx, slotID); err != nil {
+		return nil, err
+	}
+	change, err := s.repo.RetireSlot(ctx, slotID, changedBy)
+	return change, slotError(err)
+}
+
+func (s *OrchestratorService) saveSlots(ctx context.Context, slots []domain.Slot, mode, changedBy string) ([]domain.SlotChange, error) {
+	changes, err := s.repo.SaveSlots(ctx, slots, mode, changedBy)
+	return changes, slotError(err)
+}
+
+// slotError переводит ошибки справочника ячеек в ошибки запроса. Ячейка, которой не оказалось
+// при сохранении, тоже считается конфликтом
+func slotError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, repository.ErrSlotNotFound), errors.Is(err, repository.ErrSlotExists), errors.Is(err, repository.ErrSlotInUse):
+		return fmt.Errorf("%w: %s", ErrSlotConflict, err.Error())
+	}
+	return fmt.Errorf("ошибка сохранения ячеек: %w", err)
+}
+
+// prepareSlot проверяет параметры ячейки и подставляет значения по умолчанию: основной склад,
+// политику single_batch и статус active
+func (s *OrchestratorService) prepareSlot(ctx context.Context, slot *domain.Slot) error {
+	if slot.SlotID == "" {
+		return fmt.Errorf("%w: не указан slot_id", ErrInvalidRequest)
+	}
+	if len(slot.SlotID) > maxSlotIDLength {
+		return fmt.Errorf("%w: slot_id длиннее %d символов", ErrInvalidRequest, maxSlotIDLength)
+	}
+	warehouseID, err := s.resolveWarehouse(ctx, slot.WarehouseID)
+	if err != nil {
+		return err
+	}
+	slot.WarehouseID = warehouseID
+
+	if slot.MixingPolicy == "" {
+		slot.MixingPolicy = capacity.MixingSingleBatch
+	}
+	if slot.Status == "" {
+		slot.Status = capacity.SlotActive
+	}
+
+	switch {
+	case slot.MaxWeight <= 0 || slot.MaxLength <= 0 || slot.MaxWidth <= 0 || slot.MaxHeight <= 0:
+		return fmt.Errorf("%w: ячейка %s: max_weight, max_length, max_width и max_height должны быть больше нуля", ErrInvalidRequest, slot.SlotID)
+	case slot.MaxUnits != nil && *slot.MaxUnits <= 0:
+		return fmt.Errorf("%w: ячейка %s: max_units должно быть больше нуля", ErrInvalidRequest, slot.SlotID)
+	case slot.Level < 1:
+		return fmt.Errorf("%w: ячейка %s: level должен быть не меньше 1", ErrInvalidRequest, slot.SlotID)
+	case slot.DistanceFromExit < 0:
+		return fmt.Errorf("%w: ячейка %s: distance_from_exit не может быть отрицательным", ErrInvalidRequest, slot.SlotID)
+	}
+	if _, ok := zoneRank[slot.ZoneType]; !ok {
+		return fmt.Errorf("%w: ячейка %s: неизвестная зона: %s", ErrInvalidRequest, slot.SlotID, slot.ZoneType)
+	}
+	if !mixingPolicies[slot.MixingPolicy] {
+		return fmt.Errorf("%w: ячейка %s: неизвестная политика смешивания: %s", ErrInvalidRequest, slot.SlotID, slot.MixingPolicy)
+	}
+	if !slotStatuses[slot.Status] {
+		return fmt.Errorf("%w: ячейка %s: неизвестный статус: %s", ErrInvalidRequest, slot.SlotID, slot.Status)
+	}
+	return nil
+}
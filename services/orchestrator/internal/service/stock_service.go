@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"warehouse/pkg/placement"
+	"warehouse/services/orchestrator/internal/domain"
+)
+
+// defaultExpiryWindowDays - окно отчета о сроках годности по умолчанию: то же, после которого
+// сервисы ABC и XYZ размещают партию ближе к выходу
+const defaultExpiryWindowDays = int(placement.ShortDatedWindow / (24 * time.Hour))
+
+// loadTolerance - допустимое расхождение веса и объема ячейки с остатками из-за округления
+const loadTolerance = 1e-6
+
+// Stock возвращает остатки по складу, ячейке, товару и партии
+func (s *OrchestratorService) Stock(ctx context.Context, filter domain.StockFilter) (*domain.StockResponse, error) {
+	if err := s.checkWarehouseFilter(ctx, filter.WarehouseID); err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.FindStock(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &domain.StockResponse{Entries: entries}
+	for _, entry := range entries {
+		response.Total += entry.Quantity
+	}
+	return response, nil
+}
+
+// CheckStockConsistency сверяет остатки slot_stock с заполненностью и занятостью каждой ячейки
+// склада warehouseID; пустой warehouseID - всех складов
+func (s *OrchestratorService) CheckStockConsistency(ctx context.Context, warehouseID string) (*domain.StockConsistencyReport, error) {
+	if err := s.checkWarehouseFilter(ctx, warehouseID); err != nil {
+		return nil, err
+	}
+	totals, err := s.repo.GetSlotStockTotals(ctx, warehouseID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.StockConsistencyReport{
+		WarehouseID:   warehouseID,
+		SlotsChecked:  len(totals),
+		Discrepancies: []domain.StockDiscrepancy{},
+	}
+	for _, slot := range totals {
+		if slot.IsOccupied && slot.StockUnits == 0 {
+			slot.Problems = append(slot.Problems, domain.StockProblemOccupiedEmpty)
+		}
+		if !slot.IsOccupied && slot.StockUnits > 0 {
+			slot.Problems = append(slot.Problems, domain.StockProblemFreeWithStock)
+		}
+		if slot.UsedUnits != slot.StockUnits {
+			slot.Problems = append(slot.Problems, domain.StockProblemUnitsMismatch)
+		}
+		if math.Abs(slot.UsedWeight-slot.StockWeight) > loadTolerance || math.Abs(slot.UsedVolume-slot.StockVolume) > loadTolerance {
+			slot.Problems = append(slot.Problems, domain.StockProblemLoadMismatch)
+		}
+		if len(slot.Problems) > 0 {
+			report.Discrepancies = append(report.Discrepancies, slot)
+		}
+	}
+	report.Consistent = len(report.Discrepancies) == 0
+	return report, nil
+}
+
+// ExpiryReport возвращает остатки просроченных партий и партий, срок годности которых истекает
+// в ближайшие withinDays дней (0 - окно по умолчанию) на складе warehouseID; пустой warehouseID - на всех складах
+func (s *OrchestratorService) ExpiryReport(ctx context.Context, warehouseID string, withinDays int) (*domain.ExpiryReport, error) {
+	if withinDays < 0 {
+		return nil, fmt.Errorf("%w: days не может быть отрицательным", ErrInvalidRequest)
+	}
+	if err := s.checkWarehouseFilter(ctx, warehouseID); err != nil {
+		return nil, err
+	}
+	if withinDays == 0 {
+		withinDays = defaultExpiryWindowDays
+	}
+
+	entries, err := s.repo.FindExpiringStock(ctx, warehouseID, withinDays)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ExpiryReport{WarehouseID: warehouseID, WithinDays: withinDays, Entries: entries}
+	for i := range entries {
+		if entries[i].DaysLeft <= 0 {
+			entries[i].Status = domain.ExpiryStatusExpired
+			report.ExpiredUnits += entries[i].Quantity
+		} else {
+			entries[i].Status = domain.ExpiryStatusNearExpiry
+			report.NearExpiryUnits += entries[i].Quantity
+		}
+	}
+	return report, nil
+}
+
+// checkWarehouseFilter проверяет склад фильтра отчета; пустой фильтр означает все склады
+func (s *OrchestratorService) checkWarehouseFilter(ctx context.Context, warehouseID string) error {
+	if warehouseID == "" {
+		return nil
+	}
+	_, err := s.resolveWarehouse(ctx, warehouseID)
+	return err
+}
@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"warehouse/pkg/placement"
+	"warehouse/services/orchestrator/internal/domain"
+)
+
+// Warehouses возвращает склады со сводкой по их ячейкам
+func (s *OrchestratorService) Warehouses(ctx context.Context) ([]domain.Warehouse, error) {
+	return s.repo.GetWarehouses(ctx)
+}
+
+// resolveWarehouse проверяет, что склад существует, и возвращает его идентификатор;
+// пустой идентификатор означает склад по умолчанию
+func (s *OrchestratorService) resolveWarehouse(ctx context.Context, warehouseID string) (string, error) {
+	if warehouseID == "" {
+		warehouseID = placement.DefaultWarehouseID
+	}
+	warehouse, err := s.repo.GetWarehouse(ctx, warehouseID)
+	if err != nil {
+		return "", fmt.Errorf("ошибка загрузки склада %s: %w", warehouseID, err)
+	}
+	if warehouse == nil {
+		return "", fmt.Errorf("%w: склад %s не найден", ErrInvalidRequest, warehouseID)
+	}
+	return warehouseID, nil
+}
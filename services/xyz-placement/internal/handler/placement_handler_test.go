@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"context"
+	"testing"
+
+	"warehouse/pkg/placement/placementtest"
+	"warehouse/services/xyz-placement/internal/repository"
+	"warehouse/services/xyz-placement/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// contractRepo is a repository without items or batches: contract cases are either rejected before
+// reaching the database or analyze a missing item, so the other methods are never called
+type contractRepo struct {
+	repository.Repository
+}
+
+func (r *contractRepo) ItemExists(ctx context.Context, itemID string) (bool, error) {
+	return false, nil
+}
+
+func (r *contractRepo) BatchExists(ctx context.Context, batchID string) (bool, error) {
+	return false, nil
+}
+
+// TestContract runs the placementtest contract suite against the service routes
+func TestContract(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewPlacementHandler(service.NewPlacementService(&contractRepo{})).RegisterRoutes(router)
+
+	if err := placementtest.Check(router, "/api/v1/xyz-placement"); err != nil {
+		t.Fatal(err)
+	}
+}
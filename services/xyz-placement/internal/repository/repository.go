@@ -2,6 +2,9 @@ package repository
 
 import (
 	"context"
+	"time"
+
+	"warehouse/pkg/capacity"
 	"warehouse/services/xyz-placement/internal/domain"
 )
 
@@ -12,9 +15,11 @@ type Repository interface {
 
 	BatchExists(ctx context.Context, batchID string) (bool, error)
 
+	GetBatchExpiry(ctx context.Context, batchID string) (*time.Time, error)
+
 	GetItemMr(ctx context.Context, itemID string) (float64, error)
 
-	GetAvailableSlots(ctx context.Context, zoneType string) ([]domain.Slot, error)
+	GetAvailableSlots(ctx context.Context, warehouseID, zoneType string, load capacity.Load) ([]domain.Slot, error)
 
 	CreatePlacementRequest(ctx context.Context, req *domain.PlaceRequest) (int, error)
 	
@@ -23,4 +28,26 @@ type Repository interface {
 	CreatePlacementLog(ctx context.Context, slotID, itemID, batchID, algorithm string) error
 
 	CreatePlacementResponse(ctx context.Context, requestID int, success bool, slotID, algorithm string, score float64, comment string) error
-} 
\ No newline at end of file
+
+	ResolveLoad(ctx context.Context, itemID, batchID string, quantity int) (capacity.Load, error)
+
+	// GetTravelDistances returns walking distances from the dock (the nearest one if empty) to the slots
+	// of the warehouse, or nil if the warehouse has no topology
+	GetTravelDistances(ctx context.Context, warehouseID, dockID string) (map[string]float64, error)
+
+	ReserveSlot(ctx context.Context, warehouseID, slotID, token string, ttl time.Duration, load capacity.Load) (bool, error)
+
+	CommitReservation(ctx context.Context, warehouseID, token, itemID, batchID, algorithm string, quantity int) (string, error)
+
+	PlaceInSlot(ctx context.Context, slotID, itemID, batchID, algorithm string, quantity int) error
+
+	ReleaseReservation(ctx context.Context, warehouseID, token string) (bool, error)
+
+	Ping(ctx context.Context) error
+
+	BeginIdempotentRequest(ctx context.Context, scope, key, requestHash string) (*domain.IdempotencyRecord, bool, error)
+
+	CompleteIdempotentRequest(ctx context.Context, scope, key string, response []byte) error
+
+	DeleteIdempotentRequest(ctx context.Context, scope, key string) error
+}
@@ -3,35 +3,49 @@ package service
 import (
 	"context"
 	"fmt"
-	"sort"
 
+	"warehouse/pkg/placement"
+	"warehouse/pkg/reservation"
 	"warehouse/services/xyz-placement/internal/domain"
 	"warehouse/services/xyz-placement/internal/repository"
 )
 
 type PlacementService struct {
-	repo repository.Repository
+	repo         repository.Repository
+	reservations *reservation.Manager
 }
 
 
 func NewPlacementService(repo repository.Repository) *PlacementService {
-	return &PlacementService{repo: repo}
+	s := &PlacementService{repo: repo}
+	s.reservations = reservation.NewManager(repo, "xyz_placement", s.Candidates)
+	return s
 }
 
 
 func (s *PlacementService) AnalyzePlacement(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
+	candidates, rejection, err := s.RankCandidates(ctx, req, 1)
+	if err != nil || rejection != nil {
+		return rejection, err
+	}
+	return &candidates[0], nil
+}
+
+// RankCandidates returns up to limit suitable slots, best first (limit <= 0 means all).
+// If no slot fits, it returns a rejection response explaining why
+func (s *PlacementService) RankCandidates(ctx context.Context, req *domain.PlaceRequest, limit int) ([]domain.PlaceResponse, *domain.PlaceResponse, error) {
 
 	itemExists, err := s.repo.ItemExists(ctx, req.ItemID)
 	if err != nil {
-		return nil, fmt.Errorf("error checking item existence: %w", err)
+		return nil, nil, fmt.Errorf("error checking item existence: %w", err)
 	}
 	batchExists, err := s.repo.BatchExists(ctx, req.BatchID)
 	if err != nil {
-		return nil, fmt.Errorf("error checking batch existence: %w", err)
+		return nil, nil, fmt.Errorf("error checking batch existence: %w", err)
 	}
 
 	if !itemExists || !batchExists {
-		return &domain.PlaceResponse{
+		return nil, &domain.PlaceResponse{
 			Success: false,
 			Comment: "Item or batch not found",
 			Score:   0,
@@ -41,62 +55,63 @@ func (s *PlacementService) AnalyzePlacement(ctx context.Context, req *domain.Pla
 
 	mr, err := s.repo.GetItemMr(ctx, req.ItemID)
 	if err != nil {
-		return nil, fmt.Errorf("error getting item Mr: %w", err)
+		return nil, nil, fmt.Errorf("error getting item Mr: %w", err)
 	}
 
-	var xyzCategory string
-	if mr < 0.1 {
-		xyzCategory = "X" 
-	} else if mr < 0.25 {
-		xyzCategory = "Y" 
-	} else {
-		xyzCategory = "Z"
-	}
+	xyzCategory := placement.XYZClass(mr)
+	targetZoneType := placement.ClassZone(xyzCategory)
 
-
-	var targetZoneType string
-	switch xyzCategory {
-	case "X":
-		targetZoneType = "fast-access"
-	case "Y":
-		targetZoneType = "regular"
-	case "Z":
-		targetZoneType = "deep"
-	default:
-		targetZoneType = "regular"
+	// Short-dated batches go one zone closer to the exit (FEFO)
+	targetZoneType, err = s.zoneForExpiry(ctx, req.BatchID, targetZoneType)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	
-	slots, err := s.repo.GetAvailableSlots(ctx, targetZoneType)
+	load, err := s.repo.ResolveLoad(ctx, req.ItemID, req.BatchID, req.Quantity)
 	if err != nil {
-		return nil, fmt.Errorf("error getting available slots: %w", err)
+		return nil, nil, fmt.Errorf("error resolving load: %w", err)
 	}
 
+	slots, err := s.repo.GetAvailableSlots(ctx, req.Warehouse(), targetZoneType, load)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error getting available slots: %w", err)
+	}
 
-	sort.Slice(slots, func(i, j int) bool {
-		return slots[i].DistanceFromExit < slots[j].DistanceFromExit
-	})
 
+	if rejection, err := s.orderSlots(ctx, req, slots); err != nil || rejection != nil {
+		return nil, rejection, err
+	}
 
-	var suggestedSlotID string
-	if len(slots) > 0 {
-		suggestedSlotID = slots[0].SlotID
-		return &domain.PlaceResponse{
-			Success: true,
-			SlotID:  suggestedSlotID,
-			Comment: fmt.Sprintf("Suggested placement in zone %s (XYZ category %s, Mr: %.2f)", targetZoneType, xyzCategory, mr),
-			Score:   0.9,
+
+	if len(slots) == 0 {
+		return nil, &domain.PlaceResponse{
+			Success: false,
+			Comment: fmt.Sprintf("No available slots found in zone %s (XYZ category %s, Mr: %.2f)", targetZoneType, xyzCategory, mr),
+			Score:   0,
 		}, nil
 	}
 
-	return &domain.PlaceResponse{
-		Success: false,
-		Comment: fmt.Sprintf("No available slots found in zone %s (XYZ category %s, Mr: %.2f)", targetZoneType, xyzCategory, mr),
-		Score:   0,
-	}, nil
+	if limit > 0 && len(slots) > limit {
+		slots = slots[:limit]
+	}
+
+	candidates := make([]domain.PlaceResponse, 0, len(slots))
+	for _, slot := range slots {
+		candidates = append(candidates, domain.PlaceResponse{
+			Success:        true,
+			SlotID:         slot.SlotID,
+			Comment:        fmt.Sprintf("Suggested placement in zone %s (XYZ category %s, Mr: %.2f)", targetZoneType, xyzCategory, mr),
+			Score:          0.9,
+			BaseScore:      0.9,
+			DistanceToExit: slot.ExitDistance(),
+		})
+	}
+	return candidates, nil, nil
 }
 
 
+
 func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceRequest) (*domain.PlaceResponse, error) {
 
 	requestID, err := s.repo.CreatePlacementRequest(ctx, req)
@@ -129,38 +144,30 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 	}
 
 
-	var xyzCategory string
-	if mr < 0.1 {
-		xyzCategory = "X"
-	} else if mr < 0.25 {
-		xyzCategory = "Y"
-	} else {
-		xyzCategory = "Z"
+	xyzCategory := placement.XYZClass(mr)
+	targetZoneType := placement.ClassZone(xyzCategory)
+
+	// Short-dated batches go one zone closer to the exit (FEFO)
+	targetZoneType, err = s.zoneForExpiry(ctx, req.BatchID, targetZoneType)
+	if err != nil {
+		return nil, err
 	}
 
 
-	var targetZoneType string
-	switch xyzCategory {
-	case "X":
-		targetZoneType = "fast-access"
-	case "Y":
-		targetZoneType = "regular"
-	case "Z":
-		targetZoneType = "deep"
-	default:
-		targetZoneType = "regular"
+	load, err := s.repo.ResolveLoad(ctx, req.ItemID, req.BatchID, req.Quantity)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving load: %w", err)
 	}
 
-
-	slots, err := s.repo.GetAvailableSlots(ctx, targetZoneType)
+	slots, err := s.repo.GetAvailableSlots(ctx, req.Warehouse(), targetZoneType, load)
 	if err != nil {
 		return nil, fmt.Errorf("error getting available slots: %w", err)
 	}
 
 
-	sort.Slice(slots, func(i, j int) bool {
-		return slots[i].DistanceFromExit < slots[j].DistanceFromExit
-	})
+	if rejection, err := s.orderSlots(ctx, req, slots); err != nil || rejection != nil {
+		return rejection, err
+	}
 
 
 	var chosenSlotID string
@@ -168,26 +175,23 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 		chosenSlotID = slots[0].SlotID
 
 
-		if err := s.repo.UpdateSlotOccupation(ctx, chosenSlotID, true); err != nil {
-			return nil, fmt.Errorf("error updating slot occupation: %w", err)
+		if err := s.repo.PlaceInSlot(ctx, chosenSlotID, req.ItemID, req.BatchID, "xyz_placement", req.Quantity); err != nil {
+			return nil, fmt.Errorf("error placing item in slot: %w", err)
 		}
 
 
-		if err := s.repo.CreatePlacementLog(ctx, chosenSlotID, req.ItemID, req.BatchID, "xyz_placement"); err != nil {
-
-			fmt.Printf("Error creating placement log: %v\n", err)
-		}
-
 		if err := s.repo.CreatePlacementResponse(ctx, requestID, true, chosenSlotID, "xyz_placement", 1.0, fmt.Sprintf("Item placed in slot %s in zone %s (XYZ category %s, Mr: %.2f)", chosenSlotID, targetZoneType, xyzCategory, mr)); err != nil {
 
 			fmt.Printf("Error creating placement response: %v\n", err)
 		}
 
 		return &domain.PlaceResponse{
-			Success: true,
-			SlotID:  chosenSlotID,
-			Comment: fmt.Sprintf("Item placed successfully in slot %s", chosenSlotID),
-			Score:   1.0,
+			Success:        true,
+			SlotID:         chosenSlotID,
+			Comment:        fmt.Sprintf("Item placed successfully in slot %s", chosenSlotID),
+			Score:          1.0,
+			BaseScore:      1.0,
+			DistanceToExit: slots[0].ExitDistance(),
 		}, nil
 	}
 
@@ -196,4 +200,12 @@ func (s *PlacementService) PlaceItem(ctx context.Context, req *domain.PlaceReque
 		Comment: fmt.Sprintf("No available slots found in zone %s (XYZ category %s, Mr: %.2f) for placement", targetZoneType, xyzCategory, mr),
 		Score:   0,
 	}, nil
-} 
\ No newline at end of file
+}
+
+// HealthCheck verifies that the service can reach its database
+func (s *PlacementService) HealthCheck(ctx context.Context) error {
+	if err := s.repo.Ping(ctx); err != nil {
+		return fmt.Errorf("database is unavailable: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"warehouse/pkg/topology"
+	"warehouse/services/xyz-placement/internal/domain"
+)
+
+// orderSlots sorts slots by the walking distance from the request's dock, see topology.OrderSlots.
+// An unknown dock yields a rejection response
+func (s *PlacementService) orderSlots(ctx context.Context, req *domain.PlaceRequest, slots []domain.Slot) (*domain.PlaceResponse, error) {
+	distances, err := s.repo.GetTravelDistances(ctx, req.Warehouse(), req.DockID)
+	if errors.Is(err, topology.ErrUnknownDock) {
+		return &domain.PlaceResponse{
+			Success: false,
+			Comment: err.Error(),
+			Score:   0,
+		}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting travel distances: %w", err)
+	}
+
+	topology.OrderSlots(slots, distances)
+	return nil, nil
+}
@@ -22,11 +22,26 @@ CREATE TABLE IF NOT EXISTS batches (
     batch_id VARCHAR(50) PRIMARY KEY,
     item_id VARCHAR(50) REFERENCES items(item_id),
     quantity INTEGER NOT NULL,
+    lot_number VARCHAR(50), -- номер лота производителя
+    manufactured_at DATE, -- дата производства
+    expires_at DATE, -- срок годности: с этой даты партия просрочена; NULL - бессрочная
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_batches_expires_at ON batches (expires_at) WHERE expires_at IS NOT NULL;
+
+-- Склады. Ячейки, остатки в них и фиксированные ячейки товаров относятся к одному складу,
+-- а справочники товаров и партий общие
+CREATE TABLE IF NOT EXISTS warehouses (
+    warehouse_id VARCHAR(50) PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    location VARCHAR(255),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
 CREATE TABLE IF NOT EXISTS slots (
     slot_id VARCHAR(50) PRIMARY KEY,
+    warehouse_id VARCHAR(50) NOT NULL DEFAULT 'WH001' REFERENCES warehouses(warehouse_id), -- склад ячейки
     location_description VARCHAR(100),
     max_weight FLOAT NOT NULL,
     max_length FLOAT NOT NULL,
@@ -36,17 +51,50 @@ CREATE TABLE IF NOT EXISTS slots (
     is_occupied BOOLEAN DEFAULT false,
     zone_type VARCHAR(50) NOT NULL, -- 'fast-access', 'regular', 'deep'
     level INTEGER NOT NULL,
-    distance_from_exit INTEGER NOT NULL
+    distance_from_exit INTEGER NOT NULL,
+    reservation_token VARCHAR(64), -- токен резерва ячейки под размещение
+    reserved_until TIMESTAMP, -- резерв истекает автоматически после этого момента
+    max_units INTEGER, -- предел числа единиц товара; NULL - без ограничения
+    used_weight FLOAT NOT NULL DEFAULT 0, -- вес размещенного товара
+    used_volume FLOAT NOT NULL DEFAULT 0, -- объем размещенного товара
+    used_units INTEGER NOT NULL DEFAULT 0, -- число размещенных единиц товара
+    mixing_policy VARCHAR(20) NOT NULL DEFAULT 'single_batch', -- 'single_batch', 'same_item', 'mixed'
+    status VARCHAR(20) NOT NULL DEFAULT 'active' CHECK (status IN ('active', 'blocked', 'retired')), -- размещать можно только в active
+    UNIQUE (warehouse_id, slot_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_slots_warehouse_zone ON slots (warehouse_id, zone_type, distance_from_exit);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_slots_reservation_token ON slots (reservation_token) WHERE reservation_token IS NOT NULL;
+
+-- Журнал изменений справочника ячеек: кто и когда создал, изменил или вывел ячейку, с ее состоянием до и после.
+-- Ячейки не удаляются, а выводятся (status = 'retired'), поэтому журналы размещений и аудита сохраняют ссылки
+CREATE TABLE IF NOT EXISTS slot_audit (
+    audit_id BIGSERIAL PRIMARY KEY,
+    slot_id VARCHAR(50) NOT NULL REFERENCES slots(slot_id),
+    action VARCHAR(20) NOT NULL, -- created, updated, retired
+    changed_by VARCHAR(100) NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    before_state JSONB, -- NULL для created
+    after_state JSONB NOT NULL
 );
 
+CREATE INDEX IF NOT EXISTS idx_slot_audit_slot ON slot_audit (slot_id, audit_id);
+
+-- Фиксированные ячейки товаров; у товара может быть своя фиксированная ячейка на каждом складе
 CREATE TABLE IF NOT EXISTS item_slot_map (
+    warehouse_id VARCHAR(50) NOT NULL DEFAULT 'WH001' REFERENCES warehouses(warehouse_id),
     item_id VARCHAR(50) REFERENCES items(item_id),
     slot_id VARCHAR(50) REFERENCES slots(slot_id),
-    PRIMARY KEY (item_id, slot_id)
+    PRIMARY KEY (item_id, slot_id),
+    FOREIGN KEY (warehouse_id, slot_id) REFERENCES slots (warehouse_id, slot_id)
 );
 
+CREATE INDEX IF NOT EXISTS idx_item_slot_map_warehouse_item ON item_slot_map (warehouse_id, item_id);
+
 CREATE TABLE IF NOT EXISTS placement_requests (
     request_id SERIAL PRIMARY KEY,
+    warehouse_id VARCHAR(50), -- склад из запроса; без ссылки на warehouses, чтобы журналировать и запросы на неизвестный склад
     item_id VARCHAR(50) REFERENCES items(item_id),
     batch_id VARCHAR(50) REFERENCES batches(batch_id),
     quantity INTEGER NOT NULL,
@@ -69,10 +117,137 @@ CREATE TABLE IF NOT EXISTS placement_logs (
     slot_id VARCHAR(50) REFERENCES slots(slot_id),
     item_id VARCHAR(50) REFERENCES items(item_id),
     batch_id VARCHAR(50) REFERENCES batches(batch_id),
+    algorithm VARCHAR(50) NOT NULL, -- алгоритм размещения, picking для отбора, reslotting для перемещения или batch_placement для отмены размещения
+    movement VARCHAR(10) NOT NULL DEFAULT 'placed', -- placed - размещение, picked - отбор, moved_out/moved_in - перемещение, reverted - отмена размещения
+    quantity INTEGER, -- количество; NULL у размещений без количества - считается вся партия
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_placement_logs_item ON placement_logs (item_id, batch_id);
+CREATE INDEX IF NOT EXISTS idx_placement_logs_slot ON placement_logs (slot_id);
+
+-- Остатки: количество каждой партии товара в каждой ячейке. Меняются в одной транзакции
+-- с размещением, отбором или перемещением; строка с нулевым остатком удаляется.
+-- Склад остатка - склад его ячейки
+CREATE TABLE IF NOT EXISTS slot_stock (
+    slot_id VARCHAR(50) NOT NULL REFERENCES slots(slot_id),
+    item_id VARCHAR(50) NOT NULL REFERENCES items(item_id),
+    batch_id VARCHAR(50) NOT NULL DEFAULT '', -- пустая строка - товар без партии
+    quantity INTEGER NOT NULL CHECK (quantity >= 0),
+    placed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, -- первое поступление партии в ячейку
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (slot_id, item_id, batch_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_slot_stock_item ON slot_stock (item_id, batch_id);
+CREATE INDEX IF NOT EXISTS idx_slot_stock_batch ON slot_stock (batch_id);
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    scope VARCHAR(50) NOT NULL, -- сервис, принявший запрос: orchestrator, abc_placement, ...
+    idempotency_key VARCHAR(255) NOT NULL,
+    request_hash VARCHAR(64) NOT NULL, -- sha256 тела запроса
+    response JSONB, -- сохраненный ответ; NULL, пока запрос выполняется
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP,
+    PRIMARY KEY (scope, idempotency_key)
+);
+
+CREATE TABLE IF NOT EXISTS shadow_comparisons (
+    comparison_id SERIAL PRIMARY KEY,
+    item_id VARCHAR(50) REFERENCES items(item_id),
+    batch_id VARCHAR(50) REFERENCES batches(batch_id),
+    shadow_service VARCHAR(50) NOT NULL, -- сервис в теневом режиме: genetic, greedy, ...
+    shadow_success BOOLEAN NOT NULL,
+    shadow_slot_id VARCHAR(50) REFERENCES slots(slot_id),
+    shadow_score FLOAT NOT NULL,
+    decision_success BOOLEAN NOT NULL, -- реальное решение оркестратора по тому же запросу
+    decision_algorithm VARCHAR(50),
+    decision_slot_id VARCHAR(50) REFERENCES slots(slot_id),
+    decision_score FLOAT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_shadow_comparisons_service_time ON shadow_comparisons (shadow_service, created_at);
+
+CREATE TABLE IF NOT EXISTS orchestrator_decisions (
+    decision_id BIGSERIAL PRIMARY KEY,
+    operation VARCHAR(20) NOT NULL, -- analyze, place, place_batch
+    item_id VARCHAR(50) NOT NULL,
+    batch_id VARCHAR(50) NOT NULL,
+    slot_id VARCHAR(50), -- выбранная ячейка; NULL, если разместить не удалось
+    success BOOLEAN NOT NULL,
     algorithm VARCHAR(50) NOT NULL,
+    score FLOAT NOT NULL,
+    policy_version VARCHAR(50) NOT NULL,
+    request JSONB NOT NULL, -- запрос после дополнения мастер-данными
+    response JSONB NOT NULL, -- ответ оркестратора: все ответы сервисов, задержки, ошибки, разложение оценок
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+CREATE INDEX IF NOT EXISTS idx_orchestrator_decisions_item ON orchestrator_decisions (item_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_orchestrator_decisions_batch ON orchestrator_decisions (batch_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_orchestrator_decisions_slot ON orchestrator_decisions (slot_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_orchestrator_decisions_created ON orchestrator_decisions (created_at);
+
+-- Асинхронные задания оркестратора; очередь переживает перезапуск сервиса
+CREATE TABLE IF NOT EXISTS placement_jobs (
+    job_id BIGSERIAL PRIMARY KEY,
+    operation VARCHAR(20) NOT NULL, -- analyze, place, place_batch
+    state VARCHAR(10) NOT NULL DEFAULT 'queued', -- queued, running, done, failed
+    request JSONB NOT NULL,
+    response JSONB, -- ответ оркестратора, когда задание выполнено
+    error TEXT,
+    attempts INT NOT NULL DEFAULT 0, -- сколько раз задание брали в работу
+    locked_by VARCHAR(100), -- экземпляр оркестратора, выполняющий задание
+    lease_until TIMESTAMP, -- аренда задания; выполняющий экземпляр продлевает ее, пока задание идет
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_placement_jobs_queued ON placement_jobs (job_id) WHERE state = 'queued';
+CREATE INDEX IF NOT EXISTS idx_placement_jobs_lease ON placement_jobs (lease_until) WHERE state = 'running';
+
+-- Outbox событий размещения: сервис пишет событие в одной транзакции с изменением ячейки,
+-- оркестратор доставляет события получателям (вебхук) в порядке event_id
+CREATE TABLE IF NOT EXISTS placement_events_outbox (
+    event_id BIGSERIAL PRIMARY KEY,
+    event_type VARCHAR(50) NOT NULL, -- ItemPlaced, ItemPicked, SlotReleased
+    slot_id VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    published_at TIMESTAMP, -- событие доставлено
+    failed_at TIMESTAMP -- попытки доставки исчерпаны
+);
+
+CREATE INDEX IF NOT EXISTS idx_placement_events_outbox_pending ON placement_events_outbox (event_id)
+    WHERE published_at IS NULL AND failed_at IS NULL;
+
+-- Топология склада: узлы (ворота, перекрестки проходов и точки доступа к ячейкам) и проходы между ними.
+-- Если у склада есть ворота, сервисы ABC, XYZ, жадного и генетического размещения считают путь до ячейки
+-- по графу вместо distance_from_exit
+CREATE TABLE IF NOT EXISTS topology_nodes (
+    node_id VARCHAR(50) PRIMARY KEY,
+    warehouse_id VARCHAR(50) NOT NULL REFERENCES warehouses(warehouse_id),
+    node_type VARCHAR(20) NOT NULL CHECK (node_type IN ('dock', 'intersection', 'slot_access')),
+    slot_id VARCHAR(50), -- ячейка точки доступа; у остальных узлов NULL
+    FOREIGN KEY (warehouse_id, slot_id) REFERENCES slots(warehouse_id, slot_id),
+    CHECK ((node_type = 'slot_access') = (slot_id IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_topology_nodes_warehouse ON topology_nodes (warehouse_id);
+
+CREATE TABLE IF NOT EXISTS topology_edges (
+    from_node_id VARCHAR(50) NOT NULL REFERENCES topology_nodes(node_id),
+    to_node_id VARCHAR(50) NOT NULL REFERENCES topology_nodes(node_id),
+    distance FLOAT NOT NULL CHECK (distance > 0), -- длина прохода в метрах
+    one_way BOOLEAN NOT NULL DEFAULT false, -- проход только от from_node_id к to_node_id
+    PRIMARY KEY (from_node_id, to_node_id)
+);
+
 -- Вставка тестовых данных
 
 -- Товары с разными характеристиками
@@ -108,52 +283,100 @@ INSERT INTO items (item_id, name, item_type, weight, length, width, height, stor
 ('ITEM016', 'Температурный товар', 'temperature', 3.0, 0.4, 0.3, 0.2, 'temperature', 'temperature', 0.50, 0.10, false, false, false, 5.0, 0.3);
 
 -- Создание партий товаров
-INSERT INTO batches (batch_id, item_id, quantity) VALUES
-('BATCH001', 'ITEM001', 100),
-('BATCH002', 'ITEM002', 50),
-('BATCH003', 'ITEM003', 75),
-('BATCH004', 'ITEM004', 25),
-('BATCH005', 'ITEM005', 10),
-('BATCH006', 'ITEM006', 5),
-('BATCH007', 'ITEM007', 80),
-('BATCH008', 'ITEM008', 40),
-('BATCH009', 'ITEM009', 60),
-('BATCH010', 'ITEM010', 30),
-('BATCH011', 'ITEM011', 20),
-('BATCH012', 'ITEM012', 15),
-('BATCH013', 'ITEM013', 10),
-('BATCH014', 'ITEM014', 25),
-('BATCH015', 'ITEM015', 15),
-('BATCH016', 'ITEM016', 30);
-
--- Создание ячеек склада
-INSERT INTO slots (slot_id, location_description, max_weight, max_length, max_width, max_height, storage_conditions, is_occupied, zone_type, level, distance_from_exit) VALUES
--- Fast-access зона (близко к выходу)
-('SLOT001', 'Fast-Access Zone 1', 10.0, 1.0, 1.0, 1.0, 'normal', false, 'fast-access', 1, 2),
-('SLOT002', 'Fast-Access Zone 2', 10.0, 1.0, 1.0, 1.0, 'normal', false, 'fast-access', 1, 3),
-('SLOT003', 'Fast-Access Zone 3', 10.0, 1.0, 1.0, 1.0, 'normal', false, 'fast-access', 1, 4),
-
--- Regular зона (средняя удаленность)
-('SLOT004', 'Regular Zone 1', 10.0, 1.0, 1.0, 1.0, 'normal', false, 'regular', 1, 8),
-('SLOT005', 'Regular Zone 2', 10.0, 1.0, 1.0, 1.0, 'normal', false, 'regular', 1, 9),
-('SLOT006', 'Regular Zone 3', 10.0, 1.0, 1.0, 1.0, 'normal', false, 'regular', 1, 10),
-
--- Deep зона (далеко от выхода)
-('SLOT007', 'Deep Zone 1', 10.0, 1.0, 1.0, 1.0, 'normal', false, 'deep', 1, 15),
-('SLOT008', 'Deep Zone 2', 10.0, 1.0, 1.0, 1.0, 'normal', false, 'deep', 1, 16),
-('SLOT009', 'Deep Zone 3', 10.0, 1.0, 1.0, 1.0, 'normal', false, 'deep', 1, 17),
+-- Сроки заданы относительно текущей даты: BATCH005 уже просрочена, BATCH002, BATCH003, BATCH011 и BATCH016
+-- истекают в ближайшие 30 дней
+INSERT INTO batches (batch_id, item_id, quantity, lot_number, manufactured_at, expires_at) VALUES
+('BATCH001', 'ITEM001', 100, 'LOT-A-0417', CURRENT_DATE - 60, CURRENT_DATE + 300),
+('BATCH002', 'ITEM002', 50, 'LOT-A-0418', CURRENT_DATE - 30, CURRENT_DATE + 20),
+('BATCH003', 'ITEM003', 75, 'LOT-B-1102', CURRENT_DATE - 90, CURRENT_DATE + 10),
+('BATCH004', 'ITEM004', 25, 'LOT-B-1103', CURRENT_DATE - 20, CURRENT_DATE + 180),
+('BATCH005', 'ITEM005', 10, 'LOT-C-0051', CURRENT_DATE - 200, CURRENT_DATE - 5),
+('BATCH006', 'ITEM006', 5, 'LOT-C-0052', CURRENT_DATE - 10, NULL),
+('BATCH007', 'ITEM007', 80, 'LOT-X-2201', CURRENT_DATE - 15, CURRENT_DATE + 365),
+('BATCH008', 'ITEM008', 40, 'LOT-X-2202', CURRENT_DATE - 40, CURRENT_DATE + 90),
+('BATCH009', 'ITEM009', 60, 'LOT-Y-3301', CURRENT_DATE - 25, CURRENT_DATE + 45),
+('BATCH010', 'ITEM010', 30, 'LOT-Y-3302', CURRENT_DATE - 5, CURRENT_DATE + 120),
+('BATCH011', 'ITEM011', 20, 'LOT-Z-4401', CURRENT_DATE - 12, CURRENT_DATE + 25),
+('BATCH012', 'ITEM012', 15, 'LOT-Z-4402', CURRENT_DATE - 8, CURRENT_DATE + 60),
+('BATCH013', 'ITEM013', 10, 'LOT-H-0013', CURRENT_DATE - 100, NULL),
+('BATCH014', 'ITEM014', 25, 'LOT-F-0014', CURRENT_DATE - 30, NULL),
+('BATCH015', 'ITEM015', 15, 'LOT-HZ-0015', CURRENT_DATE - 60, CURRENT_DATE + 730),
+('BATCH016', 'ITEM016', 30, 'LOT-T-0016', CURRENT_DATE - 3, CURRENT_DATE + 12);
+
+-- Склады
+INSERT INTO warehouses (warehouse_id, name, location) VALUES
+('WH001', 'Основной склад', 'Москва'),
+('WH002', 'Северный склад', 'Санкт-Петербург');
+
+-- Создание ячеек основного склада
+INSERT INTO slots (slot_id, location_description, max_weight, max_length, max_width, max_height, storage_conditions, is_occupied, zone_type, level, distance_from_exit, max_units, mixing_policy) VALUES
+-- Fast-access зона (близко к выходу): паллетные места под одну партию
+('SLOT001', 'Fast-Access Zone 1', 1000.0, 1.2, 1.2, 3.0, 'normal', false, 'fast-access', 1, 2, NULL, 'single_batch'),
+('SLOT002', 'Fast-Access Zone 2', 1000.0, 1.2, 1.2, 3.0, 'normal', false, 'fast-access', 1, 3, NULL, 'single_batch'),
+('SLOT003', 'Fast-Access Zone 3', 1000.0, 1.2, 1.2, 3.0, 'normal', false, 'fast-access', 1, 4, NULL, 'single_batch'),
+
+-- Regular зона (средняя удаленность): несколько партий одного товара
+('SLOT004', 'Regular Zone 1', 1000.0, 1.2, 1.2, 3.0, 'normal', false, 'regular', 1, 8, NULL, 'same_item'),
+('SLOT005', 'Regular Zone 2', 1000.0, 1.2, 1.2, 3.0, 'normal', false, 'regular', 1, 9, NULL, 'same_item'),
+('SLOT006', 'Regular Zone 3', 1000.0, 1.2, 1.2, 3.0, 'normal', false, 'regular', 1, 10, NULL, 'same_item'),
+
+-- Deep зона (далеко от выхода): смешанное хранение медленных товаров
+('SLOT007', 'Deep Zone 1', 1000.0, 1.2, 1.2, 3.0, 'normal', false, 'deep', 1, 15, NULL, 'mixed'),
+('SLOT008', 'Deep Zone 2', 1000.0, 1.2, 1.2, 3.0, 'normal', false, 'deep', 1, 16, NULL, 'mixed'),
+('SLOT009', 'Deep Zone 3', 1000.0, 1.2, 1.2, 3.0, 'normal', false, 'deep', 1, 17, NULL, 'mixed'),
 
 -- Специальные зоны
-('SLOT010', 'Heavy Zone', 100.0, 2.0, 2.0, 2.0, 'normal', false, 'regular', 1, 5),
-('SLOT011', 'Fragile Zone', 5.0, 0.5, 0.5, 0.5, 'fragile', false, 'regular', 1, 6),
-('SLOT012', 'Hazardous Zone', 10.0, 1.0, 1.0, 1.0, 'hazardous', false, 'regular', 1, 7),
-('SLOT013', 'Temperature Zone', 10.0, 1.0, 1.0, 1.0, 'temperature', false, 'regular', 1, 8);
+('SLOT010', 'Heavy Zone', 2000.0, 3.0, 2.0, 2.0, 'normal', false, 'regular', 1, 5, NULL, 'single_batch'),
+('SLOT011', 'Fragile Zone', 100.0, 1.0, 0.6, 0.5, 'fragile', false, 'regular', 1, 6, 50, 'single_batch'),
+('SLOT012', 'Hazardous Zone', 500.0, 1.2, 1.0, 1.0, 'hazardous', false, 'regular', 1, 7, NULL, 'single_batch'),
+('SLOT013', 'Temperature Zone', 500.0, 1.2, 1.0, 1.0, 'temperature', false, 'regular', 1, 8, NULL, 'same_item');
+
+-- Ячейки северного склада: по две ячейки каждой зоны
+INSERT INTO slots (slot_id, warehouse_id, location_description, max_weight, max_length, max_width, max_height, storage_conditions, is_occupied, zone_type, level, distance_from_exit, max_units, mixing_policy) VALUES
+('SLOT101', 'WH002', 'North Fast-Access Zone 1', 1000.0, 1.2, 1.2, 3.0, 'normal', false, 'fast-access', 1, 2, NULL, 'single_batch'),
+('SLOT102', 'WH002', 'North Fast-Access Zone 2', 1000.0, 1.2, 1.2, 3.0, 'normal', false, 'fast-access', 1, 3, NULL, 'single_batch'),
+('SLOT103', 'WH002', 'North Regular Zone 1', 1000.0, 1.2, 1.2, 3.0, 'normal', false, 'regular', 1, 7, NULL, 'same_item'),
+('SLOT104', 'WH002', 'North Regular Zone 2', 1000.0, 1.2, 1.2, 3.0, 'normal', false, 'regular', 1, 8, NULL, 'same_item'),
+('SLOT105', 'WH002', 'North Deep Zone 1', 1000.0, 1.2, 1.2, 3.0, 'normal', false, 'deep', 1, 14, NULL, 'mixed'),
+('SLOT106', 'WH002', 'North Deep Zone 2', 1000.0, 1.2, 1.2, 3.0, 'normal', false, 'deep', 1, 15, NULL, 'mixed');
 
--- Фиксированные ячейки для некоторых товаров
+-- Фиксированные ячейки для некоторых товаров на основном складе
 INSERT INTO item_slot_map (item_id, slot_id) VALUES
 ('ITEM001', 'SLOT001'), -- Популярный товар A в fast-access зоне
 ('ITEM007', 'SLOT002'), -- Стабильный товар A в fast-access зоне
 ('ITEM013', 'SLOT010'), -- Тяжелый товар в специальной зоне
 ('ITEM014', 'SLOT011'), -- Хрупкий товар в специальной зоне
 ('ITEM015', 'SLOT012'), -- Опасный товар в специальной зоне
-('ITEM016', 'SLOT013'); -- Температурный товар в специальной зоне 
\ No newline at end of file
+('ITEM016', 'SLOT013'); -- Температурный товар в специальной зоне 
+
+-- Фиксированная ячейка на северном складе
+INSERT INTO item_slot_map (warehouse_id, item_id, slot_id) VALUES
+('WH002', 'ITEM001', 'SLOT101'); -- Популярный товар A в fast-access зоне
+
+-- Топология северного склада: двое ворот на концах главного прохода и односторонний проезд от дальних ворот.
+-- От ворот WH002-DOCK2 глубокие ячейки ближе, чем ячейки быстрого доступа
+INSERT INTO topology_nodes (node_id, warehouse_id, node_type, slot_id) VALUES
+('WH002-DOCK1', 'WH002', 'dock', NULL),
+('WH002-DOCK2', 'WH002', 'dock', NULL),
+('WH002-X1', 'WH002', 'intersection', NULL),
+('WH002-X2', 'WH002', 'intersection', NULL),
+('WH002-X3', 'WH002', 'intersection', NULL),
+('WH002-SLOT101', 'WH002', 'slot_access', 'SLOT101'),
+('WH002-SLOT102', 'WH002', 'slot_access', 'SLOT102'),
+('WH002-SLOT103', 'WH002', 'slot_access', 'SLOT103'),
+('WH002-SLOT104', 'WH002', 'slot_access', 'SLOT104'),
+('WH002-SLOT105', 'WH002', 'slot_access', 'SLOT105'),
+('WH002-SLOT106', 'WH002', 'slot_access', 'SLOT106');
+
+INSERT INTO topology_edges (from_node_id, to_node_id, distance, one_way) VALUES
+('WH002-DOCK1', 'WH002-X1', 5, false),
+('WH002-X1', 'WH002-X2', 10, false),
+('WH002-X2', 'WH002-X3', 10, false),
+('WH002-DOCK2', 'WH002-X3', 4, false),
+('WH002-X3', 'WH002-X1', 12, true),
+('WH002-X1', 'WH002-SLOT101', 2, false),
+('WH002-X1', 'WH002-SLOT102', 4, false),
+('WH002-X2', 'WH002-SLOT103', 3, false),
+('WH002-X2', 'WH002-SLOT104', 5, false),
+('WH002-X3', 'WH002-SLOT105', 3, false),
+('WH002-X3', 'WH002-SLOT106', 2, false);